@@ -36,6 +36,8 @@ func main() {
 	}
 	server := gin_gonic_http_srv.NewImportLocationServer(st, address, nf)
 	stopCh := util.SetupSignalHandler()
-	server.Run(stopCh)
+	if err := server.Run(stopCh); err != nil {
+		klog.Fatalf("%s", err.Error())
+	}
 
 }
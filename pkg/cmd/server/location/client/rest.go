@@ -28,11 +28,15 @@ func SetupBridgeLocationRESTClient(hostURL, token string) *BridgeLocationRESTCli
 	return b
 }
 
-func (b *BridgeLocationRESTClient) UpsertModel(importKey string, body *rest.PostBody) (int, string, error) {
+func (b *BridgeLocationRESTClient) UpsertModel(importKey, normalizerType string, body *rest.PostBody) (int, string, error) {
 	var err error
 	var locationResp *resty.Response
 
-	locationResp, err = b.RESTClient.R().SetBody(body).SetAuthToken(b.Token).SetQueryParam(util.KeyQueryParam, importKey).SetHeader("Accept", "application/json").Post(b.UpsertURL)
+	req := b.RESTClient.R().SetBody(body).SetAuthToken(b.Token).SetQueryParam(util.KeyQueryParam, importKey).SetHeader("Accept", "application/json")
+	if len(normalizerType) > 0 {
+		req = req.SetQueryParam(util.TypeQueryParam, normalizerType)
+	}
+	locationResp, err = req.Post(b.UpsertURL)
 	msg := fmt.Sprintf("%#v", locationResp)
 	if err != nil {
 		return http.StatusInternalServerError, msg, err
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"k8s.io/klog/v2"
+)
+
+const defaultAttachmentMaxBytes = 10 * 1024 * 1024
+
+// modelCardAttachment is a binary asset of a model card, such as a weights manifest or eval dataset, served at
+// /modelcard/<key>/assets/<name>
+type modelCardAttachment struct {
+	contentType string
+	content     []byte
+}
+
+// checkAttachments validates the attachments of an upsert, whose names must be unique path segments and whose
+// total size must be within the max, returning the status to fail the upsert with otherwise
+func (i *ImportLocationServer) checkAttachments(postBody rest.PostBody) (int, error) {
+	if len(postBody.Attachments) == 0 {
+		return 0, nil
+	}
+	if len(postBody.ModelCardKey) == 0 {
+		return http.StatusBadRequest, fmt.Errorf("attachments need a 'modelCardKey' in the POST body")
+	}
+	if i.attachmentMaxBytes <= 0 {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("model card attachments are disabled")
+	}
+	total := 0
+	names := map[string]struct{}{}
+	for _, a := range postBody.Attachments {
+		if len(a.Name) == 0 || a.Name == "." || a.Name == ".." || strings.ContainsAny(a.Name, "/\\") {
+			return http.StatusBadRequest, fmt.Errorf("bad attachment name for model card %s: '%s'", postBody.ModelCardKey, a.Name)
+		}
+		if _, ok := names[a.Name]; ok {
+			return http.StatusBadRequest, fmt.Errorf("attachment %s of model card %s is duplicated", a.Name, postBody.ModelCardKey)
+		}
+		names[a.Name] = struct{}{}
+		if len(a.ContentType) > 0 {
+			if _, _, err := mime.ParseMediaType(a.ContentType); err != nil {
+				return http.StatusBadRequest, fmt.Errorf("bad content type for attachment %s of model card %s: %s", a.Name, postBody.ModelCardKey, err.Error())
+			}
+		}
+		total += len(a.Content)
+	}
+	if total > i.attachmentMaxBytes {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("the attachments of model card %s total %d bytes, over the max of %d", postBody.ModelCardKey, total, i.attachmentMaxBytes)
+	}
+	return 0, nil
+}
+
+// newAttachments holds checked attachments by name, inferring the content types not given from the extension of
+// the name, or failing that the content
+func newAttachments(attachments []rest.Attachment) map[string]modelCardAttachment {
+	held := make(map[string]modelCardAttachment, len(attachments))
+	for _, a := range attachments {
+		contentType := a.ContentType
+		if len(contentType) == 0 {
+			contentType = mime.TypeByExtension(path.Ext(a.Name))
+		}
+		if len(contentType) == 0 {
+			contentType = http.DetectContentType(a.Content)
+		}
+		held[a.Name] = modelCardAttachment{contentType: contentType, content: a.Content}
+	}
+	return held
+}
+
+// attachmentNames returns the sorted names of the attachments of a model card
+func attachmentNames(mcm modelCardMetadata) []string {
+	if len(mcm.attachments) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(mcm.attachments))
+	for name := range mcm.attachments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleModelCardAssetGet serves an attachment of a model card, as a download named for the attachment
+func (i *ImportLocationServer) handleModelCardAssetGet(c *gin.Context) {
+	key, name := c.Param("key"), c.Param("name")
+	if !i.lockForRead(c) {
+		return
+	}
+	mcm, ok := i.modelcards[key]
+	a, found := mcm.attachments[name]
+	i.lock.RUnlock()
+	if !ok {
+		klog.Infof("no model card found for %s", key)
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if !found {
+		c.Status(http.StatusNotFound)
+		c.Error(fmt.Errorf("model card %s has no attachment %s", key, name))
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	c.Data(http.StatusOK, a.contentType, a.content)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestModelCardAttachments(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:            map[string]*ImportLocation{},
+		modelcards:         map[string]modelCardMetadata{},
+		attachmentMaxBytes: 64,
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.POST(util.UpsertURI, ils.handleCatalogUpsertPost)
+	r.POST(util.UpsertModelCardURI, ils.handleModelCardUpsertPost)
+	r.GET(util.ModelCardURI, ils.handleModelCardGet)
+	r.GET(util.ModelCardMetaURI, ils.handleModelCardMetaGet)
+	r.GET(util.ModelCardAssetURI, ils.handleModelCardAssetGet)
+	post := func(uri string, body rest.PostBody) int {
+		data, err := json.Marshal(body)
+		common.AssertError(t, err)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, uri, bytes.NewReader(data)))
+		return rec.Code
+	}
+	get := func(uri string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, uri, nil))
+		return rec
+	}
+
+	sc := post(util.UpsertURI+"?key=mnist_v1", rest.PostBody{
+		Body:         []byte(mnistEntity),
+		ModelCardKey: "mnist-card",
+		ModelCard:    "# mnist",
+		Attachments: []rest.Attachment{
+			{Name: "weights.json", Content: []byte(`{"layers":2}`)},
+			{Name: "eval.bin", ContentType: "application/x-eval", Content: []byte{0, 1, 2}},
+			{Name: "notes", Content: []byte("plain notes")},
+		},
+	})
+	common.AssertEqual(t, http.StatusCreated, sc)
+
+	rec := get("/modelcard/mnist-card/assets/weights.json")
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, `{"layers":2}`, rec.Body.String())
+	common.AssertEqual(t, "application/json", rec.Header().Get("Content-Type"))
+	common.AssertEqual(t, `attachment; filename=weights.json`, rec.Header().Get("Content-Disposition"))
+	rec = get("/modelcard/mnist-card/assets/eval.bin")
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, []byte{0, 1, 2}, rec.Body.Bytes())
+	common.AssertEqual(t, "application/x-eval", rec.Header().Get("Content-Type"))
+	// without a content type or a known extension, the type is detected from the content
+	rec = get("/modelcard/mnist-card/assets/notes")
+	common.AssertEqual(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	common.AssertEqual(t, http.StatusNotFound, get("/modelcard/mnist-card/assets/missing.bin").Code)
+	common.AssertEqual(t, http.StatusNotFound, get("/modelcard/other-card/assets/weights.json").Code)
+	// the static model card routes are unaffected by the asset route
+	common.AssertEqual(t, "# mnist", get(util.ModelCardURI+"?key=mnist-card").Body.String())
+	meta := ModelCardMetaResponse{}
+	common.AssertError(t, json.Unmarshal(get(util.ModelCardMetaURI+"?key=mnist-card").Body.Bytes(), &meta))
+	common.AssertEqual(t, []string{"eval.bin", "notes", "weights.json"}, meta.Attachments)
+
+	// a card only upsert without attachments keeps them, and one with attachments replaces them
+	sc = post(util.UpsertModelCardURI+"?key=mnist_v1", rest.PostBody{ModelCardKey: "mnist-card", ModelCard: "# mnist updated", LastUpdateTimeSinceEpoch: "2"})
+	common.AssertEqual(t, http.StatusCreated, sc)
+	common.AssertEqual(t, http.StatusOK, get("/modelcard/mnist-card/assets/weights.json").Code)
+	sc = post(util.UpsertModelCardURI+"?key=mnist_v1", rest.PostBody{
+		ModelCardKey: "mnist-card",
+		ModelCard:    "# mnist updated",
+		Attachments:  []rest.Attachment{{Name: "weights.json", Content: []byte(`{"layers":3}`)}},
+	})
+	common.AssertEqual(t, http.StatusCreated, sc)
+	common.AssertEqual(t, `{"layers":3}`, get("/modelcard/mnist-card/assets/weights.json").Body.String())
+	common.AssertEqual(t, http.StatusNotFound, get("/modelcard/mnist-card/assets/eval.bin").Code)
+
+	for _, tc := range []struct {
+		name        string
+		uri         string
+		attachments []rest.Attachment
+		expectedSC  int
+	}{
+		{
+			name:        "over the max size",
+			uri:         util.UpsertURI + "?key=mnist_v1",
+			attachments: []rest.Attachment{{Name: "a.bin", Content: make([]byte, 40)}, {Name: "b.bin", Content: make([]byte, 40)}},
+			expectedSC:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "card only upsert over the max size",
+			uri:         util.UpsertModelCardURI + "?key=mnist_v1",
+			attachments: []rest.Attachment{{Name: "a.bin", Content: make([]byte, 65)}},
+			expectedSC:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "name with a path",
+			uri:         util.UpsertURI + "?key=mnist_v1",
+			attachments: []rest.Attachment{{Name: "../card", Content: []byte("x")}},
+			expectedSC:  http.StatusBadRequest,
+		},
+		{
+			name:        "duplicate name",
+			uri:         util.UpsertURI + "?key=mnist_v1",
+			attachments: []rest.Attachment{{Name: "a.bin", Content: []byte("x")}, {Name: "a.bin", Content: []byte("y")}},
+			expectedSC:  http.StatusBadRequest,
+		},
+	} {
+		sc = post(tc.uri, rest.PostBody{Body: []byte(strings.Replace(mnistEntity, "mnist", "rejected", 1)), ModelCardKey: "mnist-card", ModelCard: "# rejected", Attachments: tc.attachments})
+		if sc != tc.expectedSC {
+			t.Errorf("%s: expected status %d but got %d", tc.name, tc.expectedSC, sc)
+		}
+		// a rejected upsert changes nothing
+		common.AssertEqual(t, `{"layers":3}`, get("/modelcard/mnist-card/assets/weights.json").Body.String())
+		common.AssertEqual(t, mnistEntity, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+// newBenchmarkServer creates a server with the routes registered on a bare router, or on one with the full request
+// middleware, caching the mnist and granite entities
+func newBenchmarkServer(bare bool) (*ImportLocationServer, *gin.Engine) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":   {content: []byte(mnistEntity)},
+			"/granite/v1/catalog-info.yaml": {content: []byte(graniteEntity)},
+		},
+		modelcards: map[string]modelCardMetadata{},
+		bare:       bare,
+	}
+	r := newBareRouter()
+	if !bare {
+		r = newRouter(io.Discard, nil, defaultRequestIdHeader)
+	}
+	ils.registerRoutes(r, nil)
+	return ils, r
+}
+
+func TestBareRouter(t *testing.T) {
+	_, r := newBenchmarkServer(true)
+	lookups := httpRequestsTotal.WithLabelValues("/:model/:version/:format", http.MethodGet, strconv.Itoa(http.StatusOK))
+	before := counterValue(t, lookups)
+
+	wg := sync.WaitGroup{}
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, path := range []string{"/mnist/v1/catalog-info.yaml", util.ListURI} {
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				if rec.Code != http.StatusOK {
+					t.Errorf("expected %s to respond %d but got %d", path, http.StatusOK, rec.Code)
+				}
+				if len(rec.Header().Get(defaultRequestIdHeader)) > 0 {
+					t.Errorf("expected no request ID from the bare router for %s", path)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mnist/v1/catalog-info.yaml", nil))
+	common.AssertEqual(t, mnistEntity, rec.Body.String())
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mnist/v9/catalog-info.yaml", nil))
+	common.AssertEqual(t, http.StatusNotFound, rec.Code)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ListURI, nil))
+	common.AssertContains(t, rec.Body.String(), []string{"/mnist/v1/catalog-info.yaml", "/granite/v1/catalog-info.yaml"})
+
+	// the bare router records no request metrics
+	common.AssertEqual(t, before, counterValue(t, lookups))
+}
+
+// BenchmarkRouter measures the throughput of discovery and lookup requests on a bare router, against one with the
+// request ID, logging and metrics middleware
+func BenchmarkRouter(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		path string
+	}{
+		{name: "discovery", path: util.ListURI},
+		{name: "lookup", path: "/mnist/v1/catalog-info.yaml"},
+	} {
+		path := bc.path
+		for _, bare := range []bool{true, false} {
+			name := bc.name + "/full"
+			if bare {
+				name = bc.name + "/bare"
+			}
+			b.Run(name, func(b *testing.B) {
+				_, r := newBenchmarkServer(bare)
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						rec := httptest.NewRecorder()
+						r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+						if rec.Code != http.StatusOK {
+							b.Errorf("expected %s to respond %d but got %d", path, http.StatusOK, rec.Code)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+// sourceBudgets limits the number of requests of each source in progress at once, so in a multi-tenant setup one
+// source's traffic cannot starve another's; sources without a budget are unlimited
+type sourceBudgets map[string]chan struct{}
+
+// newSourceBudgets creates the budgets from source=limit entries, skipping, with an error logged, limits that are
+// not positive integers
+func newSourceBudgets(limits map[string]string) sourceBudgets {
+	b := sourceBudgets{}
+	for source, raw := range limits {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			klog.Errorf("invalid concurrency budget %q for source %s, leaving it unlimited", raw, source)
+			continue
+		}
+		b[source] = make(chan struct{}, n)
+	}
+	return b
+}
+
+// requestSource returns the source a request is made for: that of the type parameter of upserts, that of the
+// location served at the request's path for lookups, that of the location of the key parameter for removals, or
+// otherwise the first segment of the matched route when it is not a parameter, as with /kserve/list
+func (i *ImportLocationServer) requestSource(c *gin.Context) string {
+	if source := c.Query(util.TypeQueryParam); len(source) > 0 {
+		return source
+	}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	if il, ok := i.content[c.Request.URL.Path]; ok && il.content != nil {
+		return il.source
+	}
+	if segs := strings.Split(c.Query("key"), "_"); len(segs) >= 2 {
+		for _, uri := range i.candidateURIs(segs[0], segs[1]) {
+			if il, ok := i.content[uri]; ok && il.content != nil {
+				return il.source
+			}
+		}
+	}
+	route, _, _ := strings.Cut(strings.TrimPrefix(c.FullPath(), "/"), "/")
+	if strings.HasPrefix(route, ":") || strings.HasPrefix(route, "*") {
+		return ""
+	}
+	return route
+}
+
+// limit rejects requests of a source, as returned by sourceOf, with a 429 while as many of its requests as its
+// budget allows are in progress
+func (b sourceBudgets) limit(sourceOf func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		source := sourceOf(c)
+		sem, ok := b[source]
+		if !ok {
+			c.Next()
+			return
+		}
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+			c.Next()
+		default:
+			c.Header("Retry-After", "1")
+			c.AbortWithError(http.StatusTooManyRequests, fmt.Errorf("source %s is at its budget of %d concurrent requests", source, cap(sem)))
+		}
+	}
+}
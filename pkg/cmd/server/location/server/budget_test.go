@@ -0,0 +1,110 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestSourceBudgets(t *testing.T) {
+	budgets := newSourceBudgets(map[string]string{types.KServeNormalizer: "2", types.KubeflowNormalizer: "1", "bad": "zero"})
+	common.AssertEqual(t, 2, len(budgets))
+
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte("mnist"), source: types.KServeNormalizer},
+			"/fraud/v1/catalog-info.yaml": {content: []byte("fraud"), source: types.KubeflowNormalizer},
+		},
+		format: types.CatalogInfoYamlFormat,
+	}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.Use(budgets.limit(ils.requestSource))
+	r.GET("/:model/:version/:format", func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// take up the whole budget of kserve with lookups of its model
+	wg := sync.WaitGroup{}
+	codes := make(chan int, 3)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- get("/mnist/v1/catalog-info.yaml").Code
+		}()
+		<-started
+	}
+
+	// further lookups of kserve are over its budget
+	rec := get("/mnist/v1/catalog-info.yaml")
+	common.AssertEqual(t, http.StatusTooManyRequests, rec.Code)
+	common.AssertEqual(t, "1", rec.Header().Get("Retry-After"))
+
+	// while kubeflow's proceed
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		codes <- get("/fraud/v1/catalog-info.yaml").Code
+	}()
+	<-started
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		common.AssertEqual(t, http.StatusOK, code)
+	}
+
+	// the budget is given back as requests complete
+	go func() { <-started }()
+	common.AssertEqual(t, http.StatusOK, get("/mnist/v1/catalog-info.yaml").Code)
+}
+
+func TestRequestSource(t *testing.T) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte("mnist"), source: types.KServeNormalizer},
+			"/fraud/v1/catalog-info.yaml": {source: types.KubeflowNormalizer},
+		},
+		format: types.CatalogInfoYamlFormat,
+	}
+	for _, tc := range []struct {
+		name     string
+		method   string
+		route    string
+		target   string
+		expected string
+	}{
+		{name: "upsert type", method: http.MethodPost, route: util.UpsertURI, target: util.UpsertURI + "?key=granite_v1&type=kubeflow", expected: types.KubeflowNormalizer},
+		{name: "lookup", method: http.MethodGet, route: "/:model/:version/:format", target: "/mnist/v1/catalog-info.yaml", expected: types.KServeNormalizer},
+		{name: "lookup of removed", method: http.MethodGet, route: "/:model/:version/:format", target: "/fraud/v1/catalog-info.yaml"},
+		{name: "lookup of unknown", method: http.MethodGet, route: "/:model/:version/:format", target: "/granite/v1/catalog-info.yaml"},
+		{name: "removal", method: http.MethodDelete, route: util.RemoveURI, target: util.RemoveURI + "?key=mnist_v1", expected: types.KServeNormalizer},
+		{name: "source discovery", method: http.MethodGet, route: "/" + types.KServeNormalizer + util.ListURI, target: "/" + types.KServeNormalizer + util.ListURI, expected: types.KServeNormalizer},
+	} {
+		source := "unset"
+		r := gin.New()
+		r.Handle(tc.method, tc.route, func(c *gin.Context) {
+			source = ils.requestSource(c)
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tc.method, tc.target, nil))
+		if source != tc.expected {
+			t.Errorf("%s: expected source %q but got %q", tc.name, tc.expected, source)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultBulkMaxConcurrentJobs = 2
+	// defaultBulkJobsRetained is the number of finished jobs whose results are kept for polling
+	defaultBulkJobsRetained = 100
+)
+
+type bulkJobState string
+
+const (
+	bulkJobRunning   bulkJobState = "running"
+	bulkJobSucceeded bulkJobState = "succeeded"
+	// bulkJobFailed is the state of a job that ran to completion with at least one item failing
+	bulkJobFailed bulkJobState = "failed"
+)
+
+// BulkUpsertItem is an upsert of a bulk upsert, with the key, type and override query parameters of a single upsert
+// alongside its POST body
+type BulkUpsertItem struct {
+	Key      string `json:"key"`
+	Type     string `json:"type,omitempty"`
+	Override bool   `json:"override,omitempty"`
+	rest.PostBody
+}
+
+type BulkUpsertRequest struct {
+	Items []BulkUpsertItem `json:"items"`
+}
+
+// BulkItemResult is the outcome of an item of a bulk upsert, with the status code a single upsert of it would have
+// responded with
+type BulkItemResult struct {
+	Key    string `json:"key"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BulkUpsertResponse struct {
+	Results []BulkItemResult `json:"results"`
+	Failed  int              `json:"failed"`
+}
+
+// BulkJob is the status of a bulk upsert processed in the background; results are listed once it completes
+type BulkJob struct {
+	ID        string           `json:"id"`
+	State     bulkJobState     `json:"state"`
+	Submitted time.Time        `json:"submitted"`
+	Completed *time.Time       `json:"completed,omitempty"`
+	Total     int              `json:"total"`
+	Processed int              `json:"processed"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results,omitempty"`
+}
+
+// bulkJobs tracks the bulk upserts processed in the background, bounding how many run at once and how many finished
+// jobs are kept for polling
+type bulkJobs struct {
+	lock        sync.Mutex
+	jobs        map[string]*BulkJob
+	finished    []string
+	running     int
+	maxRunning  int
+	maxRetained int
+}
+
+func newBulkJobs(maxRunning, maxRetained int) *bulkJobs {
+	return &bulkJobs{jobs: map[string]*BulkJob{}, maxRunning: maxRunning, maxRetained: maxRetained}
+}
+
+// submit starts tracking a job of total items, unless as many jobs as allowed are already running
+func (b *bulkJobs) submit(total int) (string, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.running >= b.maxRunning {
+		return "", false
+	}
+	b.running++
+	id := uuid.New().String()
+	b.jobs[id] = &BulkJob{ID: id, State: bulkJobRunning, Submitted: time.Now(), Total: total}
+	return id, true
+}
+
+// record adds the result of an item to a running job
+func (b *bulkJobs) record(id string, result BulkItemResult) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	job := b.jobs[id]
+	job.Processed++
+	if result.Status >= http.StatusBadRequest {
+		job.Failed++
+	}
+	job.Results = append(job.Results, result)
+}
+
+// finish completes a job, dropping the oldest finished jobs beyond those retained
+func (b *bulkJobs) finish(id string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	job := b.jobs[id]
+	now := time.Now()
+	job.Completed = &now
+	job.State = bulkJobSucceeded
+	if job.Failed > 0 {
+		job.State = bulkJobFailed
+	}
+	b.running--
+	b.finished = append(b.finished, id)
+	for len(b.finished) > b.maxRetained {
+		delete(b.jobs, b.finished[0])
+		b.finished = b.finished[1:]
+	}
+}
+
+// get returns a copy of a job, with its results only once it completes
+func (b *bulkJobs) get(id string) (BulkJob, bool) {
+	if b == nil {
+		return BulkJob{}, false
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	job, ok := b.jobs[id]
+	if !ok {
+		return BulkJob{}, false
+	}
+	cp := *job
+	cp.Results = nil
+	if job.Completed != nil {
+		cp.Results = append([]BulkItemResult{}, job.Results...)
+	}
+	return cp, true
+}
+
+// upsertItem upserts an item of a bulk upsert as a single upsert of it would be
+func (u *ImportLocationServer) upsertItem(item BulkUpsertItem) BulkItemResult {
+	key := u.namespace.strip(item.Key)
+	result := BulkItemResult{Key: item.Key, Status: http.StatusBadRequest}
+	if len(key) == 0 {
+		result.Error = "need a 'key'"
+		return result
+	}
+	sc, err := u.upsertKey(key, item.Type, item.Override, item.PostBody)
+	result.Status = sc
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// handleBulkUpsertPost upserts a list of items in order, responding with the outcome of each or, with the async
+// parameter, responding with a 202 and the job processing them in the background, whose status is polled at
+// /jobs/:id.  Items are applied independently, so a failing item does not stop those after it.
+func (u *ImportLocationServer) handleBulkUpsertPost(c *gin.Context) {
+	if u.memoryGuard.rejectUnderPressure(c) {
+		return
+	}
+	var req BulkUpsertRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.Status(http.StatusBadRequest)
+		klog.Errorf("error reading POST body: %s", err.Error())
+		c.Error(err)
+		return
+	}
+	if async, _ := strconv.ParseBool(c.Query(util.AsyncQueryParam)); async {
+		u.submitBulkUpsert(c, req.Items)
+		return
+	}
+	resp := &BulkUpsertResponse{Results: []BulkItemResult{}}
+	for _, item := range req.Items {
+		result := u.upsertItem(item)
+		if result.Status >= http.StatusBadRequest {
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	content, err := json.Marshal(resp)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
+
+// submitBulkUpsert starts a job upserting the items in the background
+func (u *ImportLocationServer) submitBulkUpsert(c *gin.Context, items []BulkUpsertItem) {
+	if u.bulkJobs == nil {
+		c.Status(http.StatusNotImplemented)
+		c.Error(fmt.Errorf("asynchronous bulk upserts are disabled"))
+		return
+	}
+	id, ok := u.bulkJobs.submit(len(items))
+	if !ok {
+		c.Header("Retry-After", "1")
+		c.Status(http.StatusTooManyRequests)
+		c.Error(fmt.Errorf("the maximum of %d bulk upsert jobs are running, retry later", u.bulkJobs.maxRunning))
+		return
+	}
+	job, _ := u.bulkJobs.get(id)
+	go func() {
+		for _, item := range items {
+			u.bulkJobs.record(id, u.upsertItem(item))
+		}
+		u.bulkJobs.finish(id)
+		klog.Infof("bulk upsert job %s of %d items completed", id, len(items))
+	}()
+	content, err := json.Marshal(&job)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Header("Location", strings.Replace(util.JobURI, ":id", id, 1))
+	c.Data(http.StatusAccepted, "Content-Type: application/json", content)
+}
+
+// handleJobGet responds with the status of a bulk upsert job, and its results once it completes
+func (u *ImportLocationServer) handleJobGet(c *gin.Context) {
+	id := c.Param("id")
+	job, ok := u.bulkJobs.get(id)
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	content, err := json.Marshal(&job)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func bulkUpsertRouter(ils *ImportLocationServer) *gin.Engine {
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.POST(util.UpsertBulkURI, ils.handleBulkUpsertPost)
+	r.GET(util.JobURI, ils.handleJobGet)
+	return r
+}
+
+func postBulkUpsert(t *testing.T, r *gin.Engine, query string, items ...BulkUpsertItem) *httptest.ResponseRecorder {
+	data, err := json.Marshal(&BulkUpsertRequest{Items: items})
+	common.AssertError(t, err)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, util.UpsertBulkURI+query, bytes.NewReader(data)))
+	return rec
+}
+
+func bulkItems() []BulkUpsertItem {
+	return []BulkUpsertItem{
+		{Key: "mnist_v1", PostBody: rest.PostBody{Body: []byte("kind: Component\n")}},
+		{Key: "badkey", PostBody: rest.PostBody{Body: []byte("kind: Component\n")}},
+		{Key: "fraud_v1", Type: "kserve", PostBody: rest.PostBody{Body: []byte("kind: Component\n")}},
+	}
+}
+
+func TestBulkUpsert(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{},
+	}
+	r := bulkUpsertRouter(ils)
+
+	rec := postBulkUpsert(t, r, "", bulkItems()...)
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	resp := BulkUpsertResponse{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	common.AssertEqual(t, 1, resp.Failed)
+	common.AssertEqual(t, 3, len(resp.Results))
+	common.AssertEqual(t, http.StatusCreated, resp.Results[0].Status)
+	// a failing item does not stop those after it
+	common.AssertEqual(t, http.StatusBadRequest, resp.Results[1].Status)
+	common.AssertEqual(t, "bad key format: badkey", resp.Results[1].Error)
+	common.AssertEqual(t, http.StatusCreated, resp.Results[2].Status)
+	common.AssertEqual(t, 2, len(ils.content))
+	common.AssertEqual(t, "kserve", ils.content["/fraud/v1/catalog-info.yaml"].source)
+
+	// background processing is disabled
+	rec = postBulkUpsert(t, r, "?async=true", bulkItems()...)
+	common.AssertEqual(t, http.StatusNotImplemented, rec.Code)
+}
+
+func TestBulkUpsertJob(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{},
+		bulkJobs:   newBulkJobs(1, 1),
+	}
+	r := bulkUpsertRouter(ils)
+
+	// holding the lock keeps the job running
+	ils.lock.Lock()
+	rec := postBulkUpsert(t, r, "?async=true", bulkItems()...)
+	common.AssertEqual(t, http.StatusAccepted, rec.Code)
+	job := BulkJob{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+	common.AssertEqual(t, bulkJobRunning, job.State)
+	common.AssertEqual(t, 3, job.Total)
+	common.AssertEqual(t, "/jobs/"+job.ID, rec.Header().Get("Location"))
+
+	// the number of concurrent jobs is bounded
+	rec = postBulkUpsert(t, r, "?async=true", bulkItems()[:1]...)
+	common.AssertEqual(t, http.StatusTooManyRequests, rec.Code)
+	ils.lock.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Completed == nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil))
+		common.AssertEqual(t, http.StatusOK, rec.Code)
+		job = BulkJob{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+		if job.Completed == nil {
+			// results are listed once the job completes
+			common.AssertEqual(t, 0, len(job.Results))
+		}
+	}
+	if job.Completed == nil {
+		t.Fatalf("job %s did not complete", job.ID)
+	}
+	common.AssertEqual(t, bulkJobFailed, job.State)
+	common.AssertEqual(t, 3, job.Processed)
+	common.AssertEqual(t, 1, job.Failed)
+	common.AssertEqual(t, []int{http.StatusCreated, http.StatusBadRequest, http.StatusCreated},
+		[]int{job.Results[0].Status, job.Results[1].Status, job.Results[2].Status})
+	ils.lock.RLock()
+	common.AssertEqual(t, 2, len(ils.content))
+	ils.lock.RUnlock()
+
+	// a finished job frees its slot, and only the most recent finished jobs are retained
+	rec = postBulkUpsert(t, r, "?async=true", bulkItems()[:1]...)
+	common.AssertEqual(t, http.StatusAccepted, rec.Code)
+	next := BulkJob{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &next))
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		if j, _ := ils.bulkJobs.get(next.ID); j.Completed != nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil))
+	common.AssertEqual(t, http.StatusNotFound, rec.Code)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/"+next.ID, nil))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, true, strings.Contains(rec.Body.String(), `"state":"succeeded"`))
+}
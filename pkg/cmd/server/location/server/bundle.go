@@ -0,0 +1,131 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+const (
+	mimeZip = "application/zip"
+
+	bundleModelCardFileName = "modelcard.md"
+	bundleMetadataFileName  = "metadata.json"
+)
+
+// BundleResponse is everything the location service has for a model version
+type BundleResponse struct {
+	URI          string                 `json:"uri"`
+	CatalogInfo  string                 `json:"catalogInfo"`
+	ModelCardKey string                 `json:"modelCardKey,omitempty"`
+	ModelCard    string                 `json:"modelCard,omitempty"`
+	Metadata     *ModelCardMetaResponse `json:"metadata,omitempty"`
+}
+
+// handleBundleGet returns the catalog-info, model card, and model card metadata of a model version, as a JSON
+// object or, when the client accepts only application/zip, as a zip archive of the files
+func (i *ImportLocationServer) handleBundleGet(c *gin.Context) {
+	b := &BundleResponse{}
+	var il *ImportLocation
+	// in read-through mode the location is resolved from storage, while its model card is held from upserts
+	if i.readThrough != nil {
+		key, _ := i.buildKeyAndURI(c.Param("model"), c.Param("version"), i.format)
+		fetched, err := i.resolveThrough(c.Param("model"), c.Param("version"), key)
+		if err != nil {
+			klog.Error(err.Error())
+			c.Status(http.StatusServiceUnavailable)
+			c.Error(err)
+			return
+		}
+		if fetched != nil {
+			b.URI, il = fetched.uri, fetched.il
+		}
+	}
+	i.lock.RLock()
+	for _, uri := range i.candidateURIs(c.Param("model"), c.Param("version")) {
+		if il != nil {
+			break
+		}
+		if loc, ok := i.content[uri]; ok && loc.content != nil {
+			b.URI, il = uri, loc
+		}
+	}
+	if il == nil {
+		i.lock.RUnlock()
+		c.Status(http.StatusNotFound)
+		return
+	}
+	served, _ := i.servedContent(b.URI, il, "")
+	b.CatalogInfo = string(served)
+	evicted := false
+	if mcm, ok := i.modelcards[il.modelCardKey]; ok && len(il.modelCardKey) > 0 {
+		b.ModelCardKey = il.modelCardKey
+		b.ModelCard = mcm.content
+		evicted = mcm.evicted
+		b.Metadata = &ModelCardMetaResponse{
+			Key:                      il.modelCardKey,
+			LastUpdateTimeSinceEpoch: mcm.lastUpdateTimeSinceEpoch,
+			FrontMatter:              mcm.frontMatter,
+		}
+	}
+	i.lock.RUnlock()
+	if evicted && i.restoreModelCard(b.ModelCardKey) {
+		i.lock.RLock()
+		b.ModelCard = i.modelcards[b.ModelCardKey].content
+		i.lock.RUnlock()
+	}
+
+	var content []byte
+	var err error
+	contentType := "Content-Type: application/json"
+	if c.NegotiateFormat(gin.MIMEJSON, mimeZip) == mimeZip {
+		content, err = b.zip()
+		contentType = "Content-Type: " + mimeZip
+	} else {
+		content, err = json.Marshal(b)
+	}
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// zip archives the bundle, with the catalog-info under its file name from the URI
+func (b *BundleResponse) zip() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	files := map[string][]byte{path.Base(b.URI): []byte(b.CatalogInfo)}
+	if b.Metadata != nil {
+		files[bundleModelCardFileName] = []byte(b.ModelCard)
+		metadata, err := json.Marshal(b.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		files[bundleMetadataFileName] = metadata
+	}
+	for _, fn := range []string{path.Base(b.URI), bundleModelCardFileName, bundleMetadataFileName} {
+		content, ok := files[fn]
+		if !ok {
+			continue
+		}
+		f, err := w.Create(fn)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = f.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleBundleGet(t *testing.T) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), modelCardKey: "mnist-card"},
+			"/mnist/v2/catalog-info.yaml": {content: []byte(mnistUpdatedEntity)},
+			"/mnist/v3/catalog-info.yaml": {},
+		},
+		modelcards: map[string]modelCardMetadata{
+			"mnist-card": {content: frontMatterCard, lastUpdateTimeSinceEpoch: "1", frontMatter: map[string]interface{}{"license": "apache-2.0"}},
+		},
+	}
+	r := gin.New()
+	r.GET("/:model/:version/:format", ils.handleCatalogLookupGet)
+	r.GET(util.BundleURI, ils.handleBundleGet)
+
+	for _, tc := range []struct {
+		name           string
+		path           string
+		accept         string
+		expectedSC     int
+		expectedBundle *BundleResponse
+		expectedFiles  map[string]string
+	}{
+		{
+			name:       "json bundle",
+			path:       "/mnist/v1/bundle",
+			expectedSC: http.StatusOK,
+			expectedBundle: &BundleResponse{
+				URI:          "/mnist/v1/catalog-info.yaml",
+				CatalogInfo:  mnistEntity,
+				ModelCardKey: "mnist-card",
+				ModelCard:    frontMatterCard,
+				Metadata:     &ModelCardMetaResponse{Key: "mnist-card", LastUpdateTimeSinceEpoch: "1", FrontMatter: map[string]interface{}{"license": "apache-2.0"}},
+			},
+		},
+		{
+			name:           "json bundle without a model card",
+			path:           "/mnist/v2/bundle",
+			accept:         "application/json",
+			expectedSC:     http.StatusOK,
+			expectedBundle: &BundleResponse{URI: "/mnist/v2/catalog-info.yaml", CatalogInfo: mnistUpdatedEntity},
+		},
+		{
+			name:       "zip bundle",
+			path:       "/mnist/v1/bundle",
+			accept:     "application/zip",
+			expectedSC: http.StatusOK,
+			expectedFiles: map[string]string{
+				"catalog-info.yaml": mnistEntity,
+				"modelcard.md":      frontMatterCard,
+				"metadata.json":     `{"key":"mnist-card","lastUpdateTimeSinceEpoch":"1","frontMatter":{"license":"apache-2.0"}}`,
+			},
+		},
+		{
+			name:       "removed location",
+			path:       "/mnist/v3/bundle",
+			expectedSC: http.StatusNotFound,
+		},
+		{
+			name:       "missing location",
+			path:       "/granite/v1/bundle",
+			expectedSC: http.StatusNotFound,
+		},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if len(tc.accept) > 0 {
+			req.Header.Set("Accept", tc.accept)
+		}
+		r.ServeHTTP(w, req)
+
+		common.AssertEqual(t, tc.expectedSC, w.Code)
+		if tc.expectedBundle != nil {
+			b := &BundleResponse{}
+			common.AssertError(t, json.Unmarshal(w.Body.Bytes(), b))
+			common.AssertEqual(t, tc.expectedBundle, b)
+		}
+		if tc.expectedFiles != nil {
+			zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+			common.AssertError(t, err)
+			files := map[string]string{}
+			for _, f := range zr.File {
+				rc, err := f.Open()
+				common.AssertError(t, err)
+				content, err := io.ReadAll(rc)
+				common.AssertError(t, err)
+				rc.Close()
+				files[f.Name] = string(content)
+			}
+			common.AssertEqual(t, tc.expectedFiles, files)
+		}
+	}
+}
+
+func TestHandleBundleGetAfterReload(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte(mnistEntity)})
+	st.SetModelCard("mnist_v1", "mnist-card", frontMatterCard, "1")
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), modelCardKey: "mnist-card"},
+		},
+		modelcards: map[string]modelCardMetadata{
+			"mnist-card": newModelCardMetadata("mnist-card", frontMatterCard, "1"),
+		},
+		storage: st,
+	}
+	r := gin.New()
+	r.GET(util.BundleURI, ils.handleBundleGet)
+
+	loaded, err := ils.reloadFromStorage()
+	common.AssertError(t, err)
+	common.AssertEqual(t, true, loaded)
+
+	// the reloaded location keeps its model card
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/mnist/v1/bundle", nil))
+	common.AssertEqual(t, http.StatusOK, w.Code)
+	b := &BundleResponse{}
+	common.AssertError(t, json.Unmarshal(w.Body.Bytes(), b))
+	common.AssertEqual(t, "mnist-card", b.ModelCardKey)
+	common.AssertEqual(t, frontMatterCard, b.ModelCard)
+}
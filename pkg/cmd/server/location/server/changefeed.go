@@ -0,0 +1,91 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/klog/v2"
+)
+
+// ChangeNotifier is a feed of the keys that changed in storage, for storage backends that push change
+// notifications rather than having to be polled
+type ChangeNotifier interface {
+	// Changes returns a channel of batches of changed keys, which is closed when the feed ends
+	Changes(stopCh <-chan struct{}) <-chan []string
+}
+
+// SetChangeNotifier has the server reconcile the keys notified by n as they change, instead of reconciling all of
+// storage on the reconcile interval, which it falls back to should the feed end
+func (i *ImportLocationServer) SetChangeNotifier(n ChangeNotifier) {
+	i.notifier = n
+}
+
+// watchChanges reconciles the keys of each batch of changes notified until the feed ends, returning false, or the
+// server is stopped
+func (i *ImportLocationServer) watchChanges(stopCh <-chan struct{}) bool {
+	changes := i.notifier.Changes(stopCh)
+	for {
+		select {
+		case <-stopCh:
+			return true
+		case keys, ok := <-changes:
+			if !ok {
+				return false
+			}
+			if i.namespace != nil {
+				keys = i.namespace.keys(keys)
+			}
+			reconciled := i.reconcileKeys(keys)
+			klog.V(4).Infof("reconcile of %d changed keys with storage complete: %v", len(keys), reconciled)
+		}
+	}
+}
+
+// reconcileKeys fetches the keys from storage in parallel, as the fetch semaphore allows, replacing the locations
+// of those with changed content and removing those storage no longer has.  Keys are fetched even when in
+// quarantine, as storage notifying of a change is reason to retry them.  It returns false if any fetch failed, in
+// which case the location of the key is left as is.
+func (i *ImportLocationServer) reconcileKeys(keys []string) bool {
+	wg := sync.WaitGroup{}
+	failed := atomic.Bool{}
+	for _, key := range keys {
+		source, segs, ok := i.splitStorageKey(key)
+		if !ok {
+			continue
+		}
+		if !i.shards.owns(key) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sb, err := i.fetchStorageBody(key)
+			if err != nil {
+				klog.Error(err.Error())
+				i.quarantine.failure(key)
+				failed.Store(true)
+				return
+			}
+			i.quarantine.success(key)
+			i.lock.Lock()
+			defer i.lock.Unlock()
+			uri := ""
+			// the storage service returns an empty body for keys it does not have
+			if len(sb.Body) > 0 {
+				if len(sb.ReconcilerType) == 0 {
+					sb.ReconcilerType = source
+				}
+				_, uri = i.buildKeyAndURI(segs[0], segs[1], i.formatFor(sb.Body))
+				i.replaceFetched(uri, sb)
+				i.sweeper.seen(uri, key)
+			}
+			for _, other := range i.candidateURIs(segs[0], segs[1]) {
+				if _, ok := i.content[other]; ok && other != uri {
+					i.removeLocation(other)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return !failed.Load()
+}
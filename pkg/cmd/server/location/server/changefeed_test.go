@@ -0,0 +1,71 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+)
+
+// fakeNotifier notifies of the batches of changed keys sent on its channel
+type fakeNotifier struct {
+	changes chan []string
+}
+
+func (f *fakeNotifier) Changes(stopCh <-chan struct{}) <-chan []string {
+	return f.changes
+}
+
+func TestWatchChanges(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{
+		"mnist_v1":   []byte("updated"),
+		"granite_v1": []byte("new"),
+	})
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte("old")},
+			"/mnist/v2/catalog-info.yaml": {content: []byte("removed from storage")},
+			"/other/v1/catalog-info.yaml": {content: []byte("not notified")},
+		},
+		modelcards: map[string]modelCardMetadata{},
+		storage:    st,
+	}
+	n := &fakeNotifier{changes: make(chan []string)}
+	ils.SetChangeNotifier(n)
+	done := make(chan bool)
+	go func() {
+		done <- ils.watchChanges(make(chan struct{}))
+	}()
+
+	n.changes <- []string{"mnist_v1", "mnist_v2", "granite_v1", "bad-key"}
+	// the empty batch is only received once the previous batch is reconciled
+	n.changes <- []string{}
+	// a failed fetch leaves the location as is
+	st.FailKey("granite_v1", true)
+	st.SetContent("granite_v1", []byte("newer"))
+	n.changes <- []string{"granite_v1"}
+	close(n.changes)
+	// the feed ending has the server fall back to polling
+	common.AssertEqual(t, false, <-done)
+
+	content := map[string]string{}
+	for uri, il := range ils.content {
+		content[uri] = string(il.content)
+	}
+	common.AssertEqual(t, map[string]string{
+		"/mnist/v1/catalog-info.yaml":   "updated",
+		"/mnist/v2/catalog-info.yaml":   "",
+		"/granite/v1/catalog-info.yaml": "new",
+		"/other/v1/catalog-info.yaml":   "not notified",
+	}, content)
+	common.AssertEqual(t, 1, st.FetchCount("mnist_v1"))
+	common.AssertEqual(t, 2, st.FetchCount("granite_v1"))
+	common.AssertEqual(t, 0, st.FetchCount("other_v1"))
+}
+
+func TestWatchChangesStopped(t *testing.T) {
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, notifier: &fakeNotifier{changes: make(chan []string)}}
+	stopCh := make(chan struct{})
+	close(stopCh)
+	common.AssertEqual(t, true, ils.watchChanges(stopCh))
+}
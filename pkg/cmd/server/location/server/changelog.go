@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// ChangeEvent is a location event recorded in the change log, numbered in the order it was published
+type ChangeEvent struct {
+	Seq  uint64    `json:"seq"`
+	Type string    `json:"type"`
+	URI  string    `json:"uri"`
+	Time time.Time `json:"time"`
+}
+
+// ChangesResponse lists the recorded events after the sequence number asked for, along with the sequence number of
+// the last event published, to ask for next.  Truncated is set when events after the sequence number asked for have
+// already been dropped from the log, so the client has missed some and should resync from discovery.
+type ChangesResponse struct {
+	Changes   []ChangeEvent `json:"changes"`
+	MaxSeq    uint64        `json:"maxSeq"`
+	Truncated bool          `json:"truncated"`
+}
+
+// changeLog is a bounded ring buffer of the most recent location events, so clients that were disconnected from the
+// event stream can catch up on what changed while they were away; once full, each event overwrites the oldest
+type changeLog struct {
+	lock   sync.Mutex
+	events []ChangeEvent
+	// seq is the sequence number of the last event recorded, numbering from 1
+	seq uint64
+}
+
+func newChangeLog(size int) *changeLog {
+	return &changeLog{events: make([]ChangeEvent, size)}
+}
+
+// record numbers and records an event; a nil log records nothing
+func (l *changeLog) record(e LocationEvent) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.seq++
+	l.events[(l.seq-1)%uint64(len(l.events))] = ChangeEvent{Seq: l.seq, Type: e.Type, URI: e.URI, Time: time.Now()}
+}
+
+// since returns the recorded events after the sequence number, oldest first
+func (l *changeLog) since(seq uint64) *ChangesResponse {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	resp := &ChangesResponse{Changes: []ChangeEvent{}, MaxSeq: l.seq}
+	if seq >= l.seq {
+		return resp
+	}
+	oldest := uint64(1)
+	if size := uint64(len(l.events)); l.seq > size {
+		oldest = l.seq - size + 1
+	}
+	next := seq + 1
+	if next < oldest {
+		next, resp.Truncated = oldest, true
+	}
+	for ; next <= l.seq; next++ {
+		resp.Changes = append(resp.Changes, l.events[(next-1)%uint64(len(l.events))])
+	}
+	return resp
+}
+
+// handleChangesGet responds with the location events recorded after the since query parameter, or all those
+// recorded when it is not set; without a change log the endpoint is not found
+func (i *ImportLocationServer) handleChangesGet(c *gin.Context) {
+	if i.events == nil || i.events.changes == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	seq := uint64(0)
+	if raw := c.Query(util.SinceQueryParam); len(raw) > 0 {
+		var err error
+		seq, err = strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			c.Error(fmt.Errorf("the %s query parameter must be a sequence number: %s", util.SinceQueryParam, err.Error()))
+			return
+		}
+	}
+	content, err := json.Marshal(i.events.changes.since(seq))
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
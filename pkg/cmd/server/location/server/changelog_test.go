@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestChangesEndpoint(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{},
+		events:     newEventBroker(0),
+	}
+	ils.events.changes = newChangeLog(3)
+	r := newBareRouter()
+	ils.registerRoutes(r, nil)
+	serve := func(method, path string, pb *rest.PostBody) *httptest.ResponseRecorder {
+		body := bytes.NewReader(nil)
+		if pb != nil {
+			data, err := json.Marshal(pb)
+			common.AssertError(t, err)
+			body = bytes.NewReader(data)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, body))
+		return rec
+	}
+	changes := func(since string) *ChangesResponse {
+		rec := serve(http.MethodGet, util.ChangesURI+since, nil)
+		common.AssertEqual(t, http.StatusOK, rec.Code)
+		resp := &ChangesResponse{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+		return resp
+	}
+	type change struct {
+		seq      uint64
+		typ, uri string
+	}
+	summarize := func(resp *ChangesResponse) []change {
+		got := []change{}
+		for _, e := range resp.Changes {
+			got = append(got, change{seq: e.Seq, typ: e.Type, uri: e.URI})
+		}
+		return got
+	}
+
+	resp := changes("")
+	common.AssertEqual(t, uint64(0), resp.MaxSeq)
+	common.AssertEqual(t, 0, len(resp.Changes))
+
+	common.AssertEqual(t, http.StatusCreated, serve(http.MethodPost, util.UpsertURI+"?key=mnist_v1", &rest.PostBody{Body: []byte(mnistEntity)}).Code)
+	common.AssertEqual(t, http.StatusCreated, serve(http.MethodPost, util.UpsertURI+"?key=granite_v1", &rest.PostBody{Body: []byte(graniteEntity)}).Code)
+	resp = changes("")
+	common.AssertEqual(t, []change{
+		{seq: 1, typ: eventUpsert, uri: "/mnist/v1/catalog-info.yaml"},
+		{seq: 2, typ: eventUpsert, uri: "/granite/v1/catalog-info.yaml"},
+	}, summarize(resp))
+	common.AssertEqual(t, uint64(2), resp.MaxSeq)
+	common.AssertEqual(t, false, resp.Truncated)
+
+	// polling from the last sequence number seen returns only what changed since
+	common.AssertEqual(t, http.StatusOK, serve(http.MethodDelete, util.RemoveURI+"?key=mnist_v1", nil).Code)
+	resp = changes(fmt.Sprintf("?since=%d", resp.MaxSeq))
+	common.AssertEqual(t, []change{{seq: 3, typ: eventRemove, uri: "/mnist/v1/catalog-info.yaml"}}, summarize(resp))
+	common.AssertEqual(t, uint64(3), resp.MaxSeq)
+	resp = changes("?since=3")
+	common.AssertEqual(t, 0, len(resp.Changes))
+	common.AssertEqual(t, uint64(3), resp.MaxSeq)
+
+	// once the log wraps around, the oldest events are dropped and polls from before them are marked truncated
+	common.AssertEqual(t, http.StatusCreated, serve(http.MethodPost, util.UpsertURI+"?key=mnist_v1", &rest.PostBody{Body: []byte(mnistUpdatedEntity)}).Code)
+	common.AssertEqual(t, http.StatusOK, serve(http.MethodDelete, util.RemoveURI+"?key=granite_v1", nil).Code)
+	resp = changes("?since=1")
+	common.AssertEqual(t, []change{
+		{seq: 3, typ: eventRemove, uri: "/mnist/v1/catalog-info.yaml"},
+		{seq: 4, typ: eventUpsert, uri: "/mnist/v1/catalog-info.yaml"},
+		{seq: 5, typ: eventRemove, uri: "/granite/v1/catalog-info.yaml"},
+	}, summarize(resp))
+	common.AssertEqual(t, true, resp.Truncated)
+	resp = changes("?since=3")
+	common.AssertEqual(t, []change{
+		{seq: 4, typ: eventUpsert, uri: "/mnist/v1/catalog-info.yaml"},
+		{seq: 5, typ: eventRemove, uri: "/granite/v1/catalog-info.yaml"},
+	}, summarize(resp))
+	common.AssertEqual(t, false, resp.Truncated)
+
+	common.AssertEqual(t, http.StatusBadRequest, serve(http.MethodGet, util.ChangesURI+"?since=latest", nil).Code)
+
+	// without a change log the endpoint is not found
+	ils.events.changes = nil
+	common.AssertEqual(t, http.StatusNotFound, serve(http.MethodGet, util.ChangesURI, nil).Code)
+}
+
+func TestChangeLogWraparound(t *testing.T) {
+	l := newChangeLog(4)
+	for n := 1; n <= 10; n++ {
+		l.record(LocationEvent{Type: eventUpsert, URI: fmt.Sprintf("/model%d/v1/catalog-info.yaml", n)})
+	}
+	resp := l.since(0)
+	common.AssertEqual(t, uint64(10), resp.MaxSeq)
+	common.AssertEqual(t, true, resp.Truncated)
+	common.AssertEqual(t, 4, len(resp.Changes))
+	for n, e := range resp.Changes {
+		common.AssertEqual(t, uint64(7+n), e.Seq)
+		common.AssertEqual(t, fmt.Sprintf("/model%d/v1/catalog-info.yaml", 7+n), e.URI)
+	}
+	resp = l.since(8)
+	common.AssertEqual(t, false, resp.Truncated)
+	common.AssertEqual(t, 2, len(resp.Changes))
+	common.AssertEqual(t, uint64(9), resp.Changes[0].Seq)
+	common.AssertEqual(t, 0, len(l.since(10).Changes))
+	common.AssertEqual(t, 0, len(l.since(20).Changes))
+
+	// a nil log records nothing
+	var none *changeLog
+	none.record(LocationEvent{Type: eventRemove, URI: "/mnist/v1/catalog-info.yaml"})
+}
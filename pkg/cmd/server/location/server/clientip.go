@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// noTrustedProxies is the TRUSTED_PROXIES setting that trusts no proxy, so the client IP is the peer's address
+	noTrustedProxies = "none"
+	// noTrustedPlatform is the TRUSTED_PLATFORM setting that trusts no platform header
+	noTrustedPlatform = "none"
+	// defaultTrustedPlatform, along with trusting no proxy, is the configuration the location service has always
+	// run with, which is kept by default but warned about
+	defaultTrustedPlatform = "X-Forwarded-For"
+)
+
+var defaultTrustedProxies = []string{noTrustedProxies}
+
+// platformHeaders are the headers of the platforms gin knows, which their edge sets, overwriting any sent by clients
+var platformHeaders = map[string]bool{
+	http.CanonicalHeaderKey(gin.PlatformGoogleAppEngine): true,
+	http.CanonicalHeaderKey(gin.PlatformCloudflare):      true,
+	http.CanonicalHeaderKey(gin.PlatformFlyIO):           true,
+}
+
+// trustedPlatform returns the header of a TRUSTED_PLATFORM setting, which is empty when no platform is trusted
+func trustedPlatform(platform string) string {
+	platform = strings.TrimSpace(platform)
+	if strings.EqualFold(platform, noTrustedPlatform) {
+		return ""
+	}
+	return platform
+}
+
+// clientIPProblems returns why the client IP, as logged with each request, is unreliable under the trusted platform
+// and proxies, if it is
+func clientIPProblems(platform string, proxies []string) []string {
+	problems := []string{}
+	header := http.CanonicalHeaderKey(trustedPlatform(platform))
+	switch {
+	case len(header) == 0:
+	case header == "X-Forwarded-For":
+		problems = append(problems, fmt.Sprintf("%s %s holds the chain of addresses a request was forwarded from, so the client IP would be the whole chain rather than an address; set %s to %s and %s to the addresses of the ingress instead",
+			types.TrustedPlatformEnvVar, header, types.TrustedPlatformEnvVar, noTrustedPlatform, types.TrustedProxiesEnvVar))
+	case !platformHeaders[header]:
+		problems = append(problems, fmt.Sprintf("%s %s is trusted from any peer, whatever %s is set to, so clients can set their own IP unless every ingress overwrites the header",
+			types.TrustedPlatformEnvVar, header, types.TrustedProxiesEnvVar))
+	}
+	if len(header) > 0 && len(proxies) == 1 && proxies[0] == noTrustedProxies && !platformHeaders[header] {
+		problems = append(problems, fmt.Sprintf("%s is %s, yet the client IP is taken from the %s header rather than the peer's address",
+			types.TrustedProxiesEnvVar, noTrustedProxies, header))
+	}
+	return problems
+}
+
+// configureClientIP sets the platform and proxies whose headers the client IP is taken from, warning of settings
+// that make it unreliable or, when strict, returning an error for them; proxies are gin's default, all, when empty
+func configureClientIP(r *gin.Engine, platform string, proxies []string, strict bool) error {
+	problems := clientIPProblems(platform, proxies)
+	switch {
+	case len(proxies) == 1 && proxies[0] == noTrustedProxies:
+		_ = r.SetTrustedProxies(nil)
+	case len(proxies) > 0:
+		if err := r.SetTrustedProxies(proxies); err != nil {
+			// gin keeps the proxies parsed before the invalid one, so trust none rather than a partial list
+			_ = r.SetTrustedProxies(nil)
+			problems = append(problems, fmt.Sprintf("invalid %s, trusting no proxy: %s", types.TrustedProxiesEnvVar, err.Error()))
+		}
+	}
+	r.TrustedPlatform = trustedPlatform(platform)
+	if len(problems) == 0 {
+		return nil
+	}
+	if strict {
+		return fmt.Errorf("unreliable client IP configuration: %s", strings.Join(problems, "; "))
+	}
+	for _, p := range problems {
+		klog.Warningf("unreliable client IP configuration: %s", p)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestClientIPConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		platform   string
+		proxies    []string
+		problems   int
+		invalid    bool
+		expectedIP string
+	}{
+		{name: "gin defaults", expectedIP: "203.0.113.7"},
+		{name: "no proxies", proxies: []string{noTrustedProxies}, expectedIP: "192.0.2.1"},
+		{name: "ingress proxy", proxies: []string{"192.0.2.0/24"}, expectedIP: "10.0.0.1"},
+		{name: "ingress and forwarding proxies", proxies: []string{"192.0.2.0/24", "10.0.0.0/8"}, expectedIP: "203.0.113.7"},
+		{name: "untrusted proxy", proxies: []string{"198.51.100.0/24"}, expectedIP: "192.0.2.1"},
+		{name: "invalid proxy", proxies: []string{"192.0.2.0/24", "not-an-address"}, invalid: true, expectedIP: "192.0.2.1"},
+		{name: "no platform", platform: noTrustedPlatform, proxies: []string{"192.0.2.0/24", "10.0.0.0/8"}, expectedIP: "203.0.113.7"},
+		{name: "defaults", platform: defaultTrustedPlatform, proxies: defaultTrustedProxies, problems: 2, expectedIP: "203.0.113.7, 10.0.0.1"},
+		{name: "known platform", platform: gin.PlatformCloudflare, proxies: []string{noTrustedProxies}, expectedIP: "198.51.100.9"},
+		{name: "forwarded for platform", platform: "x-forwarded-for", problems: 1, expectedIP: "203.0.113.7, 10.0.0.1"},
+		{name: "forwarded for platform without proxies", platform: "X-Forwarded-For", proxies: []string{noTrustedProxies}, problems: 2, expectedIP: "203.0.113.7, 10.0.0.1"},
+		{name: "spoofable platform", platform: "X-Real-IP", problems: 1, expectedIP: "203.0.113.8"},
+	} {
+		common.AssertEqual(t, tc.problems, len(clientIPProblems(tc.platform, tc.proxies)))
+
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		err := configureClientIP(r, tc.platform, tc.proxies, false)
+		common.AssertError(t, err)
+		ip := ""
+		r.GET("/ip", func(c *gin.Context) {
+			ip = c.ClientIP()
+		})
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "192.0.2.1:4242"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+		req.Header.Set("X-Real-IP", "203.0.113.8")
+		req.Header.Set(gin.PlatformCloudflare, "198.51.100.9")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if ip != tc.expectedIP {
+			t.Errorf("%s: expected client IP %s but got %s", tc.name, tc.expectedIP, ip)
+		}
+
+		err = configureClientIP(newRouter(io.Discard, nil, defaultRequestIdHeader), tc.platform, tc.proxies, true)
+		common.AssertEqual(t, tc.problems > 0 || tc.invalid, err != nil)
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+const (
+	defaultCompressionSamples = 100
+	maxCompressionSamples     = 1000
+)
+
+// CompressionStats reports how well the served content compresses with gzip, from a sample of the locations.  A
+// ratio is the compressed size over the original size, so lower is more compressible; the average is over the
+// sampled locations, and the estimated savings extrapolate the sampled bytes saved to every location.
+type CompressionStats struct {
+	Locations             int     `json:"locations"`
+	Sampled               int     `json:"sampled"`
+	SampledBytes          int     `json:"sampledBytes"`
+	CompressedBytes       int     `json:"compressedBytes"`
+	AverageRatio          float64 `json:"averageRatio"`
+	MinRatio              float64 `json:"minRatio"`
+	MaxRatio              float64 `json:"maxRatio"`
+	EstimatedSavingsBytes int     `json:"estimatedSavingsBytes"`
+}
+
+// gzipSize returns the size of content compressed with gzip at the default level
+func gzipSize(content []byte) (int, error) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(content); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	return buf.Len(), nil
+}
+
+// sampleURIs returns up to n of the sorted URIs, evenly spaced, so repeated samples of an unchanged catalog agree
+func sampleURIs(uris []string, n int) []string {
+	sort.Strings(uris)
+	if len(uris) <= n {
+		return uris
+	}
+	sampled := make([]string, 0, n)
+	for idx := 0; idx < n; idx++ {
+		sampled = append(sampled, uris[idx*len(uris)/n])
+	}
+	return sampled
+}
+
+// compressionStats compresses the content of up to samples of the served locations
+func (i *ImportLocationServer) compressionStats(samples int) (*CompressionStats, error) {
+	i.lock.RLock()
+	uris := []string{}
+	for uri, il := range i.content {
+		if il.content != nil {
+			uris = append(uris, uri)
+		}
+	}
+	stats := &CompressionStats{Locations: len(uris)}
+	contents := [][]byte{}
+	for _, uri := range sampleURIs(uris, samples) {
+		contents = append(contents, i.plaintext(i.content[uri].content))
+	}
+	i.lock.RUnlock()
+
+	// content is compressed outside of the lock, as a large sample takes a while
+	total := 0.0
+	for _, content := range contents {
+		if len(content) == 0 {
+			continue
+		}
+		size, err := gzipSize(content)
+		if err != nil {
+			return nil, err
+		}
+		ratio := float64(size) / float64(len(content))
+		if stats.Sampled == 0 || ratio < stats.MinRatio {
+			stats.MinRatio = ratio
+		}
+		if ratio > stats.MaxRatio {
+			stats.MaxRatio = ratio
+		}
+		total += ratio
+		stats.Sampled++
+		stats.SampledBytes += len(content)
+		stats.CompressedBytes += size
+	}
+	if stats.Sampled > 0 {
+		stats.AverageRatio = total / float64(stats.Sampled)
+		saved := float64(stats.SampledBytes-stats.CompressedBytes) / float64(stats.Sampled)
+		stats.EstimatedSavingsBytes = int(saved * float64(stats.Locations))
+	}
+	return stats, nil
+}
+
+// handleCompressionStatsGet reports how compressible the served content is, sampling as many locations as the
+// limit parameter asks, to help decide whether compressing content is worthwhile
+func (i *ImportLocationServer) handleCompressionStatsGet(c *gin.Context) {
+	if i.readThrough != nil {
+		c.Status(http.StatusNotImplemented)
+		c.Error(fmt.Errorf("no content is held in memory to sample in read-through mode"))
+		return
+	}
+	samples := defaultCompressionSamples
+	if str := c.Query(util.LimitQueryParam); len(str) > 0 {
+		n, err := strconv.Atoi(str)
+		if err != nil || n < 1 || n > maxCompressionSamples {
+			c.Status(http.StatusBadRequest)
+			c.Error(fmt.Errorf("the '%s' parameter must be between 1 and %d: %s", util.LimitQueryParam, maxCompressionSamples, str))
+			return
+		}
+		samples = n
+	}
+	stats, err := i.compressionStats(samples)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	content, err := json.Marshal(stats)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
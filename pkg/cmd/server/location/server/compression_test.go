@@ -0,0 +1,90 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleCompressionStatsGet(t *testing.T) {
+	repetitive := []byte(strings.Repeat("kind: Component\n", 256))
+	random := make([]byte, 4096)
+	// a xorshift sequence compresses poorly, yet is the same each run
+	x := uint32(2463534242)
+	for idx := range random {
+		x ^= x << 13
+		x ^= x >> 17
+		x ^= x << 5
+		random[idx] = byte(x)
+	}
+	compressed := func(content []byte) int {
+		buf := &bytes.Buffer{}
+		w := gzip.NewWriter(buf)
+		_, err := w.Write(content)
+		common.AssertError(t, err)
+		common.AssertError(t, w.Close())
+		return buf.Len()
+	}
+	repetitiveRatio := float64(compressed(repetitive)) / float64(len(repetitive))
+	randomRatio := float64(compressed(random)) / float64(len(random))
+
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":  {content: repetitive},
+			"/random/v1/catalog-info.yaml": {content: random},
+			"/gone/v1/catalog-info.yaml":   {},
+		},
+		adminToken: "admin-secret",
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.GET(util.CompressionStatsURI, adminAuth(ils.adminToken), ils.handleCompressionStatsGet)
+	get := func(query string) (*httptest.ResponseRecorder, CompressionStats) {
+		req := httptest.NewRequest(http.MethodGet, util.CompressionStatsURI+query, nil)
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		stats := CompressionStats{}
+		if rec.Code == http.StatusOK {
+			common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+		}
+		return rec, stats
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.CompressionStatsURI, nil))
+	common.AssertEqual(t, http.StatusUnauthorized, rec.Code)
+
+	rec, stats := get("")
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, 2, stats.Locations)
+	common.AssertEqual(t, 2, stats.Sampled)
+	common.AssertEqual(t, len(repetitive)+len(random), stats.SampledBytes)
+	common.AssertEqual(t, compressed(repetitive)+compressed(random), stats.CompressedBytes)
+	common.AssertEqual(t, repetitiveRatio, stats.MinRatio)
+	common.AssertEqual(t, randomRatio, stats.MaxRatio)
+	common.AssertEqual(t, (repetitiveRatio+randomRatio)/2, stats.AverageRatio)
+	common.AssertEqual(t, stats.SampledBytes-stats.CompressedBytes, stats.EstimatedSavingsBytes)
+	common.AssertEqual(t, true, stats.MinRatio < 0.1)
+	// content that does not compress grows by the gzip framing
+	common.AssertEqual(t, true, stats.MaxRatio > 1)
+
+	// the sample is bounded, with the savings extrapolated to every location
+	rec, stats = get("?limit=1")
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, 2, stats.Locations)
+	common.AssertEqual(t, 1, stats.Sampled)
+	common.AssertEqual(t, repetitiveRatio, stats.AverageRatio)
+	common.AssertEqual(t, 2*(len(repetitive)-compressed(repetitive)), stats.EstimatedSavingsBytes)
+
+	rec, _ = get(fmt.Sprintf("?limit=%d", maxCompressionSamples+1))
+	common.AssertEqual(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/server/storage"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// redacted stands in for the value of a secret that is set, so a config view shows whether it is without showing it
+const redacted = "<redacted>"
+
+// ConfigResponse is the effective configuration of the location service; secrets are redacted, and durations are
+// in Go duration format, where zero means the feature is disabled
+type ConfigResponse struct {
+	Format                   string            `json:"format"`
+	Port                     string            `json:"port"`
+	StorageURL               string            `json:"storageURL,omitempty"`
+	StorageToken             string            `json:"storageToken,omitempty"`
+	StorageKeyPrefix         string            `json:"storageKeyPrefix,omitempty"`
+	AdminToken               string            `json:"adminToken,omitempty"`
+	ScopeAuth                bool              `json:"scopeAuth"`
+	URITemplate              string            `json:"uriTemplate"`
+	MaxURILength             int               `json:"maxURILength"`
+	VersionPattern           string            `json:"versionPattern,omitempty"`
+	LatestVersionOrder       string            `json:"latestVersionOrder,omitempty"`
+	ContentHashAlgorithm     string            `json:"contentHashAlgorithm"`
+	LoadMaxKeyLength         int               `json:"loadMaxKeyLength"`
+	LoadMaxContentBytes      int               `json:"loadMaxContentBytes"`
+	ChangeLogSize            int               `json:"changeLogSize"`
+	EntityUniqueness         bool              `json:"entityUniqueness"`
+	FetchOnMiss              bool              `json:"fetchOnMiss"`
+	LocationMaxAge           string            `json:"locationMaxAge"`
+	StaleIfError             bool              `json:"staleIfError"`
+	FormatAutoDetect         bool              `json:"formatAutoDetect"`
+	ServeValidation          bool              `json:"serveValidation"`
+	StorageFetchConcurrency  int               `json:"storageFetchConcurrency"`
+	ReconcileInterval        string            `json:"reconcileInterval"`
+	ReconcileStaleAfter      string            `json:"reconcileStaleAfter"`
+	ReadLockTimeout          string            `json:"readLockTimeout"`
+	ResponseWriteTimeout     string            `json:"responseWriteTimeout"`
+	ServeRetryBackoff        string            `json:"serveRetryBackoff"`
+	ServeRetryLimit          int               `json:"serveRetryLimit"`
+	QuarantineThreshold      int               `json:"quarantineThreshold"`
+	QuarantineBaseBackoff    string            `json:"quarantineBaseBackoff,omitempty"`
+	QuarantineMaxBackoff     string            `json:"quarantineMaxBackoff,omitempty"`
+	ReloadUpsertStrategy     string            `json:"reloadUpsertStrategy"`
+	ReloadUpsertWait         string            `json:"reloadUpsertWait"`
+	ModelCardMaxAge          string            `json:"modelCardMaxAge"`
+	ModelCardRefetchInterval string            `json:"modelCardRefetchInterval"`
+	ModelCardMaxResident     int               `json:"modelCardMaxResident"`
+	ModelCardKeyConflicts    bool              `json:"modelCardKeyConflicts"`
+	AttachmentMaxBytes       int               `json:"modelCardAttachmentMaxBytes"`
+	ModelCardFallback        bool              `json:"modelCardFallback"`
+	StorageWriteBehindWindow string            `json:"storageWriteBehindWindow"`
+	StorageDeleteMode        string            `json:"storageDeleteMode"`
+	StorageDeleteRetries     int               `json:"storageDeleteRetries"`
+	IngestTransformers       int               `json:"ingestTransformers"`
+	ServeTransformers        int               `json:"serveTransformers"`
+	IngestAnnotations        map[string]string `json:"ingestAnnotations,omitempty"`
+	RedactJSONPaths          []string          `json:"redactJSONPaths,omitempty"`
+	StripPaths               []string          `json:"stripPaths,omitempty"`
+	ReferenceBaseURL         string            `json:"referenceBaseURL,omitempty"`
+	ModelCardSigningKey      string            `json:"modelCardSigningKey,omitempty"`
+	DiscoveryShape           string            `json:"discoveryShape"`
+	SSEHeartbeatInterval     string            `json:"sseHeartbeatInterval"`
+	EventsMaxSubscribers     int               `json:"eventsMaxSubscribers"`
+	ContentTypes             map[string]string `json:"contentTypes"`
+	DeadLetterFile           string            `json:"deadLetterFile,omitempty"`
+	DeadLetterMaxBytes       int64             `json:"deadLetterMaxBytes,omitempty"`
+	Tracing                  bool              `json:"tracing"`
+	SourceMetrics            bool              `json:"sourceMetrics"`
+	NotReadyServes503        bool              `json:"notReadyServes503"`
+	SkipStartupLoad          bool              `json:"skipStartupLoad"`
+	ContentEncryptionKey     string            `json:"contentEncryptionKey,omitempty"`
+	RequestIdHeader          string            `json:"requestIdHeader"`
+	BareRouter               bool              `json:"bareRouter"`
+	TLSCertFile              string            `json:"tlsCertFile,omitempty"`
+	TLSKeyFile               string            `json:"tlsKeyFile,omitempty"`
+	ContentNegotiation       bool              `json:"contentNegotiation"`
+	SchemaNegotiation        bool              `json:"schemaNegotiation"`
+	EmptyModelCardMode       string            `json:"emptyModelCardMode"`
+	KeyIdentityCheck         string            `json:"keyIdentityCheck"`
+	LegacyKeyWarning         bool              `json:"legacyKeyWarning"`
+	NormalizeWhitespace      bool              `json:"normalizeWhitespace"`
+	LookupNotFoundGuidance   bool              `json:"lookupNotFoundGuidance"`
+	EntityOwnershipLock      bool              `json:"entityOwnershipLock"`
+	ServeMetadataAnnotations bool              `json:"serveMetadataAnnotations"`
+	InstanceID               string            `json:"instanceId,omitempty"`
+	StorageKeyPrefixes       map[string]string `json:"storageKeyPrefixes,omitempty"`
+	LocationRefreshTTL       string            `json:"locationRefreshTTL,omitempty"`
+	EntityOwnershipFile      string            `json:"entityOwnershipFile,omitempty"`
+	ModelCardPlaceholder     string            `json:"modelCardPlaceholder,omitempty"`
+	ShardIndex               int               `json:"shardIndex"`
+	ShardCount               int               `json:"shardCount"`
+	UpsertMaxHeapBytes       uint64            `json:"upsertMaxHeapBytes,omitempty"`
+	MemorySampleInterval     string            `json:"memorySampleInterval,omitempty"`
+	ReadThrough              bool              `json:"readThrough"`
+	ReadThroughCacheTTL      string            `json:"readThroughCacheTTL,omitempty"`
+	ReadThroughCacheEntries  int               `json:"readThroughCacheMaxEntries,omitempty"`
+	SourceConcurrencyBudgets map[string]int    `json:"sourceConcurrencyBudgets,omitempty"`
+	ModelsIncludeEmpty       bool              `json:"modelsIncludeEmpty"`
+	TrustedPlatform          string            `json:"trustedPlatform,omitempty"`
+	BulkMaxConcurrentJobs    int               `json:"bulkMaxConcurrentJobs"`
+}
+
+func redact(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+	return redacted
+}
+
+// config returns the effective configuration; callers must hold the server lock
+func (i *ImportLocationServer) config() *ConfigResponse {
+	cfg := &ConfigResponse{
+		Format:                   string(i.format),
+		Port:                     i.port,
+		AdminToken:               redact(i.adminToken),
+		ScopeAuth:                i.scopes != nil,
+		URITemplate:              defaultURITemplate,
+		MaxURILength:             i.maxURILength,
+		VersionPattern:           i.rawVersionPattern,
+		LatestVersionOrder:       string(i.latestOrder),
+		ContentHashAlgorithm:     string(hashSHA256),
+		LoadMaxKeyLength:         i.loadLimits.maxKeyLength,
+		LoadMaxContentBytes:      i.loadLimits.maxContentBytes,
+		EntityUniqueness:         i.entityUniqueness,
+		FetchOnMiss:              i.fetchOnMiss,
+		LocationMaxAge:           i.locationMaxAge.String(),
+		StaleIfError:             i.staleIfError,
+		FormatAutoDetect:         i.formatAutoDetect,
+		ServeValidation:          i.serveValidation,
+		StorageFetchConcurrency:  i.fetchConcurrency,
+		ReconcileInterval:        i.reconcileInterval.String(),
+		ReconcileStaleAfter:      i.reconcileStaleAfter.String(),
+		ReadLockTimeout:          i.readLockTimeout.String(),
+		ResponseWriteTimeout:     i.responseWriteTimeout.String(),
+		ServeRetryBackoff:        i.serveRetryBackoff.String(),
+		ServeRetryLimit:          i.serveRetryLimit,
+		ReloadUpsertStrategy:     string(i.reloadStrategy),
+		ReloadUpsertWait:         i.reloadWait.String(),
+		ModelCardMaxAge:          i.modelCardMaxAge.String(),
+		ModelCardRefetchInterval: i.modelCardRefetchInterval.String(),
+		ModelCardMaxResident:     i.modelCardMaxResident,
+		ModelCardKeyConflicts:    i.modelCardKeyConflicts,
+		AttachmentMaxBytes:       i.attachmentMaxBytes,
+		ModelCardFallback:        i.modelCardFallback,
+		StorageWriteBehindWindow: "0s",
+		StorageDeleteMode:        string(parseStorageDeleteMode(string(i.storageDeleteMode))),
+		StorageDeleteRetries:     i.storageDeleteRetries,
+		IngestTransformers:       len(i.ingestTransformers),
+		ServeTransformers:        len(i.serveTransformers),
+		RedactJSONPaths:          i.redactJSONPaths,
+		StripPaths:               i.stripPaths,
+		ReferenceBaseURL:         i.referenceBaseURL,
+		DiscoveryShape:           string(discoveryShapeUris),
+		SSEHeartbeatInterval:     i.sseHeartbeatInterval.String(),
+		ContentTypes:             map[string]string{},
+		Tracing:                  i.tracing,
+		SourceMetrics:            i.sourceMetrics,
+		NotReadyServes503:        i.notReadyServes503,
+		SkipStartupLoad:          i.skipStartupLoad,
+		RequestIdHeader:          i.requestIdHeader,
+		BareRouter:               i.bare,
+		ContentNegotiation:       i.contentNegotiation,
+		SchemaNegotiation:        i.schemaNegotiation,
+		EmptyModelCardMode:       string(parseEmptyModelCardMode(string(i.emptyModelCardMode))),
+		KeyIdentityCheck:         string(parseKeyIdentityCheck(string(i.keyIdentityCheck))),
+		LegacyKeyWarning:         i.legacyKeyWarning,
+		NormalizeWhitespace:      i.normalizeWhitespace,
+		LookupNotFoundGuidance:   i.lookupNotFoundGuidance,
+		EntityOwnershipLock:      i.ownership != nil,
+		ServeMetadataAnnotations: i.serveAnnotations,
+		InstanceID:               i.instanceID,
+		StorageKeyPrefixes:       i.storageKeyPrefixes,
+		ShardCount:               1,
+		ModelsIncludeEmpty:       i.modelsIncludeEmpty,
+	}
+	if i.bulkJobs != nil {
+		cfg.BulkMaxConcurrentJobs = i.bulkJobs.maxRunning
+	}
+	if i.events != nil {
+		cfg.EventsMaxSubscribers = i.events.maxSubscribers
+		if i.events.changes != nil {
+			cfg.ChangeLogSize = len(i.events.changes.events)
+		}
+	}
+	if i.router != nil {
+		cfg.TrustedPlatform = i.router.TrustedPlatform
+	}
+	if len(i.discoveryShape) > 0 {
+		cfg.DiscoveryShape = string(i.discoveryShape)
+	}
+	if len(i.contentHash) > 0 {
+		cfg.ContentHashAlgorithm = string(i.contentHash)
+	}
+	for _, f := range []types.NormalizerFormat{types.CatalogInfoYamlFormat, types.JsonArrayForamt} {
+		cfg.ContentTypes[string(f)] = i.contentTypeFor(f)
+	}
+	if len(cfg.RequestIdHeader) == 0 {
+		cfg.RequestIdHeader = defaultRequestIdHeader
+	}
+	if i.signer != nil {
+		cfg.ModelCardSigningKey = redacted
+	}
+	if i.cipher != nil {
+		cfg.ContentEncryptionKey = redacted
+	}
+	if i.deadLetters != nil {
+		cfg.DeadLetterFile = i.deadLetters.path
+		cfg.DeadLetterMaxBytes = i.deadLetters.maxBytes
+	}
+	if i.sweeper != nil {
+		cfg.LocationRefreshTTL = i.sweeper.ttl.String()
+	}
+	if i.ownership != nil {
+		cfg.EntityOwnershipFile = i.ownership.path
+	}
+	if i.certs != nil {
+		cfg.TLSCertFile = i.certs.certFile
+		cfg.TLSKeyFile = i.certs.keyFile
+	}
+	if cfg.EmptyModelCardMode == string(emptyModelCardPlaceholder) {
+		cfg.ModelCardPlaceholder = i.modelCardPlaceholder
+	}
+	if i.shards != nil {
+		cfg.ShardIndex = i.shards.index
+		cfg.ShardCount = i.shards.count
+	}
+	if i.memoryGuard != nil {
+		cfg.UpsertMaxHeapBytes = i.memoryGuard.threshold
+		cfg.MemorySampleInterval = i.memoryGuard.interval.String()
+	}
+	if i.readThrough != nil {
+		cfg.ReadThrough = true
+		cfg.ReadThroughCacheTTL = i.readThrough.ttl.String()
+		cfg.ReadThroughCacheEntries = i.readThrough.maxEntries
+	}
+	if len(i.sourceBudgets) > 0 {
+		cfg.SourceConcurrencyBudgets = map[string]int{}
+		for source, sem := range i.sourceBudgets {
+			cfg.SourceConcurrencyBudgets[source] = cap(sem)
+		}
+	}
+	var client storageClient = i.storage
+	if i.namespace != nil {
+		client = i.namespace.client
+		cfg.StorageKeyPrefix = i.namespace.prefix
+	}
+	if rc, ok := client.(*storage.BridgeStorageRESTClient); ok && rc != nil {
+		cfg.StorageURL = strings.TrimSuffix(rc.ListURL, util.ListURI)
+		cfg.StorageToken = redact(rc.Token)
+	}
+	if t := i.uriTemplate.Load(); t != nil {
+		cfg.URITemplate = t.String()
+	}
+	if i.quarantine != nil {
+		cfg.QuarantineThreshold = i.quarantine.threshold
+		cfg.QuarantineBaseBackoff = i.quarantine.baseBackoff.String()
+		cfg.QuarantineMaxBackoff = i.quarantine.maxBackoff.String()
+	}
+	if i.writeBehind != nil {
+		cfg.StorageWriteBehindWindow = i.writeBehind.window.String()
+	}
+	for _, t := range i.ingestTransformers {
+		if a, ok := t.(*annotationTransformer); ok {
+			cfg.IngestAnnotations = a.annotations
+		}
+	}
+	return cfg
+}
+
+func (i *ImportLocationServer) handleConfigGet(c *gin.Context) {
+	i.lock.RLock()
+	cfg := i.config()
+	i.lock.RUnlock()
+	buf, err := json.Marshal(cfg)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", buf)
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/server/storage"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleConfigGet(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		adminToken string
+		token      string
+		expectedSC int
+	}{
+		{
+			name:       "no auth configured",
+			expectedSC: http.StatusOK,
+		},
+		{
+			name:       "auth configured without a token",
+			adminToken: "admin-secret",
+			expectedSC: http.StatusUnauthorized,
+		},
+		{
+			name:       "auth configured with the token",
+			adminToken: "admin-secret",
+			token:      "admin-secret",
+			expectedSC: http.StatusOK,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:           map[string]*ImportLocation{},
+			storage:           storage.SetupBridgeStorageRESTClient("http://storage:8080", "storage-secret"),
+			format:            types.CatalogInfoYamlFormat,
+			port:              "9090",
+			fetchOnMiss:       true,
+			fetchConcurrency:  4,
+			reconcileInterval: time.Minute,
+			quarantine:        newQuarantine(3, time.Second, time.Hour),
+			adminToken:        tc.adminToken,
+		}
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		r.GET(util.ConfigURI, optionalAdminAuth(ils.adminToken), ils.handleConfigGet)
+		req := httptest.NewRequest(http.MethodGet, util.ConfigURI, nil)
+		if len(tc.token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		common.AssertEqual(t, tc.expectedSC, rec.Code)
+		if tc.expectedSC != http.StatusOK {
+			continue
+		}
+		body := rec.Body.String()
+		common.AssertEqual(t, false, strings.Contains(body, "storage-secret"))
+		common.AssertEqual(t, false, strings.Contains(body, "admin-secret"))
+		cfg := &ConfigResponse{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), cfg))
+		common.AssertEqual(t, redacted, cfg.StorageToken)
+		common.AssertEqual(t, redact(tc.adminToken), cfg.AdminToken)
+		common.AssertEqual(t, "http://storage:8080", cfg.StorageURL)
+		common.AssertEqual(t, string(types.CatalogInfoYamlFormat), cfg.Format)
+		common.AssertEqual(t, "9090", cfg.Port)
+		common.AssertEqual(t, true, cfg.FetchOnMiss)
+		common.AssertEqual(t, 4, cfg.StorageFetchConcurrency)
+		common.AssertEqual(t, "1m0s", cfg.ReconcileInterval)
+		common.AssertEqual(t, 3, cfg.QuarantineThreshold)
+		common.AssertEqual(t, defaultURITemplate, cfg.URITemplate)
+		common.AssertEqual(t, string(storageDeleteOff), cfg.StorageDeleteMode)
+	}
+}
+
+func TestConfigFeatures(t *testing.T) {
+	shards, err := newShardRing(1, 3)
+	common.AssertError(t, err)
+	for _, tc := range []struct {
+		name     string
+		ils      *ImportLocationServer
+		expected func(cfg *ConfigResponse)
+	}{
+		{
+			name: "defaults",
+			ils:  &ImportLocationServer{sseHeartbeatInterval: defaultSSEHeartbeatInterval},
+			expected: func(cfg *ConfigResponse) {
+				common.AssertEqual(t, string(discoveryShapeUris), cfg.DiscoveryShape)
+				common.AssertEqual(t, "15s", cfg.SSEHeartbeatInterval)
+				common.AssertEqual(t, map[string]string{string(types.CatalogInfoYamlFormat): mimeYAML, string(types.JsonArrayForamt): mimeJSON}, cfg.ContentTypes)
+				common.AssertEqual(t, defaultRequestIdHeader, cfg.RequestIdHeader)
+				common.AssertEqual(t, string(emptyModelCardSkip), cfg.EmptyModelCardMode)
+				common.AssertEqual(t, "", cfg.ModelCardPlaceholder)
+				common.AssertEqual(t, "", cfg.ModelCardSigningKey)
+				common.AssertEqual(t, "", cfg.ContentEncryptionKey)
+				common.AssertEqual(t, "", cfg.DeadLetterFile)
+				common.AssertEqual(t, "", cfg.TLSCertFile)
+				common.AssertEqual(t, 0, cfg.ShardIndex)
+				common.AssertEqual(t, 1, cfg.ShardCount)
+				common.AssertEqual(t, uint64(0), cfg.UpsertMaxHeapBytes)
+				common.AssertEqual(t, false, cfg.ReadThrough)
+				common.AssertEqual(t, 0, len(cfg.SourceConcurrencyBudgets))
+			},
+		},
+		{
+			name: "features enabled",
+			ils: &ImportLocationServer{
+				signer:               newURLSigner("signing-secret"),
+				cipher:               &contentCipher{},
+				discoveryShape:       discoveryShapeTargets,
+				sseHeartbeatInterval: time.Minute,
+				contentTypes:         map[types.NormalizerFormat]string{types.CatalogInfoYamlFormat: "text/yaml"},
+				deadLetters:          &deadLetterLog{path: "/var/log/dead-letters.jsonl", maxBytes: 1024},
+				tracing:              true,
+				notReadyServes503:    true,
+				requestIdHeader:      "X-Correlation-Id",
+				certs:                &certReloader{certFile: "/etc/tls/tls.crt", keyFile: "/etc/tls/tls.key"},
+				contentNegotiation:   true,
+				emptyModelCardMode:   emptyModelCardPlaceholder,
+				modelCardPlaceholder: "No model card",
+				shards:               shards,
+				memoryGuard:          &memoryGuard{threshold: 1 << 30, interval: 5 * time.Second},
+				readThrough:          newReadThroughCache(30*time.Second, 100),
+				sourceBudgets:        newSourceBudgets(map[string]string{types.KServeNormalizer: "2"}),
+				redactJSONPaths:      []string{"spec.endpoint"},
+				modelsIncludeEmpty:   true,
+			},
+			expected: func(cfg *ConfigResponse) {
+				common.AssertEqual(t, redacted, cfg.ModelCardSigningKey)
+				common.AssertEqual(t, redacted, cfg.ContentEncryptionKey)
+				common.AssertEqual(t, string(discoveryShapeTargets), cfg.DiscoveryShape)
+				common.AssertEqual(t, "1m0s", cfg.SSEHeartbeatInterval)
+				common.AssertEqual(t, "text/yaml", cfg.ContentTypes[string(types.CatalogInfoYamlFormat)])
+				common.AssertEqual(t, "/var/log/dead-letters.jsonl", cfg.DeadLetterFile)
+				common.AssertEqual(t, int64(1024), cfg.DeadLetterMaxBytes)
+				common.AssertEqual(t, true, cfg.Tracing)
+				common.AssertEqual(t, true, cfg.NotReadyServes503)
+				common.AssertEqual(t, "X-Correlation-Id", cfg.RequestIdHeader)
+				common.AssertEqual(t, "/etc/tls/tls.crt", cfg.TLSCertFile)
+				common.AssertEqual(t, "/etc/tls/tls.key", cfg.TLSKeyFile)
+				common.AssertEqual(t, true, cfg.ContentNegotiation)
+				common.AssertEqual(t, string(emptyModelCardPlaceholder), cfg.EmptyModelCardMode)
+				common.AssertEqual(t, "No model card", cfg.ModelCardPlaceholder)
+				common.AssertEqual(t, 1, cfg.ShardIndex)
+				common.AssertEqual(t, 3, cfg.ShardCount)
+				common.AssertEqual(t, uint64(1<<30), cfg.UpsertMaxHeapBytes)
+				common.AssertEqual(t, "5s", cfg.MemorySampleInterval)
+				common.AssertEqual(t, true, cfg.ReadThrough)
+				common.AssertEqual(t, "30s", cfg.ReadThroughCacheTTL)
+				common.AssertEqual(t, 100, cfg.ReadThroughCacheEntries)
+				common.AssertEqual(t, map[string]int{types.KServeNormalizer: 2}, cfg.SourceConcurrencyBudgets)
+				common.AssertEqual(t, []string{"spec.endpoint"}, cfg.RedactJSONPaths)
+				common.AssertEqual(t, true, cfg.ModelsIncludeEmpty)
+			},
+		},
+	} {
+		rec := httptest.NewRecorder()
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		r.GET(util.ConfigURI, tc.ils.handleConfigGet)
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ConfigURI, nil))
+		common.AssertEqual(t, http.StatusOK, rec.Code)
+		common.AssertEqual(t, false, strings.Contains(rec.Body.String(), "signing-secret"))
+		cfg := &ConfigResponse{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), cfg))
+		tc.expected(cfg)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"mime"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	mimeJSON = "application/json"
+	mimeYAML = "application/yaml"
+)
+
+// defaultContentTypes maps each normalizer format to the content type its locations are served with
+var defaultContentTypes = map[types.NormalizerFormat]string{
+	types.CatalogInfoYamlFormat: mimeYAML,
+	types.JsonArrayForamt:       mimeJSON,
+}
+
+// parseContentTypes overlays the format=content type overrides on the default content types, logging an error and
+// skipping overrides for unknown formats or with invalid content types
+func parseContentTypes(overrides map[string]string) map[types.NormalizerFormat]string {
+	contentTypes := map[types.NormalizerFormat]string{}
+	for f, ct := range defaultContentTypes {
+		contentTypes[f] = ct
+	}
+	for f, ct := range overrides {
+		format := types.NormalizerFormat(f)
+		if _, ok := defaultContentTypes[format]; !ok {
+			klog.Errorf("ignoring content type %s for unknown format %s", ct, f)
+			continue
+		}
+		if _, _, err := mime.ParseMediaType(ct); err != nil {
+			klog.Errorf("ignoring invalid content type %s for format %s: %s", ct, f, err.Error())
+			continue
+		}
+		contentTypes[format] = ct
+	}
+	return contentTypes
+}
+
+// contentTypeFor returns the content type locations of format are served with, which are the defaults unless
+// configured otherwise
+func (i *ImportLocationServer) contentTypeFor(format types.NormalizerFormat) string {
+	contentTypes := i.contentTypes
+	if contentTypes == nil {
+		contentTypes = defaultContentTypes
+	}
+	if ct, ok := contentTypes[format]; ok {
+		return ct
+	}
+	return mimeJSON
+}
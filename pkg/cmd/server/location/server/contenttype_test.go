@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+)
+
+func TestParseContentTypes(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		overrides map[string]string
+		expected  map[types.NormalizerFormat]string
+	}{
+		{
+			name:     "defaults",
+			expected: map[types.NormalizerFormat]string{types.CatalogInfoYamlFormat: mimeYAML, types.JsonArrayForamt: mimeJSON},
+		},
+		{
+			name:      "override",
+			overrides: map[string]string{string(types.CatalogInfoYamlFormat): "text/yaml; charset=utf-8"},
+			expected:  map[types.NormalizerFormat]string{types.CatalogInfoYamlFormat: "text/yaml; charset=utf-8", types.JsonArrayForamt: mimeJSON},
+		},
+		{
+			name:      "unknown format and invalid content type ignored",
+			overrides: map[string]string{"TomlFormat": "application/toml", string(types.JsonArrayForamt): "not a content type"},
+			expected:  map[types.NormalizerFormat]string{types.CatalogInfoYamlFormat: mimeYAML, types.JsonArrayForamt: mimeJSON},
+		},
+	} {
+		common.AssertEqual(t, tc.expected, parseContentTypes(tc.overrides))
+	}
+}
+
+func TestHandleCatalogLookupGetContentType(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		format              types.NormalizerFormat
+		formatAutoDetect    bool
+		contentTypes        map[types.NormalizerFormat]string
+		uri                 string
+		fileName            string
+		expectedContentType string
+	}{
+		{
+			name:                "catalog info served as yaml",
+			format:              types.CatalogInfoYamlFormat,
+			uri:                 "/mnist/v1/catalog-info.yaml",
+			fileName:            catalogInfoFileName,
+			expectedContentType: mimeYAML,
+		},
+		{
+			name:                "json array served as json",
+			format:              types.JsonArrayForamt,
+			uri:                 "/mnist/v1/model-catalog.json",
+			fileName:            modelCatalogFileName,
+			expectedContentType: mimeJSON,
+		},
+		{
+			name:                "detected json array served as json",
+			format:              types.CatalogInfoYamlFormat,
+			formatAutoDetect:    true,
+			uri:                 "/mnist/v1/model-catalog.json",
+			fileName:            modelCatalogFileName,
+			expectedContentType: mimeJSON,
+		},
+		{
+			name:                "configured content type",
+			format:              types.CatalogInfoYamlFormat,
+			contentTypes:        parseContentTypes(map[string]string{string(types.CatalogInfoYamlFormat): "text/yaml"}),
+			uri:                 "/mnist/v1/catalog-info.yaml",
+			fileName:            catalogInfoFileName,
+			expectedContentType: "text/yaml",
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:          map[string]*ImportLocation{tc.uri: {content: []byte("content")}},
+			format:           tc.format,
+			formatAutoDetect: tc.formatAutoDetect,
+			contentTypes:     tc.contentTypes,
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{}}
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: tc.fileName}}
+
+		ils.handleCatalogLookupGet(ctx)
+
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedContentType, testWriter.Header().Get("Content-Type"))
+	}
+}
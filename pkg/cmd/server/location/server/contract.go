@@ -0,0 +1,100 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// contractVersion versions the contract document; it is bumped whenever the key grammar, URI structure or PostBody
+// contract the document describes changes, so clients can detect the change
+const contractVersion = "1"
+
+// keySeparator separates the segments of a key
+const keySeparator = "_"
+
+// ContractResponse describes how clients address the location service: the grammar of the keys content is upserted
+// and removed with, the URIs content is served from, the formats it is served in, and the upserted PostBody
+type ContractResponse struct {
+	Version     string            `json:"version"`
+	Key         KeyContract       `json:"key"`
+	URITemplate string            `json:"uriTemplate"`
+	Formats     []SupportedFormat `json:"formats"`
+	PostBody    []ContractField   `json:"postBody"`
+}
+
+// KeyContract is the grammar of keys, whose segments are joined by the separator in order
+type KeyContract struct {
+	Separator string          `json:"separator"`
+	Segments  []KeySegment    `json:"segments"`
+	Example   string          `json:"example"`
+	Legacy    LegacyKeyFormat `json:"legacy"`
+}
+
+type KeySegment struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required"`
+	Pattern     string `json:"pattern,omitempty"`
+	Description string `json:"description"`
+}
+
+// LegacyKeyFormat describes the key format of only the model and version, which is accepted, and deprecated when
+// clients are warned of using it
+type LegacyKeyFormat struct {
+	Segments   []string `json:"segments"`
+	Deprecated bool     `json:"deprecated"`
+}
+
+type ContractField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+}
+
+// postBodyContract describes the fields of rest.PostBody
+var postBodyContract = []ContractField{
+	{Name: "body", Type: "base64", Required: true, Description: "the catalog-info content of the model version, in one of the supported formats"},
+	{Name: "lastUpdateTimeSinceEpoch", Type: "string", Description: "when the model version was last updated, in milliseconds since the epoch"},
+	{Name: "modelCardKey", Type: "string", Description: "the key the model card is held under and served from /modelcard; without it no model card is held"},
+	{Name: "modelCard", Type: "string", Description: "the markdown model card of the model version"},
+	{Name: "attachments", Type: "array", Description: "named assets of the model card, each with a name, an optional contentType and base64 content, served from /modelcard/{key}/assets/{name}"},
+}
+
+// contract returns the contract document for the current configuration
+func (i *ImportLocationServer) contract() *ContractResponse {
+	resp := &ContractResponse{
+		Version: contractVersion,
+		Key: KeyContract{
+			Separator: keySeparator,
+			Segments: []KeySegment{
+				{Name: "model", Required: true, Description: "the name of the model"},
+				{Name: "version", Required: true, Pattern: i.rawVersionPattern, Description: "the version of the model"},
+				{Name: "source", Description: "the source the model version was normalized from, which does not take part in its URI"},
+				{Name: "normalizer", Description: "the normalizer that posted the model version, which does not take part in its URI"},
+			},
+			Example: "mnist" + keySeparator + "v1" + keySeparator + "rhoai" + keySeparator + "kfmr",
+			Legacy:  LegacyKeyFormat{Segments: []string{"model", "version"}, Deprecated: i.legacyKeyWarning},
+		},
+		URITemplate: defaultURITemplate,
+		Formats:     i.describeFormats(),
+		PostBody:    postBodyContract,
+	}
+	if t := i.uriTemplate.Load(); t != nil {
+		resp.URITemplate = t.String()
+	}
+	return resp
+}
+
+// handleContractGet returns the contract document, a machine readable description of the key grammar, URI template,
+// formats and PostBody clients integrate with
+func (i *ImportLocationServer) handleContractGet(c *gin.Context) {
+	content, err := json.Marshal(i.contract())
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
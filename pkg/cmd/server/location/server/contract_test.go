@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleContractGet(t *testing.T) {
+	template, err := parseURITemplate("/models/{model}/versions/{version}/{file}")
+	common.AssertError(t, err)
+	for _, tc := range []struct {
+		name                string
+		ils                 *ImportLocationServer
+		expectedTemplate    string
+		expectedPattern     string
+		expectedFormats     []types.NormalizerFormat
+		expectedDeprecation bool
+	}{
+		{
+			name:             "defaults",
+			ils:              &ImportLocationServer{format: types.CatalogInfoYamlFormat},
+			expectedTemplate: defaultURITemplate,
+			expectedFormats:  []types.NormalizerFormat{types.CatalogInfoYamlFormat},
+		},
+		{
+			name: "configured",
+			ils: &ImportLocationServer{
+				format:            types.JsonArrayForamt,
+				formatAutoDetect:  true,
+				versionPattern:    regexp.MustCompile(`^v[0-9]+$`),
+				rawVersionPattern: "v[0-9]+",
+				legacyKeyWarning:  true,
+			},
+			expectedTemplate:    "/models/{model}/versions/{version}/{file}",
+			expectedPattern:     "v[0-9]+",
+			expectedFormats:     []types.NormalizerFormat{types.JsonArrayForamt, types.CatalogInfoYamlFormat},
+			expectedDeprecation: true,
+		},
+	} {
+		if tc.expectedTemplate != defaultURITemplate {
+			tc.ils.uriTemplate.Store(template)
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+
+		tc.ils.handleContractGet(ctx)
+
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		resp := ContractResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		common.AssertEqual(t, contractVersion, resp.Version)
+		common.AssertEqual(t, "_", resp.Key.Separator)
+		common.AssertEqual(t, 4, len(resp.Key.Segments))
+		common.AssertEqual(t, KeySegment{Name: "version", Required: true, Pattern: tc.expectedPattern, Description: "the version of the model"}, resp.Key.Segments[1])
+		common.AssertEqual(t, LegacyKeyFormat{Segments: []string{"model", "version"}, Deprecated: tc.expectedDeprecation}, resp.Key.Legacy)
+		common.AssertEqual(t, tc.expectedTemplate, resp.URITemplate)
+		formats := []types.NormalizerFormat{}
+		for _, f := range resp.Formats {
+			formats = append(formats, f.Format)
+		}
+		common.AssertEqual(t, tc.expectedFormats, formats)
+		common.AssertEqual(t, postBodyContract, resp.PostBody)
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+const defaultDeadLetterMaxBytes = 10 * 1024 * 1024
+
+// DeadLetter is the record of a rejected upsert
+type DeadLetter struct {
+	Time      time.Time `json:"time"`
+	RequestId string    `json:"requestId,omitempty"`
+	Key       string    `json:"key,omitempty"`
+	Status    int       `json:"status"`
+	Reason    string    `json:"reason"`
+	Payload   string    `json:"payload"`
+}
+
+// deadLetterLog records rejected upserts, one JSON object per line, so the payloads of a misbehaving normalizer can
+// be inspected later.  When backed by a file, the file is rotated to a single backup once writing a record would
+// grow it past maxBytes.
+type deadLetterLog struct {
+	lock     sync.Mutex
+	out      io.Writer
+	path     string
+	file     *os.File
+	maxBytes int64
+	size     int64
+}
+
+func newDeadLetterWriter(out io.Writer) *deadLetterLog {
+	return &deadLetterLog{out: out}
+}
+
+func newDeadLetterFile(path string, maxBytes int64) (*deadLetterLog, error) {
+	d := &deadLetterLog{path: path, maxBytes: maxBytes}
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *deadLetterLog) open() error {
+	f, err := os.OpenFile(d.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("opening dead letter file %s failed: %s", d.path, err.Error())
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("reading dead letter file %s failed: %s", d.path, err.Error())
+	}
+	d.file, d.out, d.size = f, f, info.Size()
+	return nil
+}
+
+// rotate moves the file to its backup, replacing any previous backup, and starts a new file; callers must hold the lock
+func (d *deadLetterLog) rotate() error {
+	d.file.Close()
+	if err := os.Rename(d.path, d.path+".1"); err != nil {
+		klog.Errorf("rotating dead letter file %s failed: %s", d.path, err.Error())
+	}
+	return d.open()
+}
+
+// record writes the dead letter; a nil log discards it
+func (d *deadLetterLog) record(dl DeadLetter) {
+	if d == nil {
+		return
+	}
+	buf, err := json.Marshal(dl)
+	if err != nil {
+		klog.Errorf("encoding dead letter for key %s failed: %s", dl.Key, err.Error())
+		return
+	}
+	buf = append(buf, '\n')
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.file != nil && d.maxBytes > 0 && d.size > 0 && d.size+int64(len(buf)) > d.maxBytes {
+		if err = d.rotate(); err != nil {
+			klog.Error(err.Error())
+			return
+		}
+	}
+	n, err := d.out.Write(buf)
+	d.size += int64(n)
+	if err != nil {
+		klog.Errorf("writing dead letter for key %s failed: %s", dl.Key, err.Error())
+	}
+}
+
+func (d *deadLetterLog) close() {
+	if d == nil || d.file == nil {
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.file.Close()
+}
+
+// captureRejection buffers the body of the request so that, should the handler reject it with a client error, the
+// returned func records it to the dead letter log along with the reason.  Failures to upsert that are not the
+// fault of the payload, such as a reload being in progress, are not recorded.
+func (d *deadLetterLog) captureRejection(c *gin.Context) func() {
+	if d == nil || c.Request == nil || c.Request.Body == nil {
+		return func() {}
+	}
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		klog.Errorf("buffering upsert body for the dead letter log failed: %s", err.Error())
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(payload))
+	return func() {
+		sc := c.Writer.Status()
+		if sc < http.StatusBadRequest || sc >= http.StatusInternalServerError {
+			return
+		}
+		reason := http.StatusText(sc)
+		if e := c.Errors.Last(); e != nil {
+			reason = e.Error()
+		}
+		d.record(DeadLetter{
+			Time:      time.Now(),
+			RequestId: c.GetString("requestId"),
+			Key:       c.Query("key"),
+			Status:    sc,
+			Reason:    reason,
+			Payload:   string(payload),
+		})
+	}
+}
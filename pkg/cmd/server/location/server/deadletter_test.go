@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleCatalogUpsertPostDeadLetters(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		reqURL         url.URL
+		body           string
+		expectedSC     int
+		expectedReason string
+	}{
+		{
+			name:       "accepted upsert not recorded",
+			reqURL:     url.URL{RawQuery: "key=mnist_v1"},
+			body:       `{"body":"Y3JlYXRl"}`,
+			expectedSC: http.StatusCreated,
+		},
+		{
+			name:           "missing key",
+			body:           `{"body":"Y3JlYXRl"}`,
+			expectedSC:     http.StatusBadRequest,
+			expectedReason: "need a 'key' parameter",
+		},
+		{
+			name:           "bad key",
+			reqURL:         url.URL{RawQuery: "key=mnist"},
+			body:           `{"body":"Y3JlYXRl"}`,
+			expectedSC:     http.StatusBadRequest,
+			expectedReason: "bad key format: mnist",
+		},
+		{
+			name:           "bad body",
+			reqURL:         url.URL{RawQuery: "key=mnist_v1"},
+			body:           `{"body":`,
+			expectedSC:     http.StatusBadRequest,
+			expectedReason: "unexpected EOF",
+		},
+		{
+			name:           "entity conflict",
+			reqURL:         url.URL{RawQuery: "key=mnist_v2"},
+			body:           `{"body":"a2luZDogQ29tcG9uZW50Cm1ldGFkYXRhOgogIG5hbWU6IG1uaXN0Cg=="}`,
+			expectedSC:     http.StatusConflict,
+			expectedReason: "entity component:default/mnist is already provided by /mnist/v1/catalog-info.yaml",
+		},
+	} {
+		out := &bytes.Buffer{}
+		ils := &ImportLocationServer{
+			content:          map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity)}},
+			modelcards:       map[string]modelCardMetadata{},
+			entityRefs:       map[string]string{"component:default/mnist": "/mnist/v1/catalog-info.yaml"},
+			entityUniqueness: true,
+			deadLetters:      newDeadLetterWriter(out),
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &tc.reqURL, Body: io.NopCloser(strings.NewReader(tc.body))}
+		ctx.Set("requestId", "req-1")
+
+		ils.handleCatalogUpsertPost(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if len(tc.expectedReason) == 0 {
+			common.AssertEqual(t, "", out.String())
+			continue
+		}
+		dl := DeadLetter{}
+		common.AssertError(t, json.Unmarshal(out.Bytes(), &dl))
+		common.AssertEqual(t, "req-1", dl.RequestId)
+		common.AssertEqual(t, tc.reqURL.Query().Get("key"), dl.Key)
+		common.AssertEqual(t, tc.expectedSC, dl.Status)
+		common.AssertEqual(t, true, strings.Contains(dl.Reason, tc.expectedReason))
+		common.AssertEqual(t, tc.body, dl.Payload)
+	}
+}
+
+func TestDeadLetterFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deadletters.jsonl")
+	d, err := newDeadLetterFile(path, 200)
+	common.AssertError(t, err)
+	defer d.close()
+
+	for _, key := range []string{"mnist_v1", "mnist_v2", "mnist_v3"} {
+		d.record(DeadLetter{Key: key, Status: http.StatusBadRequest, Reason: "bad", Payload: strings.Repeat("x", 50)})
+	}
+
+	keys := func(fn string) []string {
+		f, err := os.Open(fn)
+		common.AssertError(t, err)
+		defer f.Close()
+		found := []string{}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			dl := DeadLetter{}
+			common.AssertError(t, json.Unmarshal(scanner.Bytes(), &dl))
+			found = append(found, dl.Key)
+		}
+		return found
+	}
+	common.AssertEqual(t, []string{"mnist_v3"}, keys(path))
+	common.AssertEqual(t, []string{"mnist_v2"}, keys(path+".1"))
+}
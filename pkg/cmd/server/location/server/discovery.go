@@ -0,0 +1,310 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+const (
+	uriField                      = "uri"
+	sourceField                   = "source"
+	sizeField                     = "size"
+	checksumField                 = "checksum"
+	modelCardKeyField             = "modelCardKey"
+	lastUpdateTimeSinceEpochField = "lastUpdateTimeSinceEpoch"
+	updatedField                  = "updated"
+	schemaVersionField            = "schemaVersion"
+)
+
+// detailFields are the fields of a DiscoveryLocation, in the order they are documented
+var detailFields = []string{uriField, sourceField, sizeField, checksumField, modelCardKeyField, lastUpdateTimeSinceEpochField, updatedField, schemaVersionField}
+
+// DiscoveryLocation details a served URI; fields not selected with the fields query parameter are omitted
+type DiscoveryLocation struct {
+	URI                      string `json:"uri,omitempty"`
+	Source                   string `json:"source,omitempty"`
+	Size                     *int   `json:"size,omitempty"`
+	Checksum                 string `json:"checksum,omitempty"`
+	ModelCardKey             string `json:"modelCardKey,omitempty"`
+	LastUpdateTimeSinceEpoch string `json:"lastUpdateTimeSinceEpoch,omitempty"`
+	Updated                  string `json:"updated,omitempty"`
+	SchemaVersion            string `json:"schemaVersion,omitempty"`
+}
+
+type DetailedDiscoveryResponse struct {
+	Locations []DiscoveryLocation `json:"locations"`
+}
+
+// parseFields returns the set of detail fields selected by the comma separated fields query parameter, which
+// defaults to all of them
+func parseFields(c *gin.Context) (map[string]bool, error) {
+	selected := map[string]bool{}
+	raw := strings.TrimSpace(c.Query(util.FieldsQueryParam))
+	if len(raw) == 0 {
+		for _, f := range detailFields {
+			selected[f] = true
+		}
+		return selected, nil
+	}
+	known := map[string]bool{}
+	for _, f := range detailFields {
+		known[f] = true
+	}
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if !known[f] {
+			return nil, fmt.Errorf("unknown field %q, the fields are %s", f, strings.Join(detailFields, ","))
+		}
+		selected[f] = true
+	}
+	return selected, nil
+}
+
+// discoveryShape is the JSON shape the discovery endpoint lists URIs in, for the different Backstage integrations
+type discoveryShape string
+
+const (
+	// discoveryShapeUris is {"uris":[...]}, the shape the bridge's entity provider reads
+	discoveryShapeUris discoveryShape = "uris"
+	// discoveryShapeArray is a bare array of URIs
+	discoveryShapeArray discoveryShape = "array"
+	// discoveryShapeTargets is an array of {"type":"url","target":...} location specs
+	discoveryShapeTargets discoveryShape = "targets"
+	// discoveryShapeLocation is a Backstage Location entity whose targets are the URIs
+	discoveryShapeLocation discoveryShape = "location"
+
+	discoveryLocationName = "model-catalog-bridge"
+)
+
+func parseDiscoveryShape(str string) (discoveryShape, error) {
+	switch s := discoveryShape(strings.ToLower(strings.TrimSpace(str))); s {
+	case discoveryShapeUris, discoveryShapeArray, discoveryShapeTargets, discoveryShapeLocation:
+		return s, nil
+	case "":
+		return discoveryShapeUris, nil
+	default:
+		return discoveryShapeUris, fmt.Errorf("unknown discovery shape %q, the shapes are %s, %s, %s and %s", str, discoveryShapeUris, discoveryShapeArray, discoveryShapeTargets, discoveryShapeLocation)
+	}
+}
+
+type LocationTarget struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+type LocationEntity struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   LocationMetadata   `json:"metadata"`
+	Spec       LocationEntitySpec `json:"spec"`
+}
+
+type LocationMetadata struct {
+	Name string `json:"name"`
+}
+
+type LocationEntitySpec struct {
+	Type    string   `json:"type"`
+	Targets []string `json:"targets"`
+}
+
+// shapeDiscovery marshals the URIs in the requested shape, defaulting to the configured one
+func (i *ImportLocationServer) shapeDiscovery(c *gin.Context, uris []string) ([]byte, error) {
+	shape := i.discoveryShape
+	if raw := c.Query(util.ShapeQueryParam); len(raw) > 0 {
+		var err error
+		shape, err = parseDiscoveryShape(raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if uris == nil {
+		uris = []string{}
+	}
+	switch shape {
+	case discoveryShapeArray:
+		sort.Strings(uris)
+		return json.Marshal(uris)
+	case discoveryShapeTargets:
+		sort.Strings(uris)
+		targets := make([]LocationTarget, 0, len(uris))
+		for _, uri := range uris {
+			targets = append(targets, LocationTarget{Type: "url", Target: uri})
+		}
+		return json.Marshal(targets)
+	case discoveryShapeLocation:
+		sort.Strings(uris)
+		return json.Marshal(&LocationEntity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       "Location",
+			Metadata:   LocationMetadata{Name: discoveryLocationName},
+			Spec:       LocationEntitySpec{Type: "url", Targets: uris},
+		})
+	}
+	return nil, nil
+}
+
+// streamFlushInterval is the number of URIs streamed between flushes, each flush sending a chunk to the client
+const streamFlushInterval = 500
+
+// streamDiscovery writes the sorted URIs in the uris or array shape one at a time, flushing them to the client in
+// chunks, so the encoded response is not buffered whole; uris, collected by the caller, are sorted here first, so
+// memory still grows with the number of URIs.  The targets and location shapes are not streamed
+func (i *ImportLocationServer) streamDiscovery(c *gin.Context, uris []string) {
+	shape := i.discoveryShape
+	if raw := c.Query(util.ShapeQueryParam); len(raw) > 0 {
+		var err error
+		shape, err = parseDiscoveryShape(raw)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			c.Error(err)
+			return
+		}
+	}
+	open, closing := `{"uris":[`, "]}"
+	switch shape {
+	case discoveryShapeArray:
+		open, closing = "[", "]"
+	case discoveryShapeTargets, discoveryShapeLocation:
+		c.Status(http.StatusBadRequest)
+		c.Error(fmt.Errorf("the %s shape cannot be streamed, only the %s and %s shapes can", shape, discoveryShapeUris, discoveryShapeArray))
+		return
+	}
+	sort.Strings(uris)
+	c.Header("Content-Type", "application/json")
+	c.Status(http.StatusOK)
+	c.Writer.WriteString(open)
+	for idx, uri := range uris {
+		if idx > 0 {
+			c.Writer.WriteString(",")
+		}
+		// a string always encodes
+		encoded, _ := json.Marshal(uri)
+		if _, err := c.Writer.Write(encoded); err != nil {
+			klog.V(4).Infof("streaming discovery stopped as the client went away: %s", err.Error())
+			return
+		}
+		if (idx+1)%streamFlushInterval == 0 {
+			c.Writer.Flush()
+		}
+	}
+	c.Writer.WriteString(closing)
+	c.Writer.Flush()
+}
+
+// window bounds discovery to the locations updated at or after since and before until; a zero bound is open
+type window struct {
+	since time.Time
+	until time.Time
+}
+
+func parseWindow(c *gin.Context) (window, error) {
+	w := window{}
+	var err error
+	for _, b := range []struct {
+		param string
+		t     *time.Time
+	}{{util.SinceQueryParam, &w.since}, {util.UntilQueryParam, &w.until}} {
+		raw := c.Query(b.param)
+		if len(raw) == 0 {
+			continue
+		}
+		*b.t, err = time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return w, fmt.Errorf("the %s query parameter must be an RFC 3339 time: %s", b.param, err.Error())
+		}
+	}
+	return w, nil
+}
+
+func (w window) open() bool {
+	return w.since.IsZero() && w.until.IsZero()
+}
+
+// includes reports whether the location at uri was updated within the window; callers must hold the server lock
+func (i *ImportLocationServer) includes(w window, uri string) bool {
+	if w.open() {
+		return true
+	}
+	updated, ok := i.updated[uri]
+	if !ok {
+		return false
+	}
+	return (w.since.IsZero() || !updated.Before(w.since)) && (w.until.IsZero() || updated.Before(w.until))
+}
+
+// touch records the content of the location at uri changed; callers must hold the server lock
+func (i *ImportLocationServer) touch(uri string) {
+	if i.updated == nil {
+		i.updated = map[string]time.Time{}
+	}
+	i.updated[uri] = time.Now()
+}
+
+// discoverDetailed responds with the details of each served URI; callers must hold the server lock for reading,
+// which it releases before writing the response
+func (i *ImportLocationServer) discoverDetailed(c *gin.Context, source string, w window) {
+	fields, err := parseFields(c)
+	if err != nil {
+		i.lock.RUnlock()
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	d := &DetailedDiscoveryResponse{Locations: []DiscoveryLocation{}}
+	uris := []string{}
+	for uri, il := range i.content {
+		if il.content != nil && (len(source) == 0 || il.source == source) && i.includes(w, uri) {
+			uris = append(uris, uri)
+		}
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		il := i.content[uri]
+		l := DiscoveryLocation{}
+		if fields[uriField] {
+			l.URI = uri
+		}
+		if fields[sourceField] {
+			l.Source = il.source
+		}
+		if fields[sizeField] {
+			size := len(i.plaintext(il.content))
+			l.Size = &size
+		}
+		if fields[checksumField] {
+			l.Checksum = i.checksum(i.plaintext(il.content))
+		}
+		if fields[modelCardKeyField] {
+			l.ModelCardKey = il.modelCardKey
+		}
+		if fields[lastUpdateTimeSinceEpochField] {
+			if mcm, ok := i.modelcards[il.modelCardKey]; ok && len(il.modelCardKey) > 0 {
+				l.LastUpdateTimeSinceEpoch = mcm.lastUpdateTimeSinceEpoch
+			}
+		}
+		if updated, ok := i.updated[uri]; ok && fields[updatedField] {
+			l.Updated = updated.UTC().Format(time.RFC3339Nano)
+		}
+		if fields[schemaVersionField] {
+			l.SchemaVersion = il.schemaVersion
+		}
+		d.Locations = append(d.Locations, l)
+	}
+	i.lock.RUnlock()
+	content, err := json.Marshal(d)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleCatalogDiscoveryGetDetailed(t *testing.T) {
+	size := func(n int) *int { return &n }
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":   {content: []byte("mnist"), source: "kserve", modelCardKey: "mnist_v1"},
+			"/granite/v1/catalog-info.yaml": {content: []byte("granite"), source: "kubeflow"},
+			"/removed/v1/catalog-info.yaml": {},
+		},
+		modelcards: map[string]modelCardMetadata{"mnist_v1": {lastUpdateTimeSinceEpoch: "1700000000"}},
+	}
+	for _, tc := range []struct {
+		name           string
+		rawQuery       string
+		expectedSC     int
+		expectedErrMsg string
+		expected       []DiscoveryLocation
+	}{
+		{
+			name:       "all fields by default",
+			rawQuery:   "detailed=true",
+			expectedSC: http.StatusOK,
+			expected: []DiscoveryLocation{
+				{URI: "/granite/v1/catalog-info.yaml", Source: "kubeflow", Size: size(7), Checksum: hashSHA256.sum([]byte("granite"))},
+				{URI: "/mnist/v1/catalog-info.yaml", Source: "kserve", Size: size(5), Checksum: hashSHA256.sum([]byte("mnist")), ModelCardKey: "mnist_v1", LastUpdateTimeSinceEpoch: "1700000000"},
+			},
+		},
+		{
+			name:       "selected subset",
+			rawQuery:   "detailed=true&fields=uri,size",
+			expectedSC: http.StatusOK,
+			expected: []DiscoveryLocation{
+				{URI: "/granite/v1/catalog-info.yaml", Size: size(7)},
+				{URI: "/mnist/v1/catalog-info.yaml", Size: size(5)},
+			},
+		},
+		{
+			name:           "invalid field",
+			rawQuery:       "detailed=true&fields=uri,owner",
+			expectedSC:     http.StatusBadRequest,
+			expectedErrMsg: `unknown field "owner"`,
+		},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: tc.rawQuery}}
+
+		ils.handleCatalogDiscoveryGet(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if tc.expectedSC != http.StatusOK {
+			common.AssertEqual(t, 1, len(ctx.Errors))
+			common.AssertEqual(t, true, strings.Contains(ctx.Errors[0].Error(), tc.expectedErrMsg))
+			continue
+		}
+		d := &DetailedDiscoveryResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), d))
+		common.AssertEqual(t, tc.expected, d.Locations)
+	}
+
+	// the selected fields alone are in the response
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "detailed=true&fields=size"}}
+	ils.handleCatalogDiscoveryGet(ctx)
+	common.AssertEqual(t, `{"locations":[{"size":7},{"size":5}]}`, testWriter.ResponseWriter.Body.String())
+}
+
+func TestHandleCatalogDiscoveryGetWindow(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":   {content: []byte("mnist v1")},
+			"/mnist/v2/catalog-info.yaml":   {content: []byte("mnist v2")},
+			"/granite/v1/catalog-info.yaml": {content: []byte("granite")},
+			"/removed/v1/catalog-info.yaml": {},
+		},
+		updated: map[string]time.Time{
+			"/mnist/v1/catalog-info.yaml":   base,
+			"/mnist/v2/catalog-info.yaml":   base.Add(time.Hour),
+			"/granite/v1/catalog-info.yaml": base.Add(2 * time.Hour),
+			"/removed/v1/catalog-info.yaml": base.Add(time.Hour),
+		},
+	}
+	for _, tc := range []struct {
+		name         string
+		rawQuery     string
+		expectedSC   int
+		expectedUris []string
+	}{
+		{
+			name:         "no window",
+			rawQuery:     "",
+			expectedSC:   http.StatusOK,
+			expectedUris: []string{"/granite/v1/catalog-info.yaml", "/mnist/v1/catalog-info.yaml", "/mnist/v2/catalog-info.yaml"},
+		},
+		{
+			name:         "since",
+			rawQuery:     "since=2024-05-01T13:00:00Z",
+			expectedSC:   http.StatusOK,
+			expectedUris: []string{"/granite/v1/catalog-info.yaml", "/mnist/v2/catalog-info.yaml"},
+		},
+		{
+			name:         "since and until",
+			rawQuery:     "since=2024-05-01T12:30:00Z&until=2024-05-01T14:00:00Z",
+			expectedSC:   http.StatusOK,
+			expectedUris: []string{"/mnist/v2/catalog-info.yaml"},
+		},
+		{
+			name:         "until",
+			rawQuery:     "until=2024-05-01T13:00:00Z",
+			expectedSC:   http.StatusOK,
+			expectedUris: []string{"/mnist/v1/catalog-info.yaml"},
+		},
+		{
+			name:       "bad time",
+			rawQuery:   "since=yesterday",
+			expectedSC: http.StatusBadRequest,
+		},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: tc.rawQuery}}
+
+		ils.handleCatalogDiscoveryGet(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if tc.expectedSC != http.StatusOK {
+			continue
+		}
+		d := &DicoveryResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), d))
+		sort.Strings(d.Uris)
+		common.AssertEqual(t, tc.expectedUris, d.Uris)
+	}
+
+	// detailed responses within a window carry the update times
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "detailed=true&fields=uri,updated&since=2024-05-01T13:00:00Z"}}
+	ils.handleCatalogDiscoveryGet(ctx)
+	d := &DetailedDiscoveryResponse{}
+	common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), d))
+	common.AssertEqual(t, []DiscoveryLocation{
+		{URI: "/granite/v1/catalog-info.yaml", Updated: "2024-05-01T14:00:00Z"},
+		{URI: "/mnist/v2/catalog-info.yaml", Updated: "2024-05-01T13:00:00Z"},
+	}, d.Locations)
+}
+
+func TestUpsertTouchesUpdated(t *testing.T) {
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}}
+	upsertForTest(t, ils, "mnist_v1", "create")
+	first := ils.updated["/mnist/v1/catalog-info.yaml"]
+	common.AssertEqual(t, false, first.IsZero())
+	time.Sleep(10 * time.Millisecond)
+	// unchanged content keeps its update time
+	upsertForTest(t, ils, "mnist_v1", "create")
+	common.AssertEqual(t, first, ils.updated["/mnist/v1/catalog-info.yaml"])
+	upsertForTest(t, ils, "mnist_v1", "update")
+	common.AssertEqual(t, true, ils.updated["/mnist/v1/catalog-info.yaml"].After(first))
+}
+
+func TestHandleCatalogDiscoveryGetShapes(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		shape      discoveryShape
+		rawQuery   string
+		singleURI  bool
+		expectedSC int
+		expected   string
+	}{
+		{
+			name:       "default",
+			singleURI:  true,
+			expectedSC: http.StatusOK,
+			expected:   `{"uris":["/mnist/v1/catalog-info.yaml"]}`,
+		},
+		{
+			name:       "array",
+			rawQuery:   "shape=array",
+			expectedSC: http.StatusOK,
+			expected:   `["/granite/v1/catalog-info.yaml","/mnist/v1/catalog-info.yaml"]`,
+		},
+		{
+			name:       "targets",
+			rawQuery:   "shape=targets",
+			expectedSC: http.StatusOK,
+			expected:   `[{"type":"url","target":"/granite/v1/catalog-info.yaml"},{"type":"url","target":"/mnist/v1/catalog-info.yaml"}]`,
+		},
+		{
+			name:       "location entity",
+			rawQuery:   "shape=location",
+			expectedSC: http.StatusOK,
+			expected:   `{"apiVersion":"backstage.io/v1alpha1","kind":"Location","metadata":{"name":"model-catalog-bridge"},"spec":{"type":"url","targets":["/granite/v1/catalog-info.yaml","/mnist/v1/catalog-info.yaml"]}}`,
+		},
+		{
+			name:       "configured shape",
+			shape:      discoveryShapeArray,
+			expectedSC: http.StatusOK,
+			expected:   `["/granite/v1/catalog-info.yaml","/mnist/v1/catalog-info.yaml"]`,
+		},
+		{
+			name:       "query overrides configured shape",
+			shape:      discoveryShapeArray,
+			rawQuery:   "shape=uris",
+			singleURI:  true,
+			expectedSC: http.StatusOK,
+			expected:   `{"uris":["/mnist/v1/catalog-info.yaml"]}`,
+		},
+		{
+			name:       "unknown shape",
+			rawQuery:   "shape=csv",
+			expectedSC: http.StatusBadRequest,
+		},
+	} {
+		content := map[string]*ImportLocation{
+			"/granite/v1/catalog-info.yaml": {content: []byte("granite")},
+			"/mnist/v1/catalog-info.yaml":   {content: []byte("mnist")},
+		}
+		if tc.singleURI {
+			// the default shape keeps the map's order, so list a single URI
+			delete(content, "/granite/v1/catalog-info.yaml")
+		}
+		ils := &ImportLocationServer{content: content, discoveryShape: tc.shape}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: tc.rawQuery}}
+
+		ils.handleCatalogDiscoveryGet(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if tc.expectedSC == http.StatusOK {
+			common.AssertEqual(t, tc.expected, testWriter.ResponseWriter.Body.String())
+		}
+	}
+}
+
+func TestHandleCatalogDiscoveryGetStream(t *testing.T) {
+	ils, r := newBenchmarkServer(true)
+	expected := []string{}
+	for n := range 5000 {
+		uri := fmt.Sprintf("/model-%04d/v1/catalog-info.yaml", n)
+		ils.content[uri] = &ImportLocation{content: []byte("kind: Component\n")}
+		expected = append(expected, uri)
+	}
+	ils.content[`/quoted"model/v1/catalog-info.yaml`] = &ImportLocation{content: []byte("kind: Component\n")}
+	ils.content["/removed/v1/catalog-info.yaml"] = &ImportLocation{}
+	expected = append(expected, "/granite/v1/catalog-info.yaml", "/mnist/v1/catalog-info.yaml", `/quoted"model/v1/catalog-info.yaml`)
+	sort.Strings(expected)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ListURI+"?stream=true", nil))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, true, rec.Flushed)
+	d := &DicoveryResponse{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), d))
+	common.AssertEqual(t, expected, d.Uris)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ListURI+"?stream=true&shape=array", nil))
+	uris := []string{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &uris))
+	common.AssertEqual(t, expected, uris)
+
+	// an empty catalog streams an empty list
+	ils.content = map[string]*ImportLocation{}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ListURI+"?stream=true", nil))
+	common.AssertEqual(t, `{"uris":[]}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ListURI+"?stream=true&shape=location", nil))
+	common.AssertEqual(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+// DriftResponse lists, by storage key, how the served catalog differs from storage: keys served but not in storage,
+// keys in storage but not served, and keys whose served content differs from that in storage.  Keys that could not
+// be fetched from storage, or are quarantined, are listed as unfetched and left out of the comparison.
+type DriftResponse struct {
+	OnlyInMemory  []string `json:"onlyInMemory"`
+	OnlyInStorage []string `json:"onlyInStorage"`
+	Differing     []string `json:"differing"`
+	Unfetched     []string `json:"unfetched,omitempty"`
+}
+
+// uriKey returns the storage key of the location at uri, as recovered from the URI under the URI template
+func (i *ImportLocationServer) uriKey(uri string) (string, bool) {
+	t := i.uriTemplate.Load()
+	if t == nil {
+		t, _ = parseURITemplate(defaultURITemplate)
+	}
+	model, version, _, ok := t.parse(uri)
+	if !ok {
+		return "", false
+	}
+	key, _ := i.buildKeyAndURI(model, version, i.format)
+	return key, true
+}
+
+// handleDriftGet compares the checksums of the served content with those of a fresh listing and fetch of storage,
+// to diagnose reconcile problems
+func (i *ImportLocationServer) handleDriftGet(c *gin.Context) {
+	if i.readThrough != nil {
+		c.Status(http.StatusNotImplemented)
+		c.Error(fmt.Errorf("nothing is held in memory to drift from storage in read-through mode"))
+		return
+	}
+	keys, ok := i.listStorageKeys()
+	if !ok {
+		c.Status(http.StatusServiceUnavailable)
+		c.Error(fmt.Errorf("listing the keys in storage failed"))
+		return
+	}
+	lock := sync.Mutex{}
+	stored := map[string]string{}
+	unfetched := map[string]bool{}
+	i.fetchKeys(keys, func(uri string, sb *types.StorageBody) {
+		key, ok := i.uriKey(uri)
+		if !ok {
+			klog.Errorf("URI %s of fetched content does not match the URI template", uri)
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		stored[key] = i.checksum(sb.Body)
+	}, func(uris []string) {
+		if key, ok := i.uriKey(uris[0]); ok {
+			lock.Lock()
+			defer lock.Unlock()
+			unfetched[key] = true
+		}
+	})
+
+	served := map[string]string{}
+	i.lock.RLock()
+	for uri, il := range i.content {
+		if il.content == nil {
+			continue
+		}
+		if key, ok := i.uriKey(uri); ok {
+			served[key] = i.checksum(i.plaintext(il.content))
+		}
+	}
+	i.lock.RUnlock()
+
+	d := &DriftResponse{OnlyInMemory: []string{}, OnlyInStorage: []string{}, Differing: []string{}}
+	for key, sum := range served {
+		storedSum, ok := stored[key]
+		switch {
+		case unfetched[key]:
+		case !ok:
+			d.OnlyInMemory = append(d.OnlyInMemory, key)
+		case storedSum != sum:
+			d.Differing = append(d.Differing, key)
+		}
+	}
+	for key := range stored {
+		if _, ok := served[key]; !ok {
+			d.OnlyInStorage = append(d.OnlyInStorage, key)
+		}
+	}
+	for key := range unfetched {
+		d.Unfetched = append(d.Unfetched, key)
+	}
+	for _, keys := range [][]string{d.OnlyInMemory, d.OnlyInStorage, d.Differing, d.Unfetched} {
+		sort.Strings(keys)
+	}
+	if len(d.OnlyInMemory)+len(d.OnlyInStorage)+len(d.Differing) > 0 {
+		klog.Infof("drift from storage: only in memory %s, only in storage %s, differing %s", strings.Join(d.OnlyInMemory, ","),
+			strings.Join(d.OnlyInStorage, ","), strings.Join(d.Differing, ","))
+	}
+	content, err := json.Marshal(d)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleDriftGet(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{
+		"mnist_v1":   []byte("mnist v1"),
+		"fraud_v1":   []byte("fraud v1 updated in storage"),
+		"granite_v3": []byte("granite v3"),
+		"llama_v2":   []byte("llama v2"),
+	})
+	st.FailKey("llama_v2", true)
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":   {content: []byte("mnist v1")},
+			"/fraud/v1/catalog-info.yaml":   {content: []byte("fraud v1")},
+			"/iris/v1/catalog-info.yaml":    {content: []byte("iris v1")},
+			"/llama/v2/catalog-info.yaml":   {content: []byte("llama v2 in memory")},
+			"/removed/v1/catalog-info.yaml": {},
+		},
+		storage:    st,
+		format:     types.CatalogInfoYamlFormat,
+		adminToken: "admin-secret",
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.GET(util.DriftURI, adminAuth(ils.adminToken), ils.handleDriftGet)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.DriftURI, nil))
+	common.AssertEqual(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, util.DriftURI, nil)
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	d := DriftResponse{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &d))
+	common.AssertEqual(t, []string{"iris_v1"}, d.OnlyInMemory)
+	common.AssertEqual(t, []string{"granite_v3"}, d.OnlyInStorage)
+	common.AssertEqual(t, []string{"fraud_v1"}, d.Differing)
+	// the failed fetch is not reported as drift
+	common.AssertEqual(t, []string{"llama_v2"}, d.Unfetched)
+
+	st.ListErr = fmt.Errorf("storage is down")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	common.AssertEqual(t, http.StatusServiceUnavailable, rec.Code)
+}
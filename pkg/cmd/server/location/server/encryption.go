@@ -0,0 +1,78 @@
+package server
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"k8s.io/klog/v2"
+)
+
+// contentCipher encrypts the content of locations held in memory with AES-GCM, so that a memory dump does not
+// expose it in cleartext; content is decrypted only as it is served or compared.  Each sealing uses a random
+// nonce, which is prepended to the ciphertext.
+type contentCipher struct {
+	aead cipher.AEAD
+}
+
+// newContentCipher creates the cipher from a base64 encoded AES key of 16, 24 or 32 bytes
+func newContentCipher(encodedKey string) (*contentCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("content encryption key is not valid base64: %s", err.Error())
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("content encryption key is not a valid AES key: %s", err.Error())
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &contentCipher{aead: aead}, nil
+}
+
+// seal encrypts content; a nil cipher, or nil content, leaves it as is
+func (cc *contentCipher) seal(content []byte) []byte {
+	if cc == nil || content == nil {
+		return content
+	}
+	nonce := make([]byte, cc.aead.NonceSize(), cc.aead.NonceSize()+len(content)+cc.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		// the crypto/rand reader does not fail on supported platforms
+		panic(err)
+	}
+	return cc.aead.Seal(nonce, nonce, content, nil)
+}
+
+// open decrypts sealed content; a nil cipher, or nil content, leaves it as is
+func (cc *contentCipher) open(sealed []byte) ([]byte, error) {
+	if cc == nil || sealed == nil {
+		return sealed, nil
+	}
+	if len(sealed) < cc.aead.NonceSize() {
+		return nil, fmt.Errorf("sealed content is shorter than a nonce")
+	}
+	nonce, ciphertext := sealed[:cc.aead.NonceSize()], sealed[cc.aead.NonceSize():]
+	content, err := cc.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	// content that was empty, but not nil, stays so
+	if content == nil {
+		content = []byte{}
+	}
+	return content, nil
+}
+
+// plaintext returns the decrypted content of a location, or nil, logging an error, if it cannot be decrypted
+func (i *ImportLocationServer) plaintext(sealed []byte) []byte {
+	content, err := i.cipher.open(sealed)
+	if err != nil {
+		klog.Errorf("decrypting content failed: %s", err.Error())
+		return nil
+	}
+	return content
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+)
+
+var testEncryptionKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+
+func TestNewContentCipher(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		key       string
+		expectErr bool
+	}{
+		{
+			name: "aes-128 key",
+			key:  base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 16)),
+		},
+		{
+			name: "aes-256 key",
+			key:  testEncryptionKey,
+		},
+		{
+			name:      "not base64",
+			key:       "not a key!",
+			expectErr: true,
+		},
+		{
+			name:      "wrong key size",
+			key:       base64.StdEncoding.EncodeToString([]byte("short")),
+			expectErr: true,
+		},
+	} {
+		_, err := newContentCipher(tc.key)
+		common.AssertEqual(t, tc.expectErr, err != nil)
+	}
+}
+
+func TestContentCipherSealOpen(t *testing.T) {
+	cc, err := newContentCipher(testEncryptionKey)
+	common.AssertError(t, err)
+	for _, content := range [][]byte{nil, {}, []byte("apiVersion: backstage.io/v1alpha1")} {
+		sealed := cc.seal(content)
+		if len(content) > 0 {
+			common.AssertEqual(t, false, bytes.Contains(sealed, content))
+		}
+		opened, err := cc.open(sealed)
+		common.AssertError(t, err)
+		common.AssertEqual(t, content, opened)
+	}
+
+	// each sealing uses a new nonce
+	common.AssertEqual(t, false, bytes.Equal(cc.seal([]byte("same")), cc.seal([]byte("same"))))
+
+	// tampered content fails to open
+	sealed := cc.seal([]byte("content"))
+	sealed[len(sealed)-1] ^= 1
+	_, err = cc.open(sealed)
+	common.AssertEqual(t, true, err != nil)
+
+	// a nil cipher leaves content as is
+	var none *contentCipher
+	common.AssertEqual(t, []byte("content"), none.seal([]byte("content")))
+}
+
+func TestEncryptedUpsertLookupRoundTrip(t *testing.T) {
+	cc, err := newContentCipher(testEncryptionKey)
+	common.AssertError(t, err)
+	for _, tc := range []struct {
+		name       string
+		body       []byte
+		transforms bool
+	}{
+		{
+			name: "plain content",
+			body: []byte("apiVersion: backstage.io/v1alpha1\nkind: Component\n"),
+		},
+		{
+			name:       "transformed content",
+			body:       []byte("apiVersion: backstage.io/v1alpha1\nkind: Component\n"),
+			transforms: true,
+		},
+	} {
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, cipher: cc}
+		expected := tc.body
+		if tc.transforms {
+			ils.AddServeTransformer(ServeTransformerFunc(func(uri string, content []byte) ([]byte, error) {
+				return append([]byte("# served\n"), content...), nil
+			}))
+			expected = append([]byte("# served\n"), tc.body...)
+		}
+
+		data, err := json.Marshal(rest.PostBody{Body: tc.body})
+		common.AssertError(t, err)
+		ctx, eng := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.router = eng
+		ils.handleCatalogUpsertPost(ctx)
+		common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+
+		// content is held in memory encrypted
+		il := ils.content["/mnist/v1/catalog-info.yaml"]
+		common.AssertEqual(t, false, bytes.Contains(il.content, tc.body))
+
+		for range 2 {
+			testWriter := testgin.NewTestResponseWriter()
+			ctx, _ = gin.CreateTestContext(testWriter)
+			ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: "catalog-info.yaml"}}
+			ils.handleCatalogLookupGet(ctx)
+			common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+			common.AssertEqual(t, string(expected), testWriter.ResponseWriter.Body.String())
+		}
+		if tc.transforms {
+			// the cached transformed copy is held encrypted as well
+			common.AssertEqual(t, 1, len(il.served.entries))
+			common.AssertEqual(t, false, bytes.Contains(il.served.entries[0].content, expected))
+		}
+	}
+}
+
+// BenchmarkServeContent measures the cost of decrypting content as it is served, against serving it in cleartext
+func BenchmarkServeContent(b *testing.B) {
+	content := bytes.Repeat([]byte("apiVersion: backstage.io/v1alpha1\n"), 256)
+	for _, bc := range []struct {
+		name string
+		key  string
+	}{
+		{name: "cleartext"},
+		{name: "aes-gcm", key: testEncryptionKey},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			ils := &ImportLocationServer{}
+			if len(bc.key) > 0 {
+				cc, err := newContentCipher(bc.key)
+				if err != nil {
+					b.Fatal(err)
+				}
+				ils.cipher = cc
+			}
+			il := &ImportLocation{content: ils.cipher.seal(content)}
+			b.SetBytes(int64(len(content)))
+			b.ResetTimer()
+			for range b.N {
+				if served, _ := ils.servedContent("/mnist/v1/catalog-info.yaml", il, ""); len(served) != len(content) {
+					b.Fatal("served content does not match")
+				}
+			}
+		})
+	}
+}
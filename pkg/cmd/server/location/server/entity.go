@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// parseEntities decodes the Backstage entities contained in catalog-info content, which can be a single
+// JSON or YAML document, or a multi-document YAML stream
+func parseEntities(content []byte) ([]map[string]interface{}, error) {
+	entities := []map[string]interface{}{}
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	for {
+		entity := map[string]interface{}{}
+		err := decoder.Decode(&entity)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// empty documents are legal in a YAML stream
+		if len(entity) == 0 {
+			continue
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// wellFormed checks content is valid UTF-8 and, depending on its first character, JSON or a YAML stream
+func wellFormed(content []byte) error {
+	if !utf8.Valid(content) {
+		return fmt.Errorf("content is not valid UTF-8")
+	}
+	if isJSON(content) {
+		if !json.Valid(content) {
+			return fmt.Errorf("content is not valid JSON")
+		}
+		return nil
+	}
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("content is not valid YAML: %s", err.Error())
+		}
+	}
+}
+
+// entityRef builds the Backstage entity reference, of the form 'kind:namespace/name', for an entity; Backstage
+// compares entity references case-insensitively, so the reference is lower cased
+func entityRef(entity map[string]interface{}) (string, bool) {
+	kind, _ := entity["kind"].(string)
+	metadata, _ := entity["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if len(kind) == 0 || len(name) == 0 {
+		return "", false
+	}
+	ns, _ := metadata["namespace"].(string)
+	if len(ns) == 0 {
+		ns = "default"
+	}
+	return strings.ToLower(fmt.Sprintf("%s:%s/%s", kind, ns, name)), true
+}
+
+// entityRefs returns the references for all the Backstage entities in catalog-info content; content, like the
+// model catalog JSON format, which does not define Backstage entities yields no references
+func entityRefs(content []byte) ([]string, error) {
+	entities, err := parseEntities(content)
+	if err != nil {
+		return nil, err
+	}
+	refs := []string{}
+	for _, entity := range entities {
+		ref, ok := entityRef(entity)
+		if ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// checkEntityRefs returns an error if any of the entity references are already provided by a location
+// other than the one at uri; callers must hold the server lock
+func (i *ImportLocationServer) checkEntityRefs(uri string, refs []string) error {
+	for _, ref := range refs {
+		owner, ok := i.entityRefs[ref]
+		if ok && owner != uri {
+			return fmt.Errorf("entity %s is already provided by %s", ref, owner)
+		}
+	}
+	return nil
+}
+
+// indexEntityRefs replaces the entity references previously indexed for the location at uri with those of
+// the new location; callers must hold the server lock
+func (i *ImportLocationServer) indexEntityRefs(uri string, old, il *ImportLocation) {
+	if i.entityRefs == nil {
+		i.entityRefs = map[string]string{}
+	}
+	if old != nil {
+		i.unindexEntityRefs(uri, old)
+	}
+	for _, ref := range il.entityRefs {
+		i.entityRefs[ref] = uri
+	}
+}
+
+// unindexEntityRefs removes the entity references of the location at uri from the index; callers must hold
+// the server lock
+func (i *ImportLocationServer) unindexEntityRefs(uri string, il *ImportLocation) {
+	for _, ref := range il.entityRefs {
+		if i.entityRefs[ref] == uri {
+			delete(i.entityRefs, ref)
+		}
+	}
+}
+
+// isJSON reports whether content is JSON, as opposed to YAML, based on its first non-whitespace character
+func isJSON(content []byte) bool {
+	trimmed := bytes.TrimSpace(content)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// marshalEntities is the inverse of parseEntities, where JSON content holds a single entity and YAML content is
+// written as a multi-document stream
+func marshalEntities(entities []map[string]interface{}, asJSON bool) ([]byte, error) {
+	if asJSON && len(entities) == 1 {
+		return json.Marshal(entities[0])
+	}
+	buf := &bytes.Buffer{}
+	for idx, entity := range entities {
+		if idx > 0 {
+			buf.WriteString("---\n")
+		}
+		data, err := yaml.Marshal(entity)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// entityAnnotations returns the annotations of an entity, creating the metadata and annotations maps if needed
+func entityAnnotations(entity map[string]interface{}) map[string]interface{} {
+	metadata, ok := entity["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		entity["metadata"] = metadata
+	}
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		annotations = map[string]interface{}{}
+		metadata["annotations"] = annotations
+	}
+	return annotations
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+const (
+	mnistEntity = `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: mnist
+spec:
+  owner: rhdh-rhoai-bridge
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  name: mnist-v1
+`
+	mnistUpdatedEntity = `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: mnist
+  description: updated
+spec:
+  owner: rhdh-rhoai-bridge
+`
+	graniteEntity = `{"apiVersion":"backstage.io/v1alpha1","kind":"Component","metadata":{"name":"granite","namespace":"ai"}}`
+)
+
+func TestEntityRefs(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		content      string
+		expectedRefs []string
+		expectErr    bool
+	}{
+		{
+			name:         "multi document yaml",
+			content:      mnistEntity,
+			expectedRefs: []string{"component:default/mnist", "resource:default/mnist-v1"},
+		},
+		{
+			name:         "json with namespace",
+			content:      graniteEntity,
+			expectedRefs: []string{"component:ai/granite"},
+		},
+		{
+			name:         "model catalog json",
+			content:      `{"models":[{"name":"mnist"}]}`,
+			expectedRefs: []string{},
+		},
+		{
+			name:      "not an entity",
+			content:   "create",
+			expectErr: true,
+		},
+	} {
+		refs, err := entityRefs([]byte(tc.content))
+		common.AssertEqual(t, tc.expectErr, err != nil)
+		if !tc.expectErr {
+			common.AssertEqual(t, tc.expectedRefs, refs)
+		}
+	}
+}
+
+func TestHandleCatalogUpsertPostEntityUniqueness(t *testing.T) {
+	// define outside of the test loop so we can vet collisions across upserts
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, entityRefs: map[string]string{}, entityUniqueness: true}
+	for _, tc := range []struct {
+		name            string
+		reqURL          url.URL
+		body            rest.PostBody
+		expectedErrMsg  string
+		expectedSC      int
+		expectedContent map[string]string
+		expectedRefs    map[string]string
+	}{
+		{
+			name:       "unique entity",
+			reqURL:     url.URL{RawQuery: "key=mnist_v1"},
+			body:       rest.PostBody{Body: []byte(mnistEntity)},
+			expectedSC: http.StatusCreated,
+			expectedContent: map[string]string{
+				"/mnist/v1/catalog-info.yaml": mnistEntity,
+			},
+			expectedRefs: map[string]string{
+				"component:default/mnist":   "/mnist/v1/catalog-info.yaml",
+				"resource:default/mnist-v1": "/mnist/v1/catalog-info.yaml",
+			},
+		},
+		{
+			name:           "duplicate entity ref",
+			reqURL:         url.URL{RawQuery: "key=mnist_v2"},
+			body:           rest.PostBody{Body: []byte(mnistUpdatedEntity)},
+			expectedSC:     http.StatusConflict,
+			expectedErrMsg: "entity component:default/mnist is already provided by /mnist/v1/catalog-info.yaml",
+			expectedContent: map[string]string{
+				"/mnist/v1/catalog-info.yaml": mnistEntity,
+			},
+			expectedRefs: map[string]string{
+				"component:default/mnist":   "/mnist/v1/catalog-info.yaml",
+				"resource:default/mnist-v1": "/mnist/v1/catalog-info.yaml",
+			},
+		},
+		{
+			name:       "update same entity",
+			reqURL:     url.URL{RawQuery: "key=mnist_v1"},
+			body:       rest.PostBody{Body: []byte(mnistUpdatedEntity)},
+			expectedSC: http.StatusCreated,
+			expectedContent: map[string]string{
+				"/mnist/v1/catalog-info.yaml": mnistUpdatedEntity,
+			},
+			expectedRefs: map[string]string{
+				"component:default/mnist": "/mnist/v1/catalog-info.yaml",
+			},
+		},
+		{
+			name:       "ref freed by update can be claimed",
+			reqURL:     url.URL{RawQuery: "key=mnist_v2"},
+			body:       rest.PostBody{Body: []byte("kind: Resource\nmetadata:\n  name: mnist-v1\n")},
+			expectedSC: http.StatusCreated,
+			expectedContent: map[string]string{
+				"/mnist/v1/catalog-info.yaml": mnistUpdatedEntity,
+				"/mnist/v2/catalog-info.yaml": "kind: Resource\nmetadata:\n  name: mnist-v1\n",
+			},
+			expectedRefs: map[string]string{
+				"component:default/mnist":   "/mnist/v1/catalog-info.yaml",
+				"resource:default/mnist-v1": "/mnist/v2/catalog-info.yaml",
+			},
+		},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		data, err := json.Marshal(tc.body)
+		common.AssertError(t, err)
+		ctx, eng := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &tc.reqURL, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.router = eng
+
+		ils.handleCatalogUpsertPost(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if len(tc.expectedErrMsg) > 0 {
+			found := false
+			for _, e := range ctx.Errors {
+				if strings.Contains(e.Error(), tc.expectedErrMsg) {
+					found = true
+					break
+				}
+			}
+			common.AssertEqual(t, true, found)
+		}
+		common.AssertEqual(t, len(tc.expectedContent), len(ils.content))
+		for key, val := range tc.expectedContent {
+			v, ok := ils.content[key]
+			common.AssertEqual(t, true, ok)
+			common.AssertEqual(t, val, string(v.content))
+		}
+		common.AssertEqual(t, tc.expectedRefs, ils.entityRefs)
+	}
+}
+
+func TestHandleCatalogDeleteEntityRefs(t *testing.T) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), entityRefs: []string{"component:default/mnist"}},
+		},
+		modelcards:       map[string]modelCardMetadata{},
+		entityRefs:       map[string]string{"component:default/mnist": "/mnist/v1/catalog-info.yaml"},
+		entityUniqueness: true,
+	}
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}}
+
+	ils.handleCatalogDelete(ctx)
+
+	common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+	common.AssertEqual(t, map[string]string{}, ils.entityRefs)
+}
+
+func TestHandleCatalogLookupGetServeValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		content         string
+		serveValidation bool
+		expectedSC      int
+		expectedErrMsg  string
+	}{
+		{
+			name:            "valid yaml",
+			content:         mnistEntity,
+			serveValidation: true,
+			expectedSC:      http.StatusOK,
+		},
+		{
+			name:            "valid json",
+			content:         graniteEntity,
+			serveValidation: true,
+			expectedSC:      http.StatusOK,
+		},
+		{
+			name:            "corrupt json",
+			content:         `{"kind":"Component","metadata":{"name":`,
+			serveValidation: true,
+			expectedSC:      http.StatusInternalServerError,
+			expectedErrMsg:  "content for key mnist_v1 at /mnist/v1/catalog-info.yaml is malformed: content is not valid JSON",
+		},
+		{
+			name:            "corrupt yaml",
+			content:         "kind: Component\nmetadata:\n  name: [mnist\n",
+			serveValidation: true,
+			expectedSC:      http.StatusInternalServerError,
+			expectedErrMsg:  "is malformed: content is not valid YAML",
+		},
+		{
+			name:            "corrupt bytes",
+			content:         "kind: \xff\xfe",
+			serveValidation: true,
+			expectedSC:      http.StatusInternalServerError,
+			expectedErrMsg:  "is malformed: content is not valid UTF-8",
+		},
+		{
+			name:       "corrupt content served when validation is disabled",
+			content:    `{"kind":"Component","metadata":{"name":`,
+			expectedSC: http.StatusOK,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:         map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(tc.content)}},
+			serveValidation: tc.serveValidation,
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{}}
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: "catalog-info.yaml"}}
+
+		ils.handleCatalogLookupGet(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if tc.expectedSC == http.StatusOK {
+			common.AssertEqual(t, tc.content, testWriter.ResponseWriter.Body.String())
+			continue
+		}
+		common.AssertEqual(t, 1, len(ctx.Errors))
+		common.AssertEqual(t, true, strings.Contains(ctx.Errors[0].Error(), tc.expectedErrMsg))
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// envBool parses the boolean setting of the named env var, returning the default and logging an error when
+// it is not set or cannot be parsed
+func envBool(name string, def bool) bool {
+	str := strings.TrimSpace(os.Getenv(name))
+	if len(str) == 0 {
+		return def
+	}
+	b, err := strconv.ParseBool(str)
+	if err != nil {
+		klog.Errorf("invalid boolean %s for env var %s, using %v: %s", str, name, def, err.Error())
+		return def
+	}
+	return b
+}
+
+// envMap parses the named env var as a comma separated list of key=value pairs, skipping and logging malformed
+// entries
+func envMap(name string) map[string]string {
+	m := map[string]string{}
+	str := strings.TrimSpace(os.Getenv(name))
+	if len(str) == 0 {
+		return m
+	}
+	for _, entry := range strings.Split(str, ",") {
+		k, v, ok := strings.Cut(entry, "=")
+		k = strings.TrimSpace(k)
+		if !ok || len(k) == 0 {
+			klog.Errorf("invalid key=value entry %s for env var %s", entry, name)
+			continue
+		}
+		m[k] = strings.TrimSpace(v)
+	}
+	return m
+}
+
+// envInt parses the integer setting of the named env var, returning the default and logging an error when it is
+// not set or cannot be parsed
+func envInt(name string, def int) int {
+	str := strings.TrimSpace(os.Getenv(name))
+	if len(str) == 0 {
+		return def
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil {
+		klog.Errorf("invalid integer %s for env var %s, using %d: %s", str, name, def, err.Error())
+		return def
+	}
+	return n
+}
+
+// envDuration parses the duration setting (i.e. 30s or 5m) of the named env var, returning the default and logging an
+// error when it is not set or cannot be parsed
+func envDuration(name string, def time.Duration) time.Duration {
+	str := strings.TrimSpace(os.Getenv(name))
+	if len(str) == 0 {
+		return def
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		klog.Errorf("invalid duration %s for env var %s, using %s: %s", str, name, def.String(), err.Error())
+		return def
+	}
+	return d
+}
+
+// envList parses the named env var as a comma separated list, returning the default when it is not set
+func envList(name string, def []string) []string {
+	str := strings.TrimSpace(os.Getenv(name))
+	if len(str) == 0 {
+		return def
+	}
+	list := []string{}
+	for _, entry := range strings.Split(str, ",") {
+		if entry = strings.TrimSpace(entry); len(entry) > 0 {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// envString returns the trimmed setting of the named env var, or the default when it is not set
+func envString(name, def string) string {
+	str := strings.TrimSpace(os.Getenv(name))
+	if len(str) == 0 {
+		return def
+	}
+	return str
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+const (
+	eventUpsert = "upsert"
+	eventRemove = "remove"
+
+	defaultSSEHeartbeatInterval = 15 * time.Second
+	// eventBuffer is how many events a subscriber can fall behind by before further events are dropped for it
+	eventBuffer = 64
+)
+
+type LocationEvent struct {
+	Type string `json:"-"`
+	URI  string `json:"uri"`
+}
+
+// eventBroker fans location events out to the subscribers of the streaming endpoints; publishing never blocks on a
+// slow subscriber, which misses events instead.  As each subscriber holds a connection, goroutine and buffer, their
+// number is capped at maxSubscribers, unless zero.  Events are also recorded in changes, if set.
+type eventBroker struct {
+	lock           sync.Mutex
+	subscribers    map[chan LocationEvent]struct{}
+	maxSubscribers int
+	changes        *changeLog
+}
+
+func newEventBroker(maxSubscribers int) *eventBroker {
+	return &eventBroker{subscribers: map[chan LocationEvent]struct{}{}, maxSubscribers: maxSubscribers}
+}
+
+// subscribe adds a subscriber, returning false when the broker already has its maximum number of subscribers
+func (b *eventBroker) subscribe() (chan LocationEvent, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.maxSubscribers > 0 && len(b.subscribers) >= b.maxSubscribers {
+		return nil, false
+	}
+	ch := make(chan LocationEvent, eventBuffer)
+	b.subscribers[ch] = struct{}{}
+	eventSubscribers.Inc()
+	return ch, true
+}
+
+func (b *eventBroker) unsubscribe(ch chan LocationEvent) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		eventSubscribers.Dec()
+	}
+}
+
+// publish sends an event to every subscriber; a nil broker has none
+func (b *eventBroker) publish(e LocationEvent) {
+	if b == nil {
+		return
+	}
+	b.changes.record(e)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			klog.Infof("dropped %s event for %s for a subscriber that has fallen behind", e.Type, e.URI)
+		}
+	}
+}
+
+// handleEventsGet streams location events as server-sent events, interleaved with keepalive comments every
+// heartbeat interval so that proxies do not close idle connections; subscribers beyond the maximum are turned away
+// with a 503
+func (i *ImportLocationServer) handleEventsGet(c *gin.Context) {
+	if i.events == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	ch, ok := i.events.subscribe()
+	if !ok {
+		c.Status(http.StatusServiceUnavailable)
+		c.Error(fmt.Errorf("the maximum of %d event subscribers are already subscribed", i.events.maxSubscribers))
+		return
+	}
+	defer i.events.unsubscribe(ch)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	var heartbeat <-chan time.Time
+	if i.sseHeartbeatInterval > 0 {
+		ticker := time.NewTicker(i.sseHeartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat:
+			if _, err := fmt.Fprint(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case e := <-ch:
+			data, err := json.Marshal(&e)
+			if err != nil {
+				klog.Errorf("unable to marshal %s event for %s: %s", e.Type, e.URI, err.Error())
+				continue
+			}
+			if _, err = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestHandleEventsGetHeartbeat(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:              map[string]*ImportLocation{},
+		modelcards:           map[string]modelCardMetadata{},
+		events:               newEventBroker(0),
+		sseHeartbeatInterval: 50 * time.Millisecond,
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.GET(util.EventsURI, ils.handleEventsGet)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+util.EventsURI, nil)
+	common.AssertError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	common.AssertError(t, err)
+	defer resp.Body.Close()
+	common.AssertEqual(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	type line struct {
+		text string
+		at   time.Time
+	}
+	lines := make(chan line, 100)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if len(scanner.Text()) > 0 {
+				lines <- line{text: scanner.Text(), at: time.Now()}
+			}
+		}
+		close(lines)
+	}()
+	next := func() line {
+		select {
+		case l := <-lines:
+			return l
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the event stream")
+		}
+		return line{}
+	}
+
+	// an idle connection gets a heartbeat every interval
+	start := time.Now()
+	var last time.Time
+	for n := 0; n < 3; n++ {
+		l := next()
+		common.AssertEqual(t, ": keepalive", l.text)
+		last = l.at
+	}
+	elapsed := last.Sub(start)
+	common.AssertEqual(t, true, elapsed >= 140*time.Millisecond && elapsed < time.Second)
+
+	// events are delivered whole between heartbeats
+	upsertForTest(t, ils, "mnist_v1", "create")
+	l := next()
+	for l.text == ": keepalive" {
+		l = next()
+	}
+	common.AssertEqual(t, "event: upsert", l.text)
+	common.AssertEqual(t, `data: {"uri":"/mnist/v1/catalog-info.yaml"}`, next().text)
+	common.AssertEqual(t, ": keepalive", next().text)
+}
+
+func TestHandleEventsGetMaxSubscribers(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{},
+		events:     newEventBroker(2),
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.GET(util.EventsURI, ils.handleEventsGet)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+	before := gaugeValue(t, eventSubscribers)
+
+	subscribe := func(ctx context.Context) *http.Response {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+util.EventsURI, nil)
+		common.AssertError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		common.AssertError(t, err)
+		return resp
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	first := subscribe(firstCtx)
+	defer first.Body.Close()
+	second := subscribe(ctx)
+	defer second.Body.Close()
+	common.AssertEqual(t, http.StatusOK, first.StatusCode)
+	common.AssertEqual(t, http.StatusOK, second.StatusCode)
+	common.AssertEqual(t, before+2, gaugeValue(t, eventSubscribers))
+
+	// subscribers past the cap are turned away
+	third := subscribe(ctx)
+	third.Body.Close()
+	common.AssertEqual(t, http.StatusServiceUnavailable, third.StatusCode)
+	common.AssertEqual(t, before+2, gaugeValue(t, eventSubscribers))
+
+	// a subscriber disconnecting makes room for another
+	cancelFirst()
+	deadline := time.Now().Add(2 * time.Second)
+	for gaugeValue(t, eventSubscribers) != before+1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	common.AssertEqual(t, before+1, gaugeValue(t, eventSubscribers))
+	fourth := subscribe(ctx)
+	defer fourth.Body.Close()
+	common.AssertEqual(t, http.StatusOK, fourth.StatusCode)
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+)
+
+// FeatureFlags enumerates the optional behaviors of the location service that are switched on or off, each off by
+// default; behaviors configured by a value, such as a duration or a key, rather than a switch are not flags
+type FeatureFlags struct {
+	EntityUniqueness      bool
+	FetchOnMiss           bool
+	StaleIfError          bool
+	FormatAutoDetect      bool
+	ContentNegotiation    bool
+	SchemaNegotiation     bool
+	ServeValidation       bool
+	NotReadyServes503     bool
+	ModelCardKeyConflicts bool
+	ModelsIncludeEmpty    bool
+	ReadThrough           bool
+	Tracing               bool
+	SourceMetrics         bool
+	SkipStartupLoad       bool
+}
+
+// featureFlagsFromEnv returns the flags as set by their env vars
+func featureFlagsFromEnv() FeatureFlags {
+	return FeatureFlags{
+		EntityUniqueness:      envBool(types.EntityUniquenessEnvVar, false),
+		FetchOnMiss:           envBool(types.FetchOnMissEnvVar, false),
+		StaleIfError:          envBool(types.StaleIfErrorEnvVar, false),
+		FormatAutoDetect:      envBool(types.FormatAutoDetectEnvVar, false),
+		ContentNegotiation:    envBool(types.ContentNegotiationEnvVar, false),
+		SchemaNegotiation:     envBool(types.SchemaNegotiationEnvVar, false),
+		ServeValidation:       envBool(types.ServeValidationEnvVar, false),
+		NotReadyServes503:     envBool(types.NotReadyServes503EnvVar, false),
+		ModelCardKeyConflicts: envBool(types.ModelCardKeyConflictsEnvVar, false),
+		ModelsIncludeEmpty:    envBool(types.ModelsIncludeEmptyEnvVar, false),
+		ReadThrough:           envBool(types.ReadThroughEnvVar, false),
+		Tracing:               envBool(types.TracingEnabledEnvVar, false),
+		SourceMetrics:         envBool(types.SourceMetricsEnvVar, false),
+		SkipStartupLoad:       envBool(types.SkipStartupLoadEnvVar, false),
+	}
+}
+
+// validate returns an error listing the flags that are set together but cannot work together, if any
+func (f FeatureFlags) validate() error {
+	conflicts := []string{}
+	for _, c := range []struct {
+		set    bool
+		reason string
+	}{
+		{f.ReadThrough && f.FetchOnMiss, fmt.Sprintf("%s with %s, as read-through lookups always fetch from storage", types.FetchOnMissEnvVar, types.ReadThroughEnvVar)},
+		{f.ReadThrough && f.EntityUniqueness, fmt.Sprintf("%s with %s, as uniqueness is checked against the content held in memory", types.EntityUniquenessEnvVar, types.ReadThroughEnvVar)},
+		{f.ReadThrough && f.ModelsIncludeEmpty, fmt.Sprintf("%s with %s, as models are not listed in read-through mode", types.ModelsIncludeEmptyEnvVar, types.ReadThroughEnvVar)},
+	} {
+		if c.set {
+			conflicts = append(conflicts, c.reason)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("conflicting features: %s", strings.Join(conflicts, "; "))
+}
+
+// features returns the flags the server is running with
+func (i *ImportLocationServer) features() FeatureFlags {
+	return FeatureFlags{
+		EntityUniqueness:      i.entityUniqueness,
+		FetchOnMiss:           i.fetchOnMiss,
+		StaleIfError:          i.staleIfError,
+		FormatAutoDetect:      i.formatAutoDetect,
+		ContentNegotiation:    i.contentNegotiation,
+		SchemaNegotiation:     i.schemaNegotiation,
+		ServeValidation:       i.serveValidation,
+		NotReadyServes503:     i.notReadyServes503,
+		ModelCardKeyConflicts: i.modelCardKeyConflicts,
+		ModelsIncludeEmpty:    i.modelsIncludeEmpty,
+		ReadThrough:           i.readThrough != nil,
+		Tracing:               i.tracing,
+		SourceMetrics:         i.sourceMetrics,
+		SkipStartupLoad:       i.skipStartupLoad,
+	}
+}
+
+// setFeatures switches the optional behaviors on and off as flagged, with read-through mode switched on without
+// caching unless its cache is already configured; it should be called before Run
+func (i *ImportLocationServer) setFeatures(f FeatureFlags) {
+	i.entityUniqueness = f.EntityUniqueness
+	i.fetchOnMiss = f.FetchOnMiss
+	i.staleIfError = f.StaleIfError
+	i.formatAutoDetect = f.FormatAutoDetect
+	i.contentNegotiation = f.ContentNegotiation
+	i.schemaNegotiation = f.SchemaNegotiation
+	i.serveValidation = f.ServeValidation
+	i.notReadyServes503 = f.NotReadyServes503
+	i.modelCardKeyConflicts = f.ModelCardKeyConflicts
+	i.modelsIncludeEmpty = f.ModelsIncludeEmpty
+	i.tracing = f.Tracing
+	i.sourceMetrics = f.SourceMetrics
+	i.skipStartupLoad = f.SkipStartupLoad
+	switch {
+	case !f.ReadThrough:
+		i.readThrough = nil
+	case i.readThrough == nil:
+		i.readThrough = newReadThroughCache(0, defaultReadThroughCacheMaxEntries)
+	}
+}
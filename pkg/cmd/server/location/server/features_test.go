@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestFeatureFlagsFromEnv(t *testing.T) {
+	common.AssertEqual(t, FeatureFlags{}, featureFlagsFromEnv())
+	t.Setenv(types.FetchOnMissEnvVar, "true")
+	t.Setenv(types.ModelsIncludeEmptyEnvVar, "true")
+	t.Setenv(types.TracingEnabledEnvVar, "false")
+	common.AssertEqual(t, FeatureFlags{FetchOnMiss: true, ModelsIncludeEmpty: true}, featureFlagsFromEnv())
+}
+
+func TestFeatureFlagsValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		flags       FeatureFlags
+		expectedErr bool
+	}{
+		{name: "defaults"},
+		{name: "independent features", flags: FeatureFlags{FetchOnMiss: true, StaleIfError: true, EntityUniqueness: true, Tracing: true}},
+		{name: "read-through alone", flags: FeatureFlags{ReadThrough: true, FormatAutoDetect: true}},
+		{name: "read-through with fetch on miss", flags: FeatureFlags{ReadThrough: true, FetchOnMiss: true}, expectedErr: true},
+		{name: "read-through with entity uniqueness", flags: FeatureFlags{ReadThrough: true, EntityUniqueness: true}, expectedErr: true},
+		{name: "read-through with empty models", flags: FeatureFlags{ReadThrough: true, ModelsIncludeEmpty: true}, expectedErr: true},
+	} {
+		err := tc.flags.validate()
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("%s: expected error %v but got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestFeatureFlagsGate(t *testing.T) {
+	for _, on := range []bool{false, true} {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("kind: Component\n")})
+		ils := &ImportLocationServer{
+			content: map[string]*ImportLocation{"/fraud/v1/catalog-info.yaml": {}},
+			storage: st,
+			format:  types.CatalogInfoYamlFormat,
+		}
+		flags := FeatureFlags{FetchOnMiss: on, ModelsIncludeEmpty: on}
+		ils.setFeatures(flags)
+		common.AssertEqual(t, flags, ils.features())
+
+		// fetch on miss serves content not yet loaded from storage
+		sc, _ := readThroughLookup(t, ils, "mnist")
+		expectedSC := http.StatusNotFound
+		if on {
+			expectedSC = http.StatusOK
+		}
+		common.AssertEqual(t, expectedSC, sc)
+
+		// models with all versions removed are listed when flagged
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{}}
+		ils.handleModelsGet(ctx)
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		resp := ModelsResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		listed := false
+		for _, m := range resp.Models {
+			listed = listed || m.Model == "fraud"
+		}
+		common.AssertEqual(t, on, listed)
+	}
+
+	// read-through mode is switched on and off
+	ils := &ImportLocationServer{}
+	ils.setFeatures(FeatureFlags{ReadThrough: true})
+	common.AssertEqual(t, true, ils.readThrough != nil)
+	ils.setFeatures(FeatureFlags{})
+	common.AssertEqual(t, true, ils.readThrough == nil)
+}
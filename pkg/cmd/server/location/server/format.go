@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+)
+
+const (
+	catalogInfoFileName  = "catalog-info.yaml"
+	modelCatalogFileName = "model-catalog.json"
+)
+
+// detectFormat infers the normalizer format of content: a JSON array, or a model catalog JSON object with its
+// models, is the JSON array format, while Backstage entities are the catalog-info format.  Anything else is
+// ambiguous and gets the default.
+func detectFormat(content []byte, def types.NormalizerFormat) types.NormalizerFormat {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return types.JsonArrayForamt
+	}
+	if isJSON(trimmed) {
+		doc := map[string]interface{}{}
+		if err := json.Unmarshal(trimmed, &doc); err == nil {
+			if _, ok := doc["models"]; ok {
+				return types.JsonArrayForamt
+			}
+		}
+	}
+	entities, err := parseEntities(content)
+	if err != nil || len(entities) == 0 {
+		return def
+	}
+	for _, entity := range entities {
+		apiVersion, _ := entity["apiVersion"].(string)
+		kind, _ := entity["kind"].(string)
+		if len(apiVersion) == 0 || len(kind) == 0 {
+			return def
+		}
+	}
+	return types.CatalogInfoYamlFormat
+}
+
+// formatForFileName maps the file name of the :format segment of a lookup to its format
+func formatForFileName(fn string, def types.NormalizerFormat) types.NormalizerFormat {
+	switch fn {
+	case catalogInfoFileName:
+		return types.CatalogInfoYamlFormat
+	case modelCatalogFileName:
+		return types.JsonArrayForamt
+	}
+	return def
+}
+
+// formatFor returns the format whose URI content is served from, which is the configured format unless
+// auto-detection is enabled
+func (i *ImportLocationServer) formatFor(content []byte) types.NormalizerFormat {
+	if !i.formatAutoDetect {
+		return i.format
+	}
+	return detectFormat(content, i.format)
+}
+
+// candidateURIs returns the URIs the location of a model version may be served from: that of the configured
+// format, followed, with auto-detection, by that of the other format
+func (i *ImportLocationServer) candidateURIs(seg1, seg2 string) []string {
+	_, uri := i.buildKeyAndURI(seg1, seg2, i.format)
+	uris := []string{uri}
+	if !i.formatAutoDetect {
+		return uris
+	}
+	for _, f := range []types.NormalizerFormat{types.CatalogInfoYamlFormat, types.JsonArrayForamt} {
+		if _, other := i.buildKeyAndURI(seg1, seg2, f); other != uri {
+			uris = append(uris, other)
+		}
+	}
+	return uris
+}
+
+// SupportedFormat details a normalizer format locations are served in
+type SupportedFormat struct {
+	Format      types.NormalizerFormat `json:"format"`
+	FileName    string                 `json:"fileName"`
+	ContentType string                 `json:"contentType"`
+}
+
+// FormatsResponse lists the formats the server is configured to serve, along with the default format, which is the
+// format of locations whose format is not detected
+type FormatsResponse struct {
+	Formats []SupportedFormat      `json:"formats"`
+	Default types.NormalizerFormat `json:"default"`
+}
+
+// supportedFormats returns the formats locations are served in: the configured format, followed, with
+// auto-detection, by the other format
+func (i *ImportLocationServer) supportedFormats() []types.NormalizerFormat {
+	formats := []types.NormalizerFormat{i.format}
+	if !i.formatAutoDetect {
+		return formats
+	}
+	for _, f := range []types.NormalizerFormat{types.CatalogInfoYamlFormat, types.JsonArrayForamt} {
+		if f != i.format {
+			formats = append(formats, f)
+		}
+	}
+	return formats
+}
+
+// describeFormats describes the supported formats with the file name and content type they are served with
+func (i *ImportLocationServer) describeFormats() []SupportedFormat {
+	formats := []SupportedFormat{}
+	for _, f := range i.supportedFormats() {
+		_, uri := util.BuildImportKeyAndURI("model", "version", f)
+		formats = append(formats, SupportedFormat{Format: f, FileName: path.Base(uri), ContentType: i.contentTypeFor(f)})
+	}
+	return formats
+}
+
+func (i *ImportLocationServer) handleFormatsGet(c *gin.Context) {
+	resp := &FormatsResponse{Formats: i.describeFormats(), Default: i.format}
+	content, err := json.Marshal(resp)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
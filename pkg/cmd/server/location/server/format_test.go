@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestDetectFormat(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		content        string
+		expectedFormat types.NormalizerFormat
+	}{
+		{
+			name:           "json array",
+			content:        `[{"name":"mnist"}]`,
+			expectedFormat: types.JsonArrayForamt,
+		},
+		{
+			name:           "model catalog json",
+			content:        `{"models":[{"name":"mnist"}],"modelServer":{"name":"mnist-v1"}}`,
+			expectedFormat: types.JsonArrayForamt,
+		},
+		{
+			name:           "catalog-info yaml",
+			content:        mnistEntity,
+			expectedFormat: types.CatalogInfoYamlFormat,
+		},
+		{
+			name:           "catalog-info json entity",
+			content:        graniteEntity,
+			expectedFormat: types.CatalogInfoYamlFormat,
+		},
+		{
+			name:           "ambiguous yaml",
+			content:        "name: mnist\n",
+			expectedFormat: types.JsonArrayForamt,
+		},
+		{
+			name:           "ambiguous text",
+			content:        "create",
+			expectedFormat: types.JsonArrayForamt,
+		},
+	} {
+		// the default is the json array format so that falling back to it is distinguishable from detecting yaml
+		common.AssertEqual(t, tc.expectedFormat, detectFormat([]byte(tc.content), types.JsonArrayForamt))
+	}
+}
+
+func TestFormatAutoDetectUpsertAndLookup(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:          map[string]*ImportLocation{},
+		modelcards:       map[string]modelCardMetadata{},
+		format:           types.CatalogInfoYamlFormat,
+		formatAutoDetect: true,
+	}
+	for _, tc := range []struct {
+		name        string
+		body        string
+		expectedURI string
+		lookups     map[string]int
+	}{
+		{
+			name:        "yaml upsert",
+			body:        mnistEntity,
+			expectedURI: "/mnist/v1/catalog-info.yaml",
+			lookups:     map[string]int{"catalog-info.yaml": http.StatusOK, "model-catalog.json": http.StatusNotFound},
+		},
+		{
+			name:        "json array upsert replaces the yaml location",
+			body:        `{"models":[{"name":"mnist"}]}`,
+			expectedURI: "/mnist/v1/model-catalog.json",
+			lookups:     map[string]int{"catalog-info.yaml": http.StatusNotFound, "model-catalog.json": http.StatusOK},
+		},
+		{
+			name:        "ambiguous upsert uses the configured format",
+			body:        "create",
+			expectedURI: "/mnist/v1/catalog-info.yaml",
+			lookups:     map[string]int{"catalog-info.yaml": http.StatusOK, "model-catalog.json": http.StatusNotFound},
+		},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		data, err := json.Marshal(rest.PostBody{Body: []byte(tc.body)})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+		common.AssertEqual(t, tc.body, string(ils.content[tc.expectedURI].content))
+
+		for fn, sc := range tc.lookups {
+			testWriter = testgin.NewTestResponseWriter()
+			ctx, _ = gin.CreateTestContext(testWriter)
+			ctx.Request = &http.Request{URL: &url.URL{}}
+			ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: fn}}
+			ils.handleCatalogLookupGet(ctx)
+			common.AssertEqual(t, sc, ctx.Writer.Status())
+		}
+	}
+}
+
+func TestHandleFormatsGet(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		format           types.NormalizerFormat
+		formatAutoDetect bool
+		contentTypes     map[types.NormalizerFormat]string
+		expected         FormatsResponse
+	}{
+		{
+			name:   "configured format only",
+			format: types.CatalogInfoYamlFormat,
+			expected: FormatsResponse{
+				Formats: []SupportedFormat{{Format: types.CatalogInfoYamlFormat, FileName: catalogInfoFileName, ContentType: mimeYAML}},
+				Default: types.CatalogInfoYamlFormat,
+			},
+		},
+		{
+			name:             "both formats with auto-detection",
+			format:           types.JsonArrayForamt,
+			formatAutoDetect: true,
+			contentTypes:     parseContentTypes(map[string]string{string(types.CatalogInfoYamlFormat): "text/yaml"}),
+			expected: FormatsResponse{
+				Formats: []SupportedFormat{
+					{Format: types.JsonArrayForamt, FileName: modelCatalogFileName, ContentType: mimeJSON},
+					{Format: types.CatalogInfoYamlFormat, FileName: catalogInfoFileName, ContentType: "text/yaml"},
+				},
+				Default: types.JsonArrayForamt,
+			},
+		},
+	} {
+		ils := &ImportLocationServer{format: tc.format, formatAutoDetect: tc.formatAutoDetect, contentTypes: tc.contentTypes}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+
+		ils.handleFormatsGet(ctx)
+
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		resp := FormatsResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		common.AssertEqual(t, tc.expected, resp)
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// contentHash is the algorithm the checksums of content are computed with, for the manifest, discovery and drift,
+// and to key the content cached as served; checksums are prefixed with the algorithm's name, so those computed with
+// different algorithms never compare equal
+type contentHash string
+
+const (
+	hashSHA256 contentHash = "sha256"
+	hashSHA1   contentHash = "sha1"
+	// hashXXHash is a fast non-cryptographic hash, for when checksums only need to tell content apart
+	hashXXHash contentHash = "xxhash"
+)
+
+func parseContentHash(str string) (contentHash, error) {
+	switch h := contentHash(strings.ToLower(strings.TrimSpace(str))); h {
+	case hashSHA256, hashSHA1, hashXXHash:
+		return h, nil
+	case "":
+		return hashSHA256, nil
+	default:
+		return hashSHA256, fmt.Errorf("unknown content hash algorithm %q, the algorithms are %s, %s and %s", str, hashSHA256, hashSHA1, hashXXHash)
+	}
+}
+
+// sum returns the checksum of content, SHA-256 unless another algorithm is set
+func (h contentHash) sum(content []byte) string {
+	switch h {
+	case hashSHA1:
+		sum := sha1.Sum(content)
+		return string(hashSHA1) + ":" + hex.EncodeToString(sum[:])
+	case hashXXHash:
+		return fmt.Sprintf("%s:%016x", hashXXHash, xxhash.Sum64(content))
+	default:
+		sum := sha256.Sum256(content)
+		return string(hashSHA256) + ":" + hex.EncodeToString(sum[:])
+	}
+}
+
+// checksum returns the checksum of content with the server's configured algorithm
+func (i *ImportLocationServer) checksum(content []byte) string {
+	return i.contentHash.sum(content)
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestContentHash(t *testing.T) {
+	for _, tc := range []struct {
+		raw         string
+		expected    contentHash
+		expectedLen int
+	}{
+		{raw: "", expected: hashSHA256, expectedLen: len("sha256:") + 64},
+		{raw: "SHA256", expected: hashSHA256, expectedLen: len("sha256:") + 64},
+		{raw: "sha1", expected: hashSHA1, expectedLen: len("sha1:") + 40},
+		{raw: " xxhash ", expected: hashXXHash, expectedLen: len("xxhash:") + 16},
+	} {
+		h, err := parseContentHash(tc.raw)
+		common.AssertError(t, err)
+		common.AssertEqual(t, tc.expected, h)
+
+		sum := h.sum([]byte(mnistEntity))
+		common.AssertEqual(t, tc.expectedLen, len(sum))
+		common.AssertEqual(t, true, strings.HasPrefix(sum, string(tc.expected)+":"))
+		// stable for identical content, changed with the content
+		common.AssertEqual(t, sum, h.sum([]byte(mnistEntity)))
+		common.AssertEqual(t, false, sum == h.sum([]byte(mnistUpdatedEntity)))
+	}
+
+	h, err := parseContentHash("md5")
+	if err == nil {
+		t.Errorf("expected an unknown algorithm to be rejected")
+	}
+	common.AssertEqual(t, hashSHA256, h)
+}
+
+func TestManifestContentHash(t *testing.T) {
+	for _, h := range []contentHash{hashSHA256, hashSHA1, hashXXHash} {
+		ils := &ImportLocationServer{
+			content: map[string]*ImportLocation{
+				"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity)},
+			},
+			contentHash: h,
+		}
+		r := gin.New()
+		r.GET(util.ManifestURI, ils.handleManifestGet)
+		getManifest := func() ManifestResponse {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ManifestURI, nil))
+			common.AssertEqual(t, http.StatusOK, rec.Code)
+			m := ManifestResponse{}
+			common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &m))
+			return m
+		}
+
+		first := getManifest()
+		common.AssertEqual(t, []ManifestEntry{{URI: "/mnist/v1/catalog-info.yaml", Checksum: h.sum([]byte(mnistEntity))}}, first.Entries)
+		common.AssertEqual(t, manifestChecksum(h, first.Entries), first.Checksum)
+		common.AssertEqual(t, first, getManifest())
+
+		ils.content["/mnist/v1/catalog-info.yaml"] = &ImportLocation{content: []byte(mnistUpdatedEntity)}
+		updated := getManifest()
+		common.AssertEqual(t, h.sum([]byte(mnistUpdatedEntity)), updated.Entries[0].Checksum)
+		common.AssertEqual(t, false, first.Checksum == updated.Checksum)
+	}
+}
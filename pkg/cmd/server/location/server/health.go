@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+// initialLoadRetryInterval is how often a failed initial load from storage is retried when not ready serves 503
+const initialLoadRetryInterval = 5 * time.Second
+
+// handleHealthzGet is the liveness probe, which fails once no reconcile with storage has succeeded for the reconcile
+// stale after duration, as when the reconcile loop is wedged, so the pod is restarted
+func (i *ImportLocationServer) handleHealthzGet(c *gin.Context) {
+	if since, ok := i.reconcileStats.sinceSuccess(); ok && i.reconcileStaleAfter > 0 && since > i.reconcileStaleAfter {
+		c.String(http.StatusServiceUnavailable, fmt.Sprintf("no reconcile with storage has succeeded for %s", since.Round(time.Second)))
+		return
+	}
+	c.String(http.StatusOK, "ok")
+}
+
+func (i *ImportLocationServer) handleReadyzGet(c *gin.Context) {
+	if i.notReadyServes503 && !i.initialLoadDone.Load() {
+		c.String(http.StatusServiceUnavailable, "the initial load from storage has not completed")
+		return
+	}
+	c.String(http.StatusOK, "ok")
+}
+
+// requireInitialLoad, when not ready serves 503, fails requests to the data endpoints with a 503 until the initial
+// load from storage fully succeeds, so that a partial catalog is never served
+func (i *ImportLocationServer) requireInitialLoad() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if i.notReadyServes503 && !i.initialLoadDone.Load() {
+			c.AbortWithError(http.StatusServiceUnavailable, fmt.Errorf("the initial load from storage has not completed"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// initialLoad loads from storage, unless skipped, retrying until it fully succeeds or the server is stopped when not
+// ready serves 503, as the data endpoints would otherwise stay unavailable until the next reconcile
+func (i *ImportLocationServer) initialLoad(stopCh <-chan struct{}, retryInterval time.Duration) {
+	if i.skipStartupLoad {
+		klog.Info("skipping the initial load from storage")
+		i.initialLoadDone.Store(true)
+		return
+	}
+	for {
+		loaded, err := i.loadFromStorage()
+		if err != nil {
+			klog.Errorf("initial load from storage failed: %s", err.Error())
+		} else {
+			klog.Infof("initial load from storage complete: %v", loaded)
+		}
+		if loaded {
+			i.initialLoadDone.Store(true)
+			return
+		}
+		if !i.notReadyServes503 {
+			return
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+)
+
+func TestNotReadyServes503(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		notReadyServes503 bool
+		expectedBeforeSC  int
+	}{
+		{
+			name:             "ungated startup serves a partial catalog",
+			expectedBeforeSC: http.StatusOK,
+		},
+		{
+			name:              "gated startup serves 503 until loaded",
+			notReadyServes503: true,
+			expectedBeforeSC:  http.StatusServiceUnavailable,
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("stored")})
+		st.ListErr = fmt.Errorf("storage is down")
+		ils := &ImportLocationServer{
+			content:           map[string]*ImportLocation{},
+			modelcards:        map[string]modelCardMetadata{},
+			storage:           st,
+			notReadyServes503: tc.notReadyServes503,
+		}
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		r.GET(util.ListURI, ils.requireInitialLoad(), ils.handleCatalogDiscoveryGet)
+		r.GET("/:model/:version/:format", ils.requireInitialLoad(), ils.handleCatalogLookupGet)
+		r.POST(util.UpsertURI, ils.handleCatalogUpsertPost)
+		r.GET(util.ReadyzURI, ils.handleReadyzGet)
+		get := func(path string) int {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			return rec.Code
+		}
+
+		// a failed initial load is retried until stopped when gated, and not at all otherwise
+		stopCh := make(chan struct{})
+		close(stopCh)
+		ils.initialLoad(stopCh, time.Hour)
+		common.AssertEqual(t, tc.expectedBeforeSC, get(util.ListURI))
+		common.AssertEqual(t, tc.expectedBeforeSC, get(util.ReadyzURI))
+
+		// upserts are accepted regardless
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, util.UpsertURI+"?key=granite_v1", bytes.NewBufferString(`{"body":"Y3JlYXRl"}`)))
+		common.AssertEqual(t, http.StatusCreated, rec.Code)
+		if tc.notReadyServes503 {
+			common.AssertEqual(t, http.StatusServiceUnavailable, get("/granite/v1/catalog-info.yaml"))
+		} else {
+			common.AssertEqual(t, http.StatusOK, get("/granite/v1/catalog-info.yaml"))
+		}
+
+		st.ListErr = nil
+		ils.initialLoad(make(chan struct{}), time.Hour)
+		common.AssertEqual(t, http.StatusOK, get(util.ListURI))
+		common.AssertEqual(t, http.StatusOK, get(util.ReadyzURI))
+		common.AssertEqual(t, http.StatusOK, get("/mnist/v1/catalog-info.yaml"))
+	}
+}
+
+func TestSkipStartupLoad(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("stored")})
+	ils := &ImportLocationServer{
+		content:           map[string]*ImportLocation{},
+		modelcards:        map[string]modelCardMetadata{},
+		storage:           st,
+		notReadyServes503: true,
+		skipStartupLoad:   true,
+	}
+	ils.initialLoad(make(chan struct{}), time.Hour)
+	common.AssertEqual(t, 0, st.FetchCount("mnist_v1"))
+	common.AssertEqual(t, 0, len(ils.content))
+	// nothing is left to wait for, so the server is ready
+	common.AssertEqual(t, true, ils.initialLoadDone.Load())
+}
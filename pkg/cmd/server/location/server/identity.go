@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// keyIdentityCheck determines how upserts whose content names no entity for the model or version of their key are
+// handled, which catches a normalizer posting content under the wrong key
+type keyIdentityCheck string
+
+const (
+	// keyIdentityOff does not compare the content with the key
+	keyIdentityOff keyIdentityCheck = "off"
+	// keyIdentityWarn logs a mismatch but upserts the content
+	keyIdentityWarn keyIdentityCheck = "warn"
+	// keyIdentityReject rejects a mismatched upsert with a 400
+	keyIdentityReject keyIdentityCheck = "reject"
+)
+
+func parseKeyIdentityCheck(str string) keyIdentityCheck {
+	switch m := keyIdentityCheck(strings.ToLower(strings.TrimSpace(str))); m {
+	case keyIdentityOff, keyIdentityWarn, keyIdentityReject:
+		return m
+	case "":
+	default:
+		klog.Errorf("invalid key identity check %s, using %s", str, keyIdentityOff)
+	}
+	return keyIdentityOff
+}
+
+// identityMismatch compares the names of the Backstage entities in content with the model and version of its key,
+// as normalizers name the entities of a model version for the model, the version, or both joined with a '-'; it
+// returns why they mismatch, or an empty string when an entity matches or the content defines no named entity to
+// compare, such as the model catalog JSON format
+func identityMismatch(model, version string, content []byte) string {
+	entities, err := parseEntities(content)
+	if err != nil {
+		return ""
+	}
+	names := []string{}
+	for _, entity := range entities {
+		metadata, _ := entity["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		if len(name) == 0 {
+			continue
+		}
+		for _, expected := range []string{model, version, model + "-" + version} {
+			if strings.EqualFold(name, expected) {
+				return ""
+			}
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("no entity is named for model %s version %s, the content names %s", model, version, strings.Join(names, ", "))
+}
+
+// checkKeyIdentity applies the key identity check to the content upserted for key, returning an error when a
+// mismatch is rejected
+func (i *ImportLocationServer) checkKeyIdentity(key, model, version string, content []byte) error {
+	if i.keyIdentityCheck != keyIdentityWarn && i.keyIdentityCheck != keyIdentityReject {
+		return nil
+	}
+	mismatch := identityMismatch(model, version, content)
+	if len(mismatch) == 0 {
+		return nil
+	}
+	if i.keyIdentityCheck == keyIdentityReject {
+		return fmt.Errorf("the content upserted for key %s does not match it: %s", key, mismatch)
+	}
+	klog.Warningf("the content upserted for key %s may not match it: %s", key, mismatch)
+	return nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleCatalogUpsertPostKeyIdentity(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		check          keyIdentityCheck
+		key            string
+		content        string
+		expectedSC     int
+		expectUpserted bool
+	}{
+		{
+			name:           "matching is upserted",
+			check:          keyIdentityReject,
+			key:            "mnist_v1",
+			content:        mnistEntity,
+			expectedSC:     http.StatusCreated,
+			expectUpserted: true,
+		},
+		{
+			name:           "matching the version, as kserve names entities, is upserted",
+			check:          keyIdentityReject,
+			key:            "ai_granite",
+			content:        graniteEntity,
+			expectedSC:     http.StatusCreated,
+			expectUpserted: true,
+		},
+		{
+			name:       "mismatching is rejected",
+			check:      keyIdentityReject,
+			key:        "granite_v1",
+			content:    mnistEntity,
+			expectedSC: http.StatusBadRequest,
+		},
+		{
+			name:           "mismatching is warned about",
+			check:          keyIdentityWarn,
+			key:            "granite_v1",
+			content:        mnistEntity,
+			expectedSC:     http.StatusCreated,
+			expectUpserted: true,
+		},
+		{
+			name:           "mismatching is not checked by default",
+			key:            "granite_v1",
+			content:        mnistEntity,
+			expectedSC:     http.StatusCreated,
+			expectUpserted: true,
+		},
+		{
+			name:           "content without named entities is not checked",
+			check:          keyIdentityReject,
+			key:            "granite_v1",
+			content:        `[{"modelName":"mnist"}]`,
+			expectedSC:     http.StatusCreated,
+			expectUpserted: true,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:          map[string]*ImportLocation{},
+			modelcards:       map[string]modelCardMetadata{},
+			keyIdentityCheck: tc.check,
+		}
+		data, err := json.Marshal(rest.PostBody{Body: []byte(tc.content)})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + tc.key}, Body: io.NopCloser(bytes.NewReader(data))}
+
+		ils.handleCatalogUpsertPost(ctx)
+
+		if ctx.Writer.Status() != tc.expectedSC {
+			t.Errorf("%s: expected status %d but got %d", tc.name, tc.expectedSC, ctx.Writer.Status())
+		}
+		common.AssertEqual(t, tc.expectUpserted, len(ils.content) == 1)
+	}
+}
+
+func TestParseKeyIdentityCheck(t *testing.T) {
+	common.AssertEqual(t, keyIdentityOff, parseKeyIdentityCheck(""))
+	common.AssertEqual(t, keyIdentityReject, parseKeyIdentityCheck(" Reject "))
+	common.AssertEqual(t, keyIdentityWarn, parseKeyIdentityCheck("warn"))
+	common.AssertEqual(t, keyIdentityOff, parseKeyIdentityCheck("strict"))
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+)
+
+const (
+	// latestVersion is the version segment a lookup asks for the latest served version of a model with
+	latestVersion = "latest"
+	// resolvedVersionHeader names the version a lookup of the latest version was served
+	resolvedVersionHeader = "X-Catalog-Version"
+)
+
+// latestOrder is how versions are ordered to resolve the latest version of a model; when empty, latest is not
+// reserved and is looked up as any other version
+type latestOrder string
+
+const (
+	// latestOrderSemver orders versions as semantic versions, tolerating a v prefix and missing minor or patch
+	// numbers; versions that do not parse order before those that do, and lexically among themselves
+	latestOrderSemver latestOrder = "semver"
+	// latestOrderLexical orders versions as strings
+	latestOrderLexical latestOrder = "lexical"
+)
+
+func parseLatestOrder(str string) (latestOrder, error) {
+	switch o := latestOrder(strings.ToLower(strings.TrimSpace(str))); o {
+	case latestOrderSemver, latestOrderLexical, "":
+		return o, nil
+	default:
+		return "", fmt.Errorf("unknown latest version order %q, the orders are %s and %s", str, latestOrderSemver, latestOrderLexical)
+	}
+}
+
+// less returns whether version a orders before version b
+func (o latestOrder) less(a, b string) bool {
+	if o == latestOrderSemver {
+		va, errA := semver.ParseTolerant(a)
+		vb, errB := semver.ParseTolerant(b)
+		switch {
+		case errA == nil && errB == nil && !va.EQ(vb):
+			return va.LT(vb)
+		case errA == nil && errB != nil:
+			return false
+		case errA != nil && errB == nil:
+			return true
+		}
+	}
+	return a < b
+}
+
+// indexVersion indexes the version of the location at uri by its model, or unindexes it when the location has no
+// content, so the latest version of a model is resolved from its own versions rather than by a scan of every
+// location; the index is only kept when the latest version is resolved.  Callers must hold the server lock.
+func (i *ImportLocationServer) indexVersion(uri string, il *ImportLocation) {
+	if len(i.latestOrder) == 0 {
+		return
+	}
+	t := i.uriTemplate.Load()
+	if t == nil {
+		t, _ = parseURITemplate(defaultURITemplate)
+	}
+	model, version, _, ok := t.parse(uri)
+	if !ok {
+		return
+	}
+	if il == nil || il.content == nil {
+		delete(i.versions[model], uri)
+		if len(i.versions[model]) == 0 {
+			delete(i.versions, model)
+		}
+		return
+	}
+	if i.versions == nil {
+		i.versions = map[string]map[string]string{}
+	}
+	if i.versions[model] == nil {
+		i.versions[model] = map[string]string{}
+	}
+	i.versions[model][uri] = version
+}
+
+// reindexVersions rebuilds the version index from the content, as after the content or URI template is replaced;
+// callers must hold the server lock
+func (i *ImportLocationServer) reindexVersions() {
+	if len(i.latestOrder) == 0 {
+		return
+	}
+	i.versions = map[string]map[string]string{}
+	for uri, il := range i.content {
+		i.indexVersion(uri, il)
+	}
+}
+
+// resolveLatestVersion returns the highest version of model, by the configured order, whose location in format has
+// content; removed versions are not considered.  The lock must be held for reading at least.
+func (i *ImportLocationServer) resolveLatestVersion(model string, format types.NormalizerFormat) (string, bool) {
+	latest := ""
+	found := false
+	for uri, version := range i.versions[model] {
+		if _, built := i.buildKeyAndURI(model, version, format); built != uri {
+			continue
+		}
+		if !found || i.latestOrder.less(latest, version) {
+			latest, found = version, true
+		}
+	}
+	return latest, found
+}
+
+// resolveLatest replaces the latest version of a lookup with the version it resolves to, naming it in the response
+// header; it returns false, having responded, when there is no such version
+func (i *ImportLocationServer) resolveLatest(c *gin.Context, model *ModelURI, format types.NormalizerFormat) bool {
+	if i.readThrough != nil {
+		c.Status(http.StatusNotImplemented)
+		c.Error(fmt.Errorf("resolving the latest version is not supported in read-through mode"))
+		return false
+	}
+	if !i.lockForRead(c) {
+		return false
+	}
+	version, ok := i.resolveLatestVersion(model.Model, format)
+	i.lock.RUnlock()
+	if !ok {
+		c.Status(http.StatusNotFound)
+		c.Error(fmt.Errorf("no version of model %s is served", model.Model))
+		return false
+	}
+	model.Version = version
+	c.Header(resolvedVersionHeader, version)
+	return true
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestLatestVersionLookup(t *testing.T) {
+	content := map[string]*ImportLocation{
+		"/mnist/v1.2.0/catalog-info.yaml":  {content: []byte("mnist v1.2.0")},
+		"/mnist/v1.10.0/catalog-info.yaml": {content: []byte("mnist v1.10.0")},
+		"/mnist/v1.9.0/catalog-info.yaml":  {content: []byte("mnist v1.9.0")},
+		// removed, so not the latest
+		"/mnist/v2.0.0/catalog-info.yaml":   {},
+		"/mnist/nightly/catalog-info.yaml":  {content: []byte("mnist nightly")},
+		"/granite/v1/catalog-info.yaml":     {content: []byte("granite v1")},
+		"/granite/v2/catalog-info.yaml":     {},
+		"/granite-ft/v9/catalog-info.yaml":  {content: []byte("granite-ft v9")},
+		"/removed/v1/catalog-info.yaml":     {},
+		"/literal/latest/catalog-info.yaml": {content: []byte("literal latest")},
+	}
+	for _, tc := range []struct {
+		name            string
+		order           latestOrder
+		path            string
+		expectedSC      int
+		expectedVersion string
+		expectedContent string
+	}{
+		{
+			name:            "semver order",
+			order:           latestOrderSemver,
+			path:            "/mnist/latest/catalog-info.yaml",
+			expectedSC:      http.StatusOK,
+			expectedVersion: "v1.10.0",
+			expectedContent: "mnist v1.10.0",
+		},
+		{
+			name:            "lexical order",
+			order:           latestOrderLexical,
+			path:            "/mnist/latest/catalog-info.yaml",
+			expectedSC:      http.StatusOK,
+			expectedVersion: "v1.9.0",
+			expectedContent: "mnist v1.9.0",
+		},
+		{
+			name:            "removed versions excluded, models sharing a prefix ignored",
+			order:           latestOrderSemver,
+			path:            "/granite/latest/catalog-info.yaml",
+			expectedSC:      http.StatusOK,
+			expectedVersion: "v1",
+			expectedContent: "granite v1",
+		},
+		{
+			name:       "no version served",
+			order:      latestOrderSemver,
+			path:       "/removed/latest/catalog-info.yaml",
+			expectedSC: http.StatusNotFound,
+		},
+		{
+			name:       "unknown model",
+			order:      latestOrderLexical,
+			path:       "/llama/latest/catalog-info.yaml",
+			expectedSC: http.StatusNotFound,
+		},
+		{
+			name:            "latest not reserved by default",
+			path:            "/literal/latest/catalog-info.yaml",
+			expectedSC:      http.StatusOK,
+			expectedContent: "literal latest",
+		},
+	} {
+		ils := &ImportLocationServer{content: content, latestOrder: tc.order}
+		ils.reindexVersions()
+		r := gin.New()
+		r.GET("/:model/:version/:format", ils.handleCatalogLookupGet)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		if rec.Code != tc.expectedSC {
+			t.Errorf("%s: expected %d but got %d", tc.name, tc.expectedSC, rec.Code)
+			continue
+		}
+		common.AssertEqual(t, tc.expectedVersion, rec.Header().Get(resolvedVersionHeader))
+		if tc.expectedSC == http.StatusOK {
+			common.AssertEqual(t, tc.expectedContent, rec.Body.String())
+		}
+	}
+}
+
+func TestLatestVersionIndex(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		modelcards:  map[string]modelCardMetadata{},
+		format:      types.CatalogInfoYamlFormat,
+		latestOrder: latestOrderSemver,
+	}
+	r := gin.New()
+	ils.registerRoutes(r, nil)
+	lookup := func() (int, string) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mnist/latest/catalog-info.yaml", nil))
+		return rec.Code, rec.Header().Get(resolvedVersionHeader)
+	}
+
+	upsertForTest(t, ils, "mnist_v1", mnistEntity)
+	upsertForTest(t, ils, "mnist_v2", mnistUpdatedEntity)
+	upsertForTest(t, ils, "granite_v3", graniteEntity)
+	common.AssertEqual(t, map[string]map[string]string{
+		"mnist":   {"/mnist/v1/catalog-info.yaml": "v1", "/mnist/v2/catalog-info.yaml": "v2"},
+		"granite": {"/granite/v3/catalog-info.yaml": "v3"},
+	}, ils.versions)
+	sc, version := lookup()
+	common.AssertEqual(t, http.StatusOK, sc)
+	common.AssertEqual(t, "v2", version)
+
+	// removals are unindexed
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, util.RemoveURI+"?key=mnist_v2", nil))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	_, version = lookup()
+	common.AssertEqual(t, "v1", version)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, util.RemoveURI+"?key=mnist_v1", nil))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	sc, _ = lookup()
+	common.AssertEqual(t, http.StatusNotFound, sc)
+	_, ok := ils.versions["mnist"]
+	common.AssertEqual(t, false, ok)
+
+	// not kept unless the latest version is resolved
+	ils = &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}}
+	upsertForTest(t, ils, "mnist_v1", mnistEntity)
+	common.AssertEqual(t, 0, len(ils.versions))
+}
+
+func TestLatestOrder(t *testing.T) {
+	for _, tc := range []struct {
+		order    latestOrder
+		a, b     string
+		expected bool
+	}{
+		{order: latestOrderSemver, a: "v1.9.0", b: "v1.10.0", expected: true},
+		{order: latestOrderSemver, a: "1.2", b: "v1.1.9", expected: false},
+		{order: latestOrderSemver, a: "nightly", b: "v0.0.1", expected: true},
+		{order: latestOrderSemver, a: "v1.0.0-rc1", b: "v1.0.0", expected: true},
+		{order: latestOrderLexical, a: "v1.9.0", b: "v1.10.0", expected: false},
+	} {
+		common.AssertEqual(t, tc.expected, tc.order.less(tc.a, tc.b))
+	}
+
+	order, err := parseLatestOrder(" SemVer ")
+	common.AssertError(t, err)
+	common.AssertEqual(t, latestOrderSemver, order)
+	if _, err = parseLatestOrder("newest"); err == nil {
+		t.Errorf("expected an unknown order to be rejected")
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+// legacyKeyWarning is the Warning header, per RFC 7234, returned for requests using the legacy key format; 299 is
+// the code for a persistent miscellaneous warning
+const legacyKeyWarning = `299 - "the <model>_<version> key format is deprecated, append the source and normalizer segments to the key"`
+
+// isLegacyKey reports whether a key has only the model and version segments of the legacy key format
+func isLegacyKey(key string) bool {
+	return strings.Count(key, "_") == 1
+}
+
+// warnLegacyKey logs, and warns the client with a Warning header, that the key of an upsert or delete uses the
+// deprecated legacy key format, when legacy key warnings are enabled; the request is processed as usual
+func (i *ImportLocationServer) warnLegacyKey(c *gin.Context, key string) {
+	if !i.legacyKeyWarning || !isLegacyKey(key) {
+		return
+	}
+	klog.Warningf("%s %s used the deprecated legacy key format for key %s", c.Request.Method, c.Request.URL.Path, key)
+	c.Header("Warning", legacyKeyWarning)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+)
+
+func TestWarnLegacyKey(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		method          string
+		key             string
+		enabled         bool
+		expectedWarning string
+	}{
+		{
+			name:            "legacy key upsert",
+			method:          http.MethodPost,
+			key:             "mnist_v1",
+			enabled:         true,
+			expectedWarning: legacyKeyWarning,
+		},
+		{
+			name:            "legacy key delete",
+			method:          http.MethodDelete,
+			key:             "mnist_v1",
+			enabled:         true,
+			expectedWarning: legacyKeyWarning,
+		},
+		{
+			name:    "new format key upsert",
+			method:  http.MethodPost,
+			key:     "mnist_v1_rhoai_kfmr",
+			enabled: true,
+		},
+		{
+			name:    "new format key delete",
+			method:  http.MethodDelete,
+			key:     "mnist_v1_rhoai_kfmr",
+			enabled: true,
+		},
+		{
+			name:   "legacy key upsert with warnings disabled",
+			method: http.MethodPost,
+			key:    "mnist_v1",
+		},
+		{
+			name:   "legacy key delete with warnings disabled",
+			method: http.MethodDelete,
+			key:    "mnist_v1",
+		},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, eng := gin.CreateTestContext(testWriter)
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, legacyKeyWarning: tc.enabled}
+		ils.router = eng
+
+		if tc.method == http.MethodPost {
+			ctx.Request, _ = http.NewRequest(tc.method, util.UpsertURI+"?key="+tc.key, bytes.NewBufferString(`{"body":"YXBpVmVyc2lvbjogdjE="}`))
+			ils.handleCatalogUpsertPost(ctx)
+		} else {
+			ctx.Request, _ = http.NewRequest(tc.method, util.RemoveURI+"?key="+tc.key, nil)
+			ils.handleCatalogDelete(ctx)
+		}
+
+		// the request is processed as usual either way
+		if ctx.Writer.Status() != http.StatusOK && ctx.Writer.Status() != http.StatusCreated {
+			t.Errorf("%s: unexpected status %d: %v", tc.name, ctx.Writer.Status(), ctx.Errors)
+		}
+		common.AssertEqual(t, tc.expectedWarning, ctx.Writer.Header().Get("Warning"))
+	}
+}
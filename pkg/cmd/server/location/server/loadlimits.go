@@ -0,0 +1,38 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+)
+
+// loadLimits bounds the entries loaded from storage, so a corrupt or malicious entry with an enormous key or body is
+// skipped rather than held; a limit of 0 is unbounded
+type loadLimits struct {
+	// maxKeyLength bounds the storage key of an entry and the model card key stored with it
+	maxKeyLength int
+	// maxContentBytes bounds the content of an entry and the model card stored with it
+	maxContentBytes int
+}
+
+// checkKey returns an error when a storage key is longer than the limit, so its entry is not fetched
+func (l loadLimits) checkKey(key string) error {
+	if l.maxKeyLength > 0 && len(key) > l.maxKeyLength {
+		return fmt.Errorf("skipping the storage key of length %d, over the limit of %d: %.64s...", len(key), l.maxKeyLength, key)
+	}
+	return nil
+}
+
+// checkBody returns an error when the content, model card key or model card fetched for key exceed the limits
+func (l loadLimits) checkBody(key string, sb *types.StorageBody) error {
+	if l.maxKeyLength > 0 && len(sb.ModelCardKey) > l.maxKeyLength {
+		return fmt.Errorf("skipping storage key %s, whose model card key of length %d is over the limit of %d", key, len(sb.ModelCardKey), l.maxKeyLength)
+	}
+	if l.maxContentBytes > 0 && len(sb.Body) > l.maxContentBytes {
+		return fmt.Errorf("skipping storage key %s, whose content of %d bytes is over the limit of %d", key, len(sb.Body), l.maxContentBytes)
+	}
+	if l.maxContentBytes > 0 && len(sb.ModelCard) > l.maxContentBytes {
+		return fmt.Errorf("skipping storage key %s, whose model card of %d bytes is over the limit of %d", key, len(sb.ModelCard), l.maxContentBytes)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+)
+
+func TestLoadLimits(t *testing.T) {
+	newStorage := func() *stubstorage.StubStorageClient {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{
+			"mnist_v1":                             []byte(mnistEntity),
+			"granite_v1":                           []byte(graniteEntity),
+			"fraud_v1":                             []byte(strings.Repeat("x", 2048)),
+			strings.Repeat("k", 200) + "_v1":       []byte("oversized key"),
+			"iris_v1":                              []byte("iris"),
+			"bert_v1":                              []byte("bert"),
+			"resnet_" + strings.Repeat("9", 10000): []byte("oversized version"),
+		})
+		st.SetModelCard("iris_v1", strings.Repeat("c", 200), "# iris", "1")
+		st.SetModelCard("bert_v1", "bert-card", strings.Repeat("#", 2048), "1")
+		st.SetModelCard("mnist_v1", "mnist-card", "# mnist", "1")
+		return st
+	}
+	for _, tc := range []struct {
+		name         string
+		limits       loadLimits
+		expectedURIs []string
+	}{
+		{
+			name:   "unbounded by default",
+			limits: loadLimits{},
+			expectedURIs: []string{"/bert/v1/catalog-info.yaml", "/fraud/v1/catalog-info.yaml", "/granite/v1/catalog-info.yaml",
+				"/iris/v1/catalog-info.yaml", "/" + strings.Repeat("k", 200) + "/v1/catalog-info.yaml", "/mnist/v1/catalog-info.yaml",
+				"/resnet/" + strings.Repeat("9", 10000) + "/catalog-info.yaml"},
+		},
+		{
+			name:         "oversized keys, model card keys, content and model cards skipped",
+			limits:       loadLimits{maxKeyLength: 128, maxContentBytes: 1024},
+			expectedURIs: []string{"/granite/v1/catalog-info.yaml", "/mnist/v1/catalog-info.yaml"},
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:    map[string]*ImportLocation{},
+			modelcards: map[string]modelCardMetadata{},
+			storage:    newStorage(),
+			loadLimits: tc.limits,
+		}
+		loaded, err := ils.loadFromStorage()
+		common.AssertError(t, err)
+		// skipped entries are not failed fetches
+		common.AssertEqual(t, true, loaded)
+		uris := []string{}
+		for uri := range ils.content {
+			uris = append(uris, uri)
+		}
+		sort.Strings(uris)
+		sort.Strings(tc.expectedURIs)
+		common.AssertEqual(t, tc.expectedURIs, uris)
+	}
+
+	// a key over the limit is not even fetched
+	st := newStorage()
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{},
+		storage:    st,
+		loadLimits: loadLimits{maxKeyLength: 128},
+	}
+	_, err := ils.loadFromStorage()
+	common.AssertError(t, err)
+	common.AssertEqual(t, 0, st.FetchCount(strings.Repeat("k", 200)+"_v1"))
+	common.AssertEqual(t, 1, st.FetchCount("mnist_v1"))
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rlockWithin acquires the server lock for reading, waiting at most timeout, or indefinitely when timeout is not
+// positive.  A wait abandoned at the timeout releases the lock as soon as it is acquired, so the lock is never left
+// held.
+func (i *ImportLocationServer) rlockWithin(timeout time.Duration) bool {
+	if timeout <= 0 {
+		i.lock.RLock()
+		return true
+	}
+	if i.lock.TryRLock() {
+		return true
+	}
+	acquired := make(chan struct{})
+	abandoned := make(chan struct{})
+	go func() {
+		i.lock.RLock()
+		select {
+		case acquired <- struct{}{}:
+		case <-abandoned:
+			i.lock.RUnlock()
+		}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-acquired:
+		return true
+	case <-timer.C:
+		close(abandoned)
+		return false
+	}
+}
+
+// lockForRead acquires the server lock for reading for a lookup, discovery or model card request, to be released
+// with RUnlock, failing the request with a 503 and a Retry-After of the read lock timeout, rounded up to a second,
+// when it is not acquired within the timeout, so clients back off rather than pile up behind a reload holding the
+// lock
+func (i *ImportLocationServer) lockForRead(c *gin.Context) bool {
+	if i.rlockWithin(i.readLockTimeout) {
+		return true
+	}
+	readLockTimeouts.Inc()
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(i.readLockTimeout.Seconds()))))
+	c.Status(http.StatusServiceUnavailable)
+	c.Error(fmt.Errorf("the server is busy, the request waited over %s for the catalog", i.readLockTimeout.String()))
+	return false
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+)
+
+func TestReadLockTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		timeout    time.Duration
+		handler    func(ils *ImportLocationServer, c *gin.Context)
+		expectedSC int
+	}{
+		{
+			name:       "lookup waits by default",
+			handler:    (*ImportLocationServer).handleCatalogLookupGet,
+			expectedSC: http.StatusOK,
+		},
+		{
+			name:       "lookup times out",
+			timeout:    20 * time.Millisecond,
+			handler:    (*ImportLocationServer).handleCatalogLookupGet,
+			expectedSC: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "discovery times out",
+			timeout:    20 * time.Millisecond,
+			handler:    (*ImportLocationServer).handleCatalogDiscoveryGet,
+			expectedSC: http.StatusServiceUnavailable,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:         map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte("kind: Component\n")}},
+			modelcards:      map[string]modelCardMetadata{},
+			format:          types.CatalogInfoYamlFormat,
+			readLockTimeout: tc.timeout,
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{}, Header: http.Header{}}
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: catalogInfoFileName}}
+
+		// a reload holding the lock for longer than the timeout
+		ils.lock.Lock()
+		released := make(chan struct{})
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			ils.lock.Unlock()
+			close(released)
+		}()
+		start := time.Now()
+		tc.handler(ils, ctx)
+		waited := time.Since(start)
+
+		if ctx.Writer.Status() != tc.expectedSC {
+			t.Errorf("%s: expected status %d but got %d", tc.name, tc.expectedSC, ctx.Writer.Status())
+		}
+		if tc.expectedSC == http.StatusServiceUnavailable {
+			common.AssertEqual(t, "1", testWriter.Header().Get("Retry-After"))
+			common.AssertEqual(t, true, waited < 200*time.Millisecond)
+		}
+		<-released
+		// the abandoned wait does not leave the lock held
+		deadline := time.Now().Add(5 * time.Second)
+		for !ils.lock.TryLock() {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: the lock was left held", tc.name)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		ils.lock.Unlock()
+	}
+}
+
+// TestReadsShareLock asserts reads are served alongside another reader holding the lock, rather than one at a time
+func TestReadsShareLock(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:         map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte("kind: Component\n")}},
+		modelcards:      map[string]modelCardMetadata{"mnist-card": newModelCardMetadata("mnist-card", "# mnist", "1")},
+		format:          types.CatalogInfoYamlFormat,
+		readLockTimeout: 20 * time.Millisecond,
+	}
+	r := gin.New()
+	ils.registerRoutes(r, nil)
+	ils.lock.RLock()
+	defer ils.lock.RUnlock()
+	for _, path := range []string{"/mnist/v1/catalog-info.yaml", util.ListURI, util.ListURI + "?detailed=true", util.ModelCardURI + "?key=mnist-card"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected %s to be served alongside another reader but got %d", path, rec.Code)
+		}
+	}
+}
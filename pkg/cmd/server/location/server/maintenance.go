@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+const defaultMaintenanceMessage = "the catalog is read-only for maintenance, retry later"
+
+// maintenanceWindow is a period during which the catalog is read-only, as while storage is maintained
+type maintenanceWindow struct {
+	message string
+	since   time.Time
+}
+
+type MaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+}
+
+type MaintenanceResponse struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+	Since   string `json:"since,omitempty"`
+}
+
+// rejectDuringMaintenance fails writes with a 503 and the maintenance message while a maintenance window is open,
+// so clients retry them once the window is closed, while reads are served as usual
+func (i *ImportLocationServer) rejectDuringMaintenance() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := i.maintenance.Load()
+		if w == nil {
+			c.Next()
+			return
+		}
+		c.Abort()
+		c.Error(fmt.Errorf("%s rejected during maintenance: %s", c.Request.URL.Path, w.message))
+		c.String(http.StatusServiceUnavailable, w.message)
+	}
+}
+
+// handleMaintenancePost opens or closes the maintenance window, making the catalog read-only, or writable again,
+// at runtime; the window is not persisted, so a restart ends it
+func (i *ImportLocationServer) handleMaintenancePost(c *gin.Context) {
+	req := MaintenanceRequest{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	resp := MaintenanceResponse{Enabled: req.Enabled}
+	if req.Enabled {
+		w := &maintenanceWindow{message: req.Message, since: time.Now()}
+		if len(w.message) == 0 {
+			w.message = defaultMaintenanceMessage
+		}
+		i.maintenance.Store(w)
+		klog.Infof("maintenance window opened, writes are rejected: %s", w.message)
+		resp.Message, resp.Since = w.message, w.since.UTC().Format(time.RFC3339)
+	} else if i.maintenance.Swap(nil) != nil {
+		klog.Info("maintenance window closed, writes are accepted")
+	}
+	content, err := json.Marshal(&resp)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
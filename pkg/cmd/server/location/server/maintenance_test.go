@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity)}},
+		modelcards: map[string]modelCardMetadata{},
+		adminToken: "admin-token",
+	}
+	r := newBareRouter()
+	ils.registerRoutes(r, nil)
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if len(token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	upsert := func() int {
+		return serve(http.MethodPost, util.UpsertURI+"?key=granite_v1", `{"body":"`+base64.StdEncoding.EncodeToString([]byte(graniteEntity))+`"}`, "").Code
+	}
+
+	// toggling maintenance requires the admin token
+	common.AssertEqual(t, http.StatusUnauthorized, serve(http.MethodPost, util.MaintenanceURI, `{"enabled":true}`, "").Code)
+
+	rec := serve(http.MethodPost, util.MaintenanceURI, `{"enabled":true,"message":"storage upgrade until 10:00"}`, "admin-token")
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	resp := &MaintenanceResponse{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+	common.AssertEqual(t, true, resp.Enabled)
+	common.AssertEqual(t, "storage upgrade until 10:00", resp.Message)
+	common.AssertEqual(t, true, len(resp.Since) > 0)
+
+	// writes are rejected with the maintenance message, while reads are served
+	common.AssertEqual(t, http.StatusServiceUnavailable, upsert())
+	rec = serve(http.MethodDelete, util.RemoveURI+"?key=mnist_v1", "", "")
+	common.AssertEqual(t, http.StatusServiceUnavailable, rec.Code)
+	common.AssertEqual(t, "storage upgrade until 10:00", rec.Body.String())
+	common.AssertEqual(t, http.StatusServiceUnavailable, serve(http.MethodPost, util.UpsertModelCardURI+"?key=mnist-card", `{}`, "").Code)
+	common.AssertEqual(t, http.StatusServiceUnavailable, serve(http.MethodPost, util.UpsertBulkURI, `{"items":[]}`, "").Code)
+	common.AssertEqual(t, http.StatusOK, serve(http.MethodGet, "/mnist/v1/catalog-info.yaml", "", "").Code)
+	common.AssertEqual(t, http.StatusOK, serve(http.MethodGet, util.ListURI, "", "").Code)
+	_, ok := ils.content["/granite/v1/catalog-info.yaml"]
+	common.AssertEqual(t, false, ok)
+
+	// writes resume once the window is closed
+	rec = serve(http.MethodPost, util.MaintenanceURI, `{"enabled":false}`, "admin-token")
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, `{"enabled":false}`, rec.Body.String())
+	common.AssertEqual(t, http.StatusCreated, upsert())
+	common.AssertEqual(t, http.StatusOK, serve(http.MethodDelete, util.RemoveURI+"?key=mnist_v1", "", "").Code)
+	common.AssertEqual(t, http.StatusOK, serve(http.MethodGet, "/granite/v1/catalog-info.yaml", "", "").Code)
+
+	// without a message the default is served
+	serve(http.MethodPost, util.MaintenanceURI, `{"enabled":true}`, "admin-token")
+	rec = serve(http.MethodDelete, util.RemoveURI+"?key=granite_v1", "", "")
+	common.AssertEqual(t, defaultMaintenanceMessage, rec.Body.String())
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+type ManifestEntry struct {
+	URI      string `json:"uri"`
+	Checksum string `json:"checksum"`
+}
+
+// ManifestResponse lists the checksum of the content of each URI, sorted by URI, along with a checksum over all
+// the entries, so clients can verify some or all of the catalog
+type ManifestResponse struct {
+	Prefix   string          `json:"prefix,omitempty"`
+	Entries  []ManifestEntry `json:"entries"`
+	Checksum string          `json:"checksum"`
+}
+
+// manifestChecksum is the checksum over the sorted entries, so the subtotal for a prefix can be recomputed from the
+// matching entries of the full manifest
+func manifestChecksum(h contentHash, entries []ManifestEntry) string {
+	b := strings.Builder{}
+	for _, e := range entries {
+		b.WriteString(e.URI)
+		b.WriteString(" ")
+		b.WriteString(e.Checksum)
+		b.WriteString("\n")
+	}
+	return h.sum([]byte(b.String()))
+}
+
+func (i *ImportLocationServer) handleManifestGet(c *gin.Context) {
+	prefix := c.Query(util.PrefixQueryParam)
+	m := &ManifestResponse{Prefix: prefix, Entries: []ManifestEntry{}}
+	i.lock.RLock()
+	for uri, il := range i.content {
+		if il.content == nil || !strings.HasPrefix(uri, prefix) {
+			continue
+		}
+		m.Entries = append(m.Entries, ManifestEntry{URI: uri, Checksum: i.checksum(i.plaintext(il.content))})
+	}
+	i.lock.RUnlock()
+	sort.Slice(m.Entries, func(a, b int) bool {
+		return m.Entries[a].URI < m.Entries[b].URI
+	})
+	m.Checksum = manifestChecksum(i.contentHash, m.Entries)
+	content, err := json.Marshal(m)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleManifestGet(t *testing.T) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":   {content: []byte(mnistEntity)},
+			"/mnist/v2/catalog-info.yaml":   {content: []byte(mnistUpdatedEntity)},
+			"/mnist/v3/catalog-info.yaml":   {},
+			"/granite/v1/catalog-info.yaml": {content: []byte(graniteEntity)},
+		},
+	}
+	getManifest := func(query string) ManifestResponse {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request, _ = http.NewRequest(http.MethodGet, "/manifest?"+query, nil)
+		ils.handleManifestGet(ctx)
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		m := ManifestResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &m))
+		return m
+	}
+	full := getManifest("")
+	common.AssertEqual(t, []ManifestEntry{
+		{URI: "/granite/v1/catalog-info.yaml", Checksum: hashSHA256.sum([]byte(graniteEntity))},
+		{URI: "/mnist/v1/catalog-info.yaml", Checksum: hashSHA256.sum([]byte(mnistEntity))},
+		{URI: "/mnist/v2/catalog-info.yaml", Checksum: hashSHA256.sum([]byte(mnistUpdatedEntity))},
+	}, full.Entries)
+	common.AssertEqual(t, manifestChecksum(hashSHA256, full.Entries), full.Checksum)
+
+	for _, tc := range []struct {
+		prefix      string
+		expectedLen int
+	}{
+		{prefix: "/mnist/", expectedLen: 2},
+		{prefix: "/granite/v1", expectedLen: 1},
+		{prefix: "/bert/", expectedLen: 0},
+	} {
+		m := getManifest("prefix=" + tc.prefix)
+		common.AssertEqual(t, tc.prefix, m.Prefix)
+		common.AssertEqual(t, tc.expectedLen, len(m.Entries))
+		// the scoped manifest is the matching slice of the full manifest, with a subtotal over just that slice
+		subset := []ManifestEntry{}
+		for _, e := range full.Entries {
+			if strings.HasPrefix(e.URI, tc.prefix) {
+				subset = append(subset, e)
+			}
+		}
+		common.AssertEqual(t, subset, m.Entries)
+		common.AssertEqual(t, manifestChecksum(hashSHA256, subset), m.Checksum)
+		common.AssertEqual(t, false, m.Checksum == full.Checksum)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+const defaultMemorySampleInterval = 5 * time.Second
+
+// heapSampler returns the number of bytes of heap in use
+type heapSampler func() uint64
+
+func runtimeHeapInUse() uint64 {
+	m := runtime.MemStats{}
+	runtime.ReadMemStats(&m)
+	return m.HeapInuse
+}
+
+// memoryGuard rejects upserts while the heap in use, as last sampled, is above a threshold, so a flood of large
+// upserts does not get the service OOM killed; reads are served regardless.  Sampling periodically, rather than per
+// request, keeps runtime.ReadMemStats, which stops the world, off the request path.
+type memoryGuard struct {
+	threshold uint64
+	interval  time.Duration
+	sample    heapSampler
+	heap      atomic.Uint64
+	rejecting atomic.Bool
+}
+
+func newMemoryGuard(threshold uint64, interval time.Duration, sample heapSampler) *memoryGuard {
+	g := &memoryGuard{threshold: threshold, interval: interval, sample: sample}
+	g.refresh()
+	return g
+}
+
+// refresh samples the heap in use, logging when the guard starts and stops rejecting upserts
+func (g *memoryGuard) refresh() {
+	heap := g.sample()
+	g.heap.Store(heap)
+	over := heap > g.threshold
+	if g.rejecting.Swap(over) != over {
+		if over {
+			klog.Warningf("heap in use of %d bytes is above the threshold of %d bytes, rejecting upserts", heap, g.threshold)
+		} else {
+			klog.Infof("heap in use of %d bytes is back under the threshold of %d bytes, accepting upserts", heap, g.threshold)
+		}
+	}
+}
+
+// run samples the heap every interval until stopCh is closed
+func (g *memoryGuard) run(stopCh <-chan struct{}) {
+	if g == nil {
+		return
+	}
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			g.refresh()
+		}
+	}
+}
+
+// check returns an error if the last sampled heap in use is above the threshold; a nil guard accepts everything
+func (g *memoryGuard) check() error {
+	if g == nil {
+		return nil
+	}
+	if heap := g.heap.Load(); heap > g.threshold {
+		return fmt.Errorf("heap in use of %d bytes is above the threshold of %d bytes", heap, g.threshold)
+	}
+	return nil
+}
+
+// rejectUnderPressure responds with a 503, asking the client to retry after the next sample, and returns true
+// when memory pressure is high
+func (g *memoryGuard) rejectUnderPressure(c *gin.Context) bool {
+	err := g.check()
+	if err == nil {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(g.interval.Seconds()))))
+	c.Status(http.StatusServiceUnavailable)
+	c.Error(fmt.Errorf("rejecting upsert: %s", err.Error()))
+	return true
+}
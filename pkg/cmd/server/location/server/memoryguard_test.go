@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestMemoryGuardRejectsUpserts(t *testing.T) {
+	heap := atomic.Uint64{}
+	heap.Store(10)
+	guard := newMemoryGuard(100, 2*time.Second, func() uint64 { return heap.Load() })
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity)}},
+		modelcards:  map[string]modelCardMetadata{},
+		memoryGuard: guard,
+	}
+	upsert := func(key string) (int, http.Header) {
+		data, err := json.Marshal(rest.PostBody{Body: []byte(mnistEntity)})
+		common.AssertError(t, err)
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + key}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		return ctx.Writer.Status(), testWriter.Header()
+	}
+	lookup := func() int {
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: catalogInfoFileName}}
+		ils.handleCatalogLookupGet(ctx)
+		return ctx.Writer.Status()
+	}
+
+	sc, _ := upsert("mnist_v2")
+	common.AssertEqual(t, http.StatusCreated, sc)
+
+	// crossing the threshold is only seen once sampled
+	heap.Store(200)
+	sc, _ = upsert("mnist_v3")
+	common.AssertEqual(t, http.StatusCreated, sc)
+	guard.refresh()
+	sc, header := upsert("mnist_v4")
+	common.AssertEqual(t, http.StatusServiceUnavailable, sc)
+	common.AssertEqual(t, "2", header.Get("Retry-After"))
+	_, ok := ils.content["/mnist/v4/catalog-info.yaml"]
+	common.AssertEqual(t, false, ok)
+
+	// model card upserts are rejected too, while reads are served
+	data, err := json.Marshal(rest.PostBody{ModelCardKey: "mnist-card", ModelCard: "# mnist"})
+	common.AssertError(t, err)
+	ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+	ils.handleModelCardUpsertPost(ctx)
+	common.AssertEqual(t, http.StatusServiceUnavailable, ctx.Writer.Status())
+	common.AssertEqual(t, http.StatusOK, lookup())
+
+	// upserts are accepted again once back under the threshold
+	heap.Store(50)
+	guard.refresh()
+	sc, _ = upsert("mnist_v4")
+	common.AssertEqual(t, http.StatusCreated, sc)
+}
+
+func TestMemoryGuardRun(t *testing.T) {
+	heap := atomic.Uint64{}
+	guard := newMemoryGuard(100, time.Millisecond, func() uint64 { return heap.Load() })
+	common.AssertError(t, guard.check())
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go guard.run(stopCh)
+
+	heap.Store(200)
+	deadline := time.Now().Add(5 * time.Second)
+	for guard.check() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	common.AssertEqual(t, true, guard.check() != nil)
+
+	// without a guard nothing is rejected
+	var none *memoryGuard
+	common.AssertError(t, none.check())
+}
+
+// countingReader counts the bytes read from it
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.read += n
+	return n, err
+}
+
+func TestMemoryGuardRejectsBeforeReadingBody(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		modelcards:  map[string]modelCardMetadata{},
+		memoryGuard: newMemoryGuard(100, time.Second, func() uint64 { return 200 }),
+		deadLetters: newDeadLetterWriter(io.Discard),
+	}
+	body := &countingReader{r: bytes.NewReader(bytes.Repeat([]byte("x"), 1<<20))}
+	ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(body)}
+
+	ils.handleCatalogUpsertPost(ctx)
+
+	common.AssertEqual(t, http.StatusServiceUnavailable, ctx.Writer.Status())
+	// neither the handler nor the dead letter capture read the rejected body
+	common.AssertEqual(t, 0, body.read)
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsNamespace = "model_catalog_bridge"
+	metricsSubsystem = "location"
+)
+
+// contentSizeBuckets bound the content size histograms, from 256 bytes to 4MiB by powers of 4
+var contentSizeBuckets = prometheus.ExponentialBuckets(256, 4, 8)
+
+var (
+	storageFetchesInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "storage_fetches_in_flight",
+		Help:      "The number of fetches from the storage service currently in progress.",
+	})
+	storageKeysQuarantined = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "storage_keys_quarantined",
+		Help:      "The number of storage keys whose fetches are backed off after repeatedly failing.",
+	})
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "http_requests_total",
+		Help:      "The number of HTTP requests handled, by route, method and status code.",
+	}, []string{"route", "method", "code"})
+	httpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "http_requests_in_flight",
+		Help:      "The number of HTTP requests currently being handled.",
+	})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "http_request_duration_seconds",
+		Help:      "The latency of HTTP requests, by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	httpSourceRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "http_source_requests_total",
+		Help:      "The number of HTTP requests handled for a source, by source, route, method and status code.",
+	}, []string{"source", "route", "method", "code"})
+	readLockTimeouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "read_lock_timeouts_total",
+		Help:      "The number of requests failed with a 503 as they waited over the read lock timeout for the catalog.",
+	})
+	slowClientAborts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "slow_client_aborts_total",
+		Help:      "The number of responses aborted as the client read them slower than the response write timeout.",
+	})
+	eventSubscribers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "event_subscribers",
+		Help:      "The number of clients currently subscribed to the location event stream.",
+	})
+	upsertedContentSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "upserted_content_size_bytes",
+		Help:      "The size of the catalog-info content upserted, by format.",
+		Buckets:   contentSizeBuckets,
+	}, []string{"format"})
+	servedContentSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "served_content_size_bytes",
+		Help:      "The size of the catalog-info content served by lookups, by format.",
+		Buckets:   contentSizeBuckets,
+	}, []string{"format"})
+	modelCardsServed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "model_cards_served_total",
+		Help:      "The number of fetches of each model card held that were answered with its content, not as not modified, by model card key; the series of a card is deleted when the location of its key is removed or swept, or the card is replaced by another.",
+	}, []string{"key"})
+	catalogSourceLocations = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "catalog_source_locations"),
+		"The number of locations served, by the source that provided them.", []string{"source"}, nil)
+)
+
+func init() {
+	prometheus.MustRegister(storageFetchesInFlight, storageKeysQuarantined, httpRequestsTotal, httpRequestsInFlight, httpRequestDuration,
+		httpSourceRequestsTotal, readLockTimeouts, slowClientAborts, eventSubscribers, upsertedContentSize, servedContentSize,
+		modelCardsServed)
+}
+
+// otherSource labels the source metrics of sources not discovered, and of locations whose source is not known
+const otherSource = "other"
+
+// sourceLabels are the sources the source metrics are labeled with, bounding their cardinality; other sources are
+// labeled as otherSource
+type sourceLabels map[string]bool
+
+func newSourceLabels(sources []string) sourceLabels {
+	labels := sourceLabels{}
+	for _, source := range sources {
+		labels[source] = true
+	}
+	return labels
+}
+
+func (s sourceLabels) label(source string) string {
+	if s[source] {
+		return source
+	}
+	return otherSource
+}
+
+// sourceMetrics counts requests by their source, as returned by sourceOf; requests that are not made for a source,
+// such as those to /healthz, are not counted
+func sourceMetrics(sourceOf func(c *gin.Context) string, labels sourceLabels) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// taken before the request is handled, as a removal drops the location its source is taken from
+		source := sourceOf(c)
+		route := c.FullPath()
+		c.Next()
+		first, _, _ := strings.Cut(strings.TrimPrefix(route, "/"), "/")
+		// the source taken from a route, as with /kserve/list, is only a source when it is labeled
+		if len(source) == 0 || (source == first && !labels[source]) {
+			return
+		}
+		httpSourceRequestsTotal.WithLabelValues(labels.label(source), route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}
+
+// catalogSourceCollector reports the number of locations served by each source as they are scraped
+type catalogSourceCollector struct {
+	server *ImportLocationServer
+	labels sourceLabels
+}
+
+func (cc *catalogSourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- catalogSourceLocations
+}
+
+func (cc *catalogSourceCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := map[string]int{otherSource: 0}
+	for source := range cc.labels {
+		counts[source] = 0
+	}
+	cc.server.lock.RLock()
+	for _, il := range cc.server.content {
+		if il.content != nil {
+			counts[cc.labels.label(il.source)]++
+		}
+	}
+	cc.server.lock.RUnlock()
+	for source, n := range counts {
+		ch <- prometheus.MustNewConstMetric(catalogSourceLocations, prometheus.GaugeValue, float64(n), source)
+	}
+}
+
+// requestsInFlight mirrors the http_requests_in_flight gauge, which cannot be read back, for /metrics/inflight
+var requestsInFlight atomic.Int64
+
+// handleInFlightGet returns the number of requests in flight as a plain integer, for autoscalers that scale on
+// concurrency without scraping Prometheus; the request itself is not counted
+func handleInFlightGet(c *gin.Context) {
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(strconv.FormatInt(requestsInFlight.Load(), 10)))
+}
+
+// metricsHandler serves the metrics, in the OpenMetrics format when negotiated and exemplars are enabled, as the
+// Prometheus text format cannot carry exemplars
+func metricsHandler(exemplars bool) http.Handler {
+	if !exemplars {
+		return promhttp.Handler()
+	}
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+}
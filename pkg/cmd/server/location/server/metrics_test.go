@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestSourceMetrics(t *testing.T) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":  {content: []byte("mnist"), source: types.KServeNormalizer},
+			"/fraud/v1/catalog-info.yaml":  {content: []byte("fraud"), source: types.KubeflowNormalizer},
+			"/custom/v1/catalog-info.yaml": {content: []byte("custom"), source: "custom"},
+			"/iris/v1/catalog-info.yaml":   {content: []byte("iris")},
+			"/gone/v1/catalog-info.yaml":   {source: types.KServeNormalizer},
+		},
+		format: types.CatalogInfoYamlFormat,
+	}
+	labels := newSourceLabels([]string{types.KServeNormalizer, types.KubeflowNormalizer})
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.Use(sourceMetrics(ils.requestSource, labels))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/:model/:version/:format", ok)
+	r.POST(util.UpsertURI, ok)
+	r.GET("/"+types.KServeNormalizer+util.ListURI, ok)
+	r.GET(util.HealthzURI, ok)
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		path   string
+		source string
+		route  string
+	}{
+		{name: "kserve lookup", method: http.MethodGet, path: "/mnist/v1/catalog-info.yaml", source: types.KServeNormalizer, route: "/:model/:version/:format"},
+		{name: "kubeflow lookup", method: http.MethodGet, path: "/fraud/v1/catalog-info.yaml", source: types.KubeflowNormalizer, route: "/:model/:version/:format"},
+		{name: "unexpected source lookup", method: http.MethodGet, path: "/custom/v1/catalog-info.yaml", source: otherSource, route: "/:model/:version/:format"},
+		{name: "kubeflow upsert", method: http.MethodPost, path: util.UpsertURI + "?key=new_v1&type=" + types.KubeflowNormalizer, source: types.KubeflowNormalizer, route: util.UpsertURI},
+		{name: "unexpected source upsert", method: http.MethodPost, path: util.UpsertURI + "?key=new_v1&type=custom", source: otherSource, route: util.UpsertURI},
+		{name: "source discovery", method: http.MethodGet, path: "/" + types.KServeNormalizer + util.ListURI, source: types.KServeNormalizer, route: "/" + types.KServeNormalizer + util.ListURI},
+	} {
+		counter := httpSourceRequestsTotal.WithLabelValues(tc.source, tc.route, tc.method, "200")
+		before := counterValue(t, counter)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tc.method, tc.path, nil))
+		if after := counterValue(t, counter); after != before+1 {
+			t.Errorf("%s: expected the %s count to be %v but got %v", tc.name, tc.source, before+1, after)
+		}
+	}
+
+	// requests not made for a source are not counted by source
+	healthz := httpSourceRequestsTotal.WithLabelValues(otherSource, util.HealthzURI, http.MethodGet, "200")
+	before := counterValue(t, healthz)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, util.HealthzURI, nil))
+	common.AssertEqual(t, before, counterValue(t, healthz))
+
+	reg := prometheus.NewPedanticRegistry()
+	common.AssertError(t, reg.Register(&catalogSourceCollector{server: ils, labels: labels}))
+	families, err := reg.Gather()
+	common.AssertError(t, err)
+	common.AssertEqual(t, 1, len(families))
+	locations := map[string]float64{}
+	for _, m := range families[0].GetMetric() {
+		locations[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+	}
+	// removed locations are not counted, and those of unknown source are other
+	common.AssertEqual(t, map[string]float64{types.KServeNormalizer: 1, types.KubeflowNormalizer: 1, otherSource: 2}, locations)
+}
+
+func histogramValues(t *testing.T, o prometheus.Observer) (uint64, float64) {
+	m := &dto.Metric{}
+	common.AssertError(t, o.(prometheus.Metric).Write(m))
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestContentSizeMetrics(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:          map[string]*ImportLocation{},
+		modelcards:       map[string]modelCardMetadata{},
+		format:           types.CatalogInfoYamlFormat,
+		formatAutoDetect: true,
+	}
+	r := newBareRouter()
+	ils.registerRoutes(r, nil)
+	yamlUpserts := upsertedContentSize.WithLabelValues(string(types.CatalogInfoYamlFormat))
+	jsonUpserts := upsertedContentSize.WithLabelValues(string(types.JsonArrayForamt))
+	yamlServes := servedContentSize.WithLabelValues(string(types.CatalogInfoYamlFormat))
+	yamlUpsertCount, yamlUpsertSum := histogramValues(t, yamlUpserts)
+	jsonUpsertCount, jsonUpsertSum := histogramValues(t, jsonUpserts)
+	yamlServeCount, yamlServeSum := histogramValues(t, yamlServes)
+
+	jsonArray := `[{"modelName":"iris"}]`
+	for key, body := range map[string]string{"mnist_v1": mnistEntity, "granite_v1": graniteEntity, "iris_v1": jsonArray} {
+		data, err := json.Marshal(rest.PostBody{Body: []byte(body)})
+		common.AssertError(t, err)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, util.UpsertURI+"?key="+key, bytes.NewReader(data)))
+		common.AssertEqual(t, http.StatusCreated, rec.Code)
+	}
+	for _, path := range []string{"/mnist/v1/catalog-info.yaml", "/mnist/v1/catalog-info.yaml", "/fraud/v1/catalog-info.yaml"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	count, sum := histogramValues(t, yamlUpserts)
+	common.AssertEqual(t, yamlUpsertCount+2, count)
+	common.AssertEqual(t, yamlUpsertSum+float64(len(mnistEntity)+len(graniteEntity)), sum)
+	count, sum = histogramValues(t, jsonUpserts)
+	common.AssertEqual(t, jsonUpsertCount+1, count)
+	common.AssertEqual(t, jsonUpsertSum+float64(len(jsonArray)), sum)
+	// the lookup of a missing location serves nothing
+	count, sum = histogramValues(t, yamlServes)
+	common.AssertEqual(t, yamlServeCount+2, count)
+	common.AssertEqual(t, yamlServeSum+float64(2*len(mnistEntity)), sum)
+}
+
+// TestModelCardsServedMetric fetches a model card concurrently, asserting every fetch answered with the content is
+// counted exactly once and those answered as not modified are not, and that the series goes with the location
+func TestModelCardsServedMetric(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), modelCardKey: "mnist-card"}},
+		modelcards: map[string]modelCardMetadata{"mnist-card": newModelCardMetadata("mnist-card", "# mnist", "1000")},
+	}
+	r := newBareRouter()
+	ils.registerRoutes(r, nil)
+
+	served := modelCardsServed.WithLabelValues("mnist-card")
+	before := counterValue(t, served)
+	fetches := 200
+	ok := atomic.Int32{}
+	wg := sync.WaitGroup{}
+	for range fetches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ModelCardURI+"?key=mnist-card", nil))
+			switch rec.Code {
+			case http.StatusOK:
+				ok.Add(1)
+			case http.StatusNotModified:
+			default:
+				t.Errorf("expected the model card to be fetched but got %d", rec.Code)
+			}
+		}()
+	}
+	wg.Wait()
+	// a changed card is answered with its content until it has been fetched 11 times
+	common.AssertEqual(t, int32(11), ok.Load())
+	common.AssertEqual(t, float64(ok.Load()), counterValue(t, served)-before)
+
+	// cards not held are not counted
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ModelCardURI+"?key=missing-card", nil))
+	common.AssertEqual(t, http.StatusNotFound, rec.Code)
+	common.AssertEqual(t, float64(0), counterValue(t, modelCardsServed.WithLabelValues("missing-card")))
+
+	// removing the location deletes the series of its card
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, util.RemoveURI+"?key=mnist_v1", nil))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, float64(0), counterValue(t, modelCardsServed.WithLabelValues("mnist-card")))
+}
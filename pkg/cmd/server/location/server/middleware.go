@@ -0,0 +1,162 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+)
+
+// defaultRequestLogSkipPaths are excluded from request logging by default, as probes and scrapes hit them every few
+// seconds
+var defaultRequestLogSkipPaths = []string{"/healthz", "/readyz", "/metrics"}
+
+const (
+	// defaultRequestIdHeader is the header request IDs are read from and echoed back in by default
+	defaultRequestIdHeader = "X-Request-Id"
+	// maxRequestIdLength is the longest inbound request ID honored
+	maxRequestIdLength = 128
+)
+
+// newRouter creates the gin engine with the location service's middleware; requests to skipPaths are not logged,
+// but like every other request, are counted in the request metrics, and request IDs are read from and echoed back
+// in requestIdHeader
+func newRouter(logOut io.Writer, skipPaths []string, requestIdHeader string) *gin.Engine {
+	r := gin.New()
+	r.Use(addRequestId(requestIdHeader), trackInFlight(), requestMetrics(), requestLogger(logOut, skipPaths), gin.Recovery())
+	return r
+}
+
+// newBareRouter creates the gin engine with none of the location service's request logging, metrics and ID
+// middleware, only recovering from panics, to measure the serving path without their overhead
+func newBareRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery())
+	return r
+}
+
+// addRequestId adds a request ID to the gin context, honoring a valid one sent by the client or ingress in header so
+// that requests can be correlated across services, and generating a UUID otherwise; the ID is echoed back in the
+// same header
+func addRequestId(header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(header)
+		if !validRequestId(id) {
+			id = uuid.New().String()
+		}
+		c.Set("requestId", id)
+		c.Header(header, id)
+		c.Next()
+	}
+}
+
+// validRequestId checks an inbound request ID is short and made only of characters safe to log and echo back, i.e.
+// letters, digits, and any of -_.:=+/
+func validRequestId(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIdLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("-_.:=+/", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestLogger logs each request, along with its request ID, in the format of gin's default logger
+func requestLogger(out io.Writer, skipPaths []string) gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Output:    out,
+		SkipPaths: skipPaths,
+		Formatter: func(p gin.LogFormatterParams) string {
+			return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v | requestId=%v\n%s",
+				p.TimeStamp.Format("2006/01/02 - 15:04:05"),
+				p.StatusCode,
+				p.Latency,
+				p.ClientIP,
+				p.Method,
+				p.Path,
+				p.Keys["requestId"],
+				p.ErrorMessage,
+			)
+		},
+	})
+}
+
+// trackInFlight counts the requests being handled, other than those polling the count and the long-lived event
+// streams
+func trackInFlight() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path == util.MetricsInFlightURI || c.Request.URL.Path == util.EventsURI {
+			c.Next()
+			return
+		}
+		requestsInFlight.Add(1)
+		httpRequestsInFlight.Inc()
+		defer func() {
+			requestsInFlight.Add(-1)
+			httpRequestsInFlight.Dec()
+		}()
+		c.Next()
+	}
+}
+
+// requestMetrics counts and times each request by its route, where requests not matching a route share the
+// "unmatched" route so that arbitrary paths cannot grow the number of series; traced requests have their trace ID
+// attached to their latency as an exemplar
+func requestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if len(route) == 0 {
+			route = "unmatched"
+		}
+		httpRequestsTotal.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+		observer := httpRequestDuration.WithLabelValues(route, c.Request.Method)
+		if exemplar := traceExemplar(c); exemplar != nil {
+			observer.(prometheus.ExemplarObserver).ObserveWithExemplar(time.Since(start).Seconds(), exemplar)
+			return
+		}
+		observer.Observe(time.Since(start).Seconds())
+	}
+}
+
+// adminAuth requires requests to present token as a bearer token, or to have been granted the admin scope; with
+// neither configured, the endpoints it guards are disabled
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requireAdminScope(c) {
+			return
+		}
+		if len(token) == 0 {
+			c.AbortWithError(http.StatusForbidden, fmt.Errorf("configuration endpoints are disabled as no admin token is set"))
+			return
+		}
+		presented, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			c.AbortWithError(http.StatusUnauthorized, fmt.Errorf("a valid admin bearer token is required"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// optionalAdminAuth is adminAuth when a token is configured, and lets requests through otherwise
+func optionalAdminAuth(token string) gin.HandlerFunc {
+	if len(token) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return adminAuth(token)
+}
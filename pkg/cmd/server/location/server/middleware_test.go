@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestRequestLogSkipPaths(t *testing.T) {
+	logs := &bytes.Buffer{}
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}}
+	r := newRouter(logs, defaultRequestLogSkipPaths, defaultRequestIdHeader)
+	r.GET("/healthz", ils.handleHealthzGet)
+	r.GET("/list", ils.handleCatalogDiscoveryGet)
+
+	for _, tc := range []struct {
+		path        string
+		route       string
+		expectedSC  int
+		expectedLog bool
+	}{
+		{path: "/healthz", route: "/healthz", expectedSC: http.StatusOK},
+		{path: "/list", route: "/list", expectedSC: http.StatusOK, expectedLog: true},
+		{path: "/missing", route: "unmatched", expectedSC: http.StatusNotFound, expectedLog: true},
+	} {
+		counter := httpRequestsTotal.WithLabelValues(tc.route, http.MethodGet, strconv.Itoa(tc.expectedSC))
+		before := counterValue(t, counter)
+		logs.Reset()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		common.AssertEqual(t, tc.expectedSC, w.Code)
+		common.AssertEqual(t, tc.expectedLog, strings.Contains(logs.String(), tc.path))
+		common.AssertEqual(t, before+1, counterValue(t, counter))
+	}
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	common.AssertError(t, c.Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	common.AssertError(t, g.Write(m))
+	return m.GetGauge().GetValue()
+}
+
+func TestRequestsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.GET("/slow", func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusOK)
+	})
+	r.GET(util.MetricsInFlightURI, handleInFlightGet)
+	inFlight := func() string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, util.MetricsInFlightURI, nil))
+		common.AssertEqual(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+	common.AssertEqual(t, "0", inFlight())
+
+	wg := sync.WaitGroup{}
+	for n := 0; n < 5; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for requestsInFlight.Load() < 5 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	common.AssertEqual(t, "5", inFlight())
+	common.AssertEqual(t, float64(5), gaugeValue(t, httpRequestsInFlight))
+
+	close(release)
+	wg.Wait()
+	common.AssertEqual(t, "0", inFlight())
+	common.AssertEqual(t, float64(0), gaugeValue(t, httpRequestsInFlight))
+}
+
+func TestRequestMetricsExemplars(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	for _, tc := range []struct {
+		name            string
+		tracing         bool
+		traceparent     string
+		expectedTraceID string
+	}{
+		{
+			name:        "tracing disabled",
+			traceparent: "00-" + traceID + "-00f067aa0ba902b7-01",
+		},
+		{
+			name:    "tracing enabled without a trace",
+			tracing: true,
+		},
+		{
+			name:            "tracing enabled with a trace",
+			tracing:         true,
+			traceparent:     "00-" + traceID + "-00f067aa0ba902b7-01",
+			expectedTraceID: traceID,
+		},
+	} {
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		if tc.tracing {
+			r.Use(tracing())
+		}
+		r.GET("/exemplars", func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodGet, "/exemplars", nil)
+		if len(tc.traceparent) > 0 {
+			req.Header.Set("traceparent", tc.traceparent)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		m := &dto.Metric{}
+		common.AssertError(t, httpRequestDuration.WithLabelValues("/exemplars", http.MethodGet).(prometheus.Metric).Write(m))
+		found := ""
+		for _, b := range m.GetHistogram().GetBucket() {
+			for _, l := range b.GetExemplar().GetLabel() {
+				if l.GetName() == "trace_id" {
+					found = l.GetValue()
+				}
+			}
+		}
+		common.AssertEqual(t, tc.expectedTraceID, found)
+		httpRequestDuration.DeleteLabelValues("/exemplars", http.MethodGet)
+	}
+}
+
+func TestAddRequestId(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		header     string
+		inbound    string
+		expectedId string
+	}{
+		{
+			name:       "inbound ID honored",
+			header:     defaultRequestIdHeader,
+			inbound:    "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+			expectedId: "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+		},
+		{
+			name:       "inbound ID in a configured header honored",
+			header:     "X-Correlation-Id",
+			inbound:    "Root=1-67891233-abcdef012345678912345678",
+			expectedId: "Root=1-67891233-abcdef012345678912345678",
+		},
+		{
+			name:    "invalid inbound ID regenerated",
+			header:  defaultRequestIdHeader,
+			inbound: "bad id\nrequestId=forged",
+		},
+		{
+			name:    "overly long inbound ID regenerated",
+			header:  defaultRequestIdHeader,
+			inbound: strings.Repeat("a", maxRequestIdLength+1),
+		},
+		{
+			name:   "no inbound ID",
+			header: defaultRequestIdHeader,
+		},
+	} {
+		logs := &bytes.Buffer{}
+		r := newRouter(logs, nil, tc.header)
+		requestId := ""
+		r.GET("/id", func(c *gin.Context) {
+			requestId = c.GetString("requestId")
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/id", nil)
+		if len(tc.inbound) > 0 {
+			req.Header.Set(tc.header, tc.inbound)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		common.AssertEqual(t, http.StatusOK, w.Code)
+		if len(tc.expectedId) > 0 {
+			common.AssertEqual(t, tc.expectedId, requestId)
+		} else {
+			_, err := uuid.Parse(requestId)
+			common.AssertError(t, err)
+		}
+		common.AssertEqual(t, requestId, w.Header().Get(tc.header))
+		common.AssertEqual(t, true, strings.Contains(logs.String(), "requestId="+requestId))
+	}
+}
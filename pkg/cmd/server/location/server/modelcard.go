@@ -0,0 +1,566 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const frontMatterDelimiter = "---"
+
+// emptyModelCardMode determines how upserts of a model card key with empty card content are handled
+type emptyModelCardMode string
+
+const (
+	// emptyModelCardSkip creates no card, so that no blank card is served
+	emptyModelCardSkip emptyModelCardMode = "skip"
+	// emptyModelCardReject rejects the upsert with a 400
+	emptyModelCardReject emptyModelCardMode = "reject"
+	// emptyModelCardPlaceholder creates the card with placeholder content in place of the empty content
+	emptyModelCardPlaceholder emptyModelCardMode = "placeholder"
+
+	defaultModelCardPlaceholder = "No model card is available for this model."
+)
+
+func parseEmptyModelCardMode(str string) emptyModelCardMode {
+	switch m := emptyModelCardMode(strings.ToLower(strings.TrimSpace(str))); m {
+	case emptyModelCardSkip, emptyModelCardReject, emptyModelCardPlaceholder:
+		return m
+	case "":
+	default:
+		klog.Errorf("invalid empty model card mode %s, using %s", str, emptyModelCardSkip)
+	}
+	return emptyModelCardSkip
+}
+
+// checkEmptyModelCard applies the empty model card mode to the card content upserted with a model card key,
+// returning the content to hold the card with, and false if no card should be held; a rejected empty card is an
+// error
+func (i *ImportLocationServer) checkEmptyModelCard(cardKey, content string) (string, bool, error) {
+	if len(strings.TrimSpace(content)) > 0 {
+		return content, true, nil
+	}
+	switch i.emptyModelCardMode {
+	case emptyModelCardReject:
+		return "", false, fmt.Errorf("model card %s has empty content", cardKey)
+	case emptyModelCardPlaceholder:
+		return i.modelCardPlaceholder, true, nil
+	}
+	klog.Infof("skipping model card %s as its content is empty", cardKey)
+	return "", false, nil
+}
+
+// heldModelCard returns the content a card refetched from storage is held with, which is the placeholder for
+// empty content in placeholder mode
+func (i *ImportLocationServer) heldModelCard(content string) string {
+	if i.emptyModelCardMode == emptyModelCardPlaceholder && len(strings.TrimSpace(content)) == 0 {
+		return i.modelCardPlaceholder
+	}
+	return content
+}
+
+// parseFrontMatter extracts the YAML front-matter, the block delimited by '---' lines at the very start of the
+// markdown, from a model card; cards without front-matter, or whose front-matter is not valid YAML, yield nil
+func parseFrontMatter(card string) (map[string]interface{}, error) {
+	lines := strings.Split(strings.ReplaceAll(card, "\r\n", "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontMatterDelimiter {
+		return nil, nil
+	}
+	for idx := 1; idx < len(lines); idx++ {
+		if strings.TrimSpace(lines[idx]) != frontMatterDelimiter {
+			continue
+		}
+		fm := map[string]interface{}{}
+		err := yaml.Unmarshal([]byte(strings.Join(lines[1:idx], "\n")), &fm)
+		if err != nil {
+			return nil, fmt.Errorf("invalid model card front-matter: %s", err.Error())
+		}
+		return fm, nil
+	}
+	return nil, fmt.Errorf("model card front-matter is not terminated with '%s'", frontMatterDelimiter)
+}
+
+// newModelCardMetadata creates the metadata for newly upserted model card content
+func newModelCardMetadata(key, content, lastUpdateTimeSinceEpoch string) modelCardMetadata {
+	return modelCardMetadata{
+		content:                  content,
+		lastUpdateTimeSinceEpoch: lastUpdateTimeSinceEpoch,
+		serving:                  &modelCardServing{needToUpdate: true},
+		frontMatter:              modelCardFrontMatter(key, content),
+		cachedAt:                 time.Now(),
+	}
+}
+
+// modelCardServing is the state serving a card updates; as cards are served under the server read lock, it is
+// guarded by its own lock, and the copies of a card's metadata share it.  A nil state records nothing.
+type modelCardServing struct {
+	lock sync.Mutex
+	// needToUpdate is set when the card changes, and updateCount is the number of times its content was returned
+	// since; together they decide when Backstage is answered that the card is not modified
+	needToUpdate bool
+	updateCount  int
+	// lastUsed orders cards by when they were last upserted or served, for eviction
+	lastUsed uint64
+}
+
+// changed flags the card for Backstage to fetch again
+func (s *modelCardServing) changed() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.needToUpdate, s.updateCount = true, 0
+}
+
+// serve records a fetch of the card, returning false when Backstage is to be answered that it is not modified
+func (s *modelCardServing) serve() bool {
+	if s == nil {
+		return true
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.needToUpdate && s.updateCount > 10 {
+		return false
+	}
+	s.needToUpdate = false
+	s.updateCount++
+	return true
+}
+
+// pending returns whether Backstage has yet to fetch the card since it changed
+func (s *modelCardServing) pending() bool {
+	if s == nil {
+		return false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.needToUpdate
+}
+
+func (s *modelCardServing) use(clock uint64) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastUsed = clock
+}
+
+func (s *modelCardServing) used() uint64 {
+	if s == nil {
+		return 0
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.lastUsed
+}
+
+// modelCardFrontMatter returns the front-matter of model card content, or nil when it has none that parses
+func modelCardFrontMatter(key, content string) map[string]interface{} {
+	fm, err := parseFrontMatter(content)
+	if err != nil {
+		klog.Infof("model card %s front-matter ignored: %s", key, err.Error())
+	}
+	return fm
+}
+
+// reconcile updates a held card with the content and last update time posted or fetched for it, flagging it for
+// Backstage to fetch again only when either genuinely changed, so a card reconciled unchanged keeps its update count
+// and Backstage does not refetch it; an empty last update time is not a change.  The content of an evicted card
+// cannot be compared, so it is restored, and only its last update time decides whether it changed.  It returns
+// whether the card changed.
+func (mcm *modelCardMetadata) reconcile(key, content, lastUpdateTimeSinceEpoch string) bool {
+	changed := false
+	switch {
+	case mcm.evicted:
+		mcm.content, mcm.frontMatter, mcm.evicted = content, modelCardFrontMatter(key, content), false
+	case mcm.content != content:
+		mcm.content, mcm.frontMatter = content, modelCardFrontMatter(key, content)
+		changed = true
+	}
+	if len(lastUpdateTimeSinceEpoch) > 0 && mcm.lastUpdateTimeSinceEpoch != lastUpdateTimeSinceEpoch {
+		mcm.lastUpdateTimeSinceEpoch = lastUpdateTimeSinceEpoch
+		changed = true
+	}
+	if changed {
+		mcm.serving.changed()
+	}
+	return changed
+}
+
+// refreshModelCard refetches a model card from storage when its cached content is older than the max age, so that
+// cards changed in storage out-of-band are served current; a card is refetched at most once per refetch interval,
+// and a failed refetch leaves the cached card to be served
+func (i *ImportLocationServer) refreshModelCard(key string) {
+	if i.modelCardMaxAge <= 0 {
+		return
+	}
+	now := time.Now()
+	i.lock.Lock()
+	mcm, ok := i.modelcards[key]
+	if !ok || len(mcm.storageKey) == 0 || now.Sub(mcm.cachedAt) <= i.modelCardMaxAge || now.Sub(mcm.lastRefetch) < i.modelCardRefetchInterval {
+		i.lock.Unlock()
+		return
+	}
+	mcm.lastRefetch = now
+	i.modelcards[key] = mcm
+	i.lock.Unlock()
+
+	i.refetchModelCard(key, mcm.storageKey)
+}
+
+// refetchModelCard refetches a model card from the storage key it is stored under, updating the cached card when
+// its content has changed; it returns whether the card was refetched, so false when the fetch failed or the storage
+// key no longer has the card
+func (i *ImportLocationServer) refetchModelCard(key, storageKey string) bool {
+	v, err, _ := i.fetches.Do(util.ModelCardURI+"/"+key, func() (interface{}, error) {
+		return i.fetchStorageBody(storageKey)
+	})
+	if err != nil {
+		klog.Errorf("refetch of model card %s from storage key %s failed: %s", key, storageKey, err.Error())
+		return false
+	}
+	sb := v.(*types.StorageBody)
+	if sb.ModelCardKey != key {
+		klog.Infof("storage key %s no longer has model card %s", storageKey, key)
+		return false
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	mcm, ok := i.modelcards[key]
+	if !ok {
+		return false
+	}
+	mcm.cachedAt = time.Now()
+	evicted := mcm.evicted
+	if mcm.reconcile(key, i.heldModelCard(sb.ModelCard), sb.LastUpdateTimeSinceEpoch) {
+		klog.Infof("model card %s refetched from storage key %s has changed", key, storageKey)
+	}
+	i.modelcards[key] = mcm
+	// a refetched evicted card is resident again
+	if evicted && i.modelCardMaxResident > 0 {
+		i.useModelCard(key)
+		i.evictModelCards(key)
+	}
+	return true
+}
+
+type ModelCardMetaResponse struct {
+	Key                      string                 `json:"key"`
+	LastUpdateTimeSinceEpoch string                 `json:"lastUpdateTimeSinceEpoch"`
+	FrontMatter              map[string]interface{} `json:"frontMatter,omitempty"`
+	Attachments              []string               `json:"attachments,omitempty"`
+}
+
+func (i *ImportLocationServer) handleModelCardMetaGet(c *gin.Context) {
+	key := c.Query(util.KeyQueryParam)
+	i.lock.RLock()
+	mcm, ok := i.modelcards[key]
+	i.lock.RUnlock()
+	if !ok {
+		klog.Infof("no model card found for %s", key)
+		c.Status(http.StatusNotFound)
+		return
+	}
+	content, err := json.Marshal(&ModelCardMetaResponse{
+		Key:                      key,
+		LastUpdateTimeSinceEpoch: mcm.lastUpdateTimeSinceEpoch,
+		FrontMatter:              mcm.frontMatter,
+		Attachments:              attachmentNames(mcm),
+	})
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
+
+type ModelCardsResponse struct {
+	ModelCards []ModelCardMetaResponse `json:"modelCards"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+}
+
+func (i *ImportLocationServer) handleModelCardsGet(c *gin.Context) {
+	p, err := parsePage(c)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	i.lock.RLock()
+	keys := make([]string, 0, len(i.modelcards))
+	for key := range i.modelcards {
+		keys = append(keys, key)
+	}
+	selected, next := p.apply(keys)
+	resp := &ModelCardsResponse{ModelCards: make([]ModelCardMetaResponse, 0, len(selected)), NextCursor: next}
+	for _, key := range selected {
+		mcm := i.modelcards[key]
+		resp.ModelCards = append(resp.ModelCards, ModelCardMetaResponse{
+			Key:                      key,
+			LastUpdateTimeSinceEpoch: mcm.lastUpdateTimeSinceEpoch,
+			FrontMatter:              mcm.frontMatter,
+			Attachments:              attachmentNames(mcm),
+		})
+	}
+	content, err := json.Marshal(resp)
+	i.lock.RUnlock()
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
+
+// checkModelCardKey rejects, when conflicts are checked, reusing the model card key of another key's location unless
+// overridden; callers must hold the server lock
+func (i *ImportLocationServer) checkModelCardKey(cardKey, key string, override bool) error {
+	mcm, ok := i.modelcards[cardKey]
+	if !i.modelCardKeyConflicts || !ok || override || len(cardKey) == 0 || len(mcm.storageKey) == 0 || mcm.storageKey == key {
+		return nil
+	}
+	return fmt.Errorf("model card key %s is already used by key %s, set %s=true to reassign it", cardKey, mcm.storageKey, util.OverrideQueryParam)
+}
+
+// handleModelCardUpsertPost updates only the model card of the existing location for a key, leaving its
+// catalog-info untouched, so normalizers need not re-post unchanged content when only the card changes
+func (u *ImportLocationServer) handleModelCardUpsertPost(c *gin.Context) {
+	if u.memoryGuard.rejectUnderPressure(c) {
+		return
+	}
+	defer u.deadLetters.captureRejection(c)()
+	key := u.namespace.strip(c.Query(util.KeyQueryParam))
+	if len(key) == 0 {
+		c.Status(http.StatusBadRequest)
+		c.Error(fmt.Errorf("need a 'key' parameter"))
+		return
+	}
+	var postBody rest.PostBody
+	err := c.BindJSON(&postBody)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		klog.Errorf("error reading POST body: %s", err.Error())
+		c.Error(err)
+		return
+	}
+	if len(postBody.ModelCardKey) == 0 {
+		c.Status(http.StatusBadRequest)
+		c.Error(fmt.Errorf("need a 'modelCardKey' in the POST body"))
+		return
+	}
+	segs := strings.Split(key, "_")
+	if len(segs) < 2 {
+		c.Status(http.StatusBadRequest)
+		c.Error(fmt.Errorf("bad key format: %s", key))
+		return
+	}
+	if err := u.shards.checkShard(key); err != nil {
+		c.Status(http.StatusMisdirectedRequest)
+		c.Error(err)
+		return
+	}
+	if status, err := u.checkAttachments(postBody); err != nil {
+		c.Status(status)
+		c.Error(err)
+		return
+	}
+	cardContent, holdCard, err := u.checkEmptyModelCard(postBody.ModelCardKey, postBody.ModelCard)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	if !holdCard {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	override, _ := strconv.ParseBool(c.Query(util.OverrideQueryParam))
+	if u.readThrough != nil {
+		status, err := u.upsertModelCardThrough(key, segs, postBody, cardContent, override)
+		c.Status(status)
+		if err != nil {
+			c.Error(err)
+		}
+		return
+	}
+	if !u.lockForMutation() {
+		c.Status(http.StatusServiceUnavailable)
+		c.Error(fmt.Errorf("a reload from storage is in progress, retry the upsert of %s", key))
+		return
+	}
+	defer u.lock.Unlock()
+	var il *ImportLocation
+	var ilURI string
+	for _, uri := range u.candidateURIs(segs[0], segs[1]) {
+		if loc, ok := u.content[uri]; ok && loc.content != nil {
+			il, ilURI = loc, uri
+			break
+		}
+	}
+	if il == nil {
+		c.Status(http.StatusNotFound)
+		c.Error(fmt.Errorf("no location for key %s to update the model card of", key))
+		return
+	}
+	if err = u.checkModelCardKey(postBody.ModelCardKey, key, override); err != nil {
+		c.Status(http.StatusConflict)
+		klog.Error(err.Error())
+		c.Error(err)
+		return
+	}
+	mcm, ok := u.modelcards[postBody.ModelCardKey]
+	if !ok || mcm.content != cardContent || mcm.lastUpdateTimeSinceEpoch != postBody.LastUpdateTimeSinceEpoch {
+		updated := newModelCardMetadata(postBody.ModelCardKey, cardContent, postBody.LastUpdateTimeSinceEpoch)
+		updated.serving.use(mcm.serving.used())
+		updated.attachments = mcm.attachments
+		mcm = updated
+		// storage keeps the card along with the unchanged content
+		u.writeBehind.enqueue(key, il.source, rest.PostBody{
+			Body:                     u.plaintext(il.content),
+			ModelCardKey:             postBody.ModelCardKey,
+			ModelCard:                cardContent,
+			LastUpdateTimeSinceEpoch: postBody.LastUpdateTimeSinceEpoch,
+		})
+	}
+	mcm.storageKey = key
+	if len(postBody.Attachments) > 0 {
+		mcm.attachments = newAttachments(postBody.Attachments)
+	}
+	u.modelcards[postBody.ModelCardKey] = mcm
+	if len(il.modelCardKey) > 0 && il.modelCardKey != postBody.ModelCardKey {
+		u.unlinkModelCard(il.modelCardKey, key, ilURI)
+	}
+	u.content[ilURI] = il.withModelCardKey(postBody.ModelCardKey)
+	if u.modelCardMaxResident > 0 {
+		u.useModelCard(postBody.ModelCardKey)
+		u.evictModelCards(postBody.ModelCardKey)
+	}
+	klog.Infof("Upserting model card %s of len %d for key %s", postBody.ModelCardKey, len(postBody.ModelCard), key)
+	c.Status(http.StatusCreated)
+}
+
+// unlinkModelCard drops the card at cardKey, which the location of key at uri no longer has, unless the card was
+// reassigned to another key or another location still has it; callers must hold the server lock
+func (u *ImportLocationServer) unlinkModelCard(cardKey, key, uri string) {
+	if mcm, ok := u.modelcards[cardKey]; !ok || (len(mcm.storageKey) > 0 && mcm.storageKey != key) {
+		return
+	}
+	for other, il := range u.content {
+		if other != uri && il.content != nil && il.modelCardKey == cardKey {
+			return
+		}
+	}
+	klog.Infof("dropping model card %s, no longer the card of key %s", cardKey, key)
+	delete(u.modelcards, cardKey)
+	modelCardsServed.DeleteLabelValues(cardKey)
+}
+
+type ModelCardsRefreshResponse struct {
+	Refreshed int `json:"refreshed"`
+	Failed    int `json:"failed"`
+}
+
+// handleModelCardsRefreshPost refetches from storage the cards Backstage has yet to pull, i.e. flagged as needing an
+// update, or every card when the all parameter is true, rather than waiting for each to be pulled; the fetches run in
+// parallel as the fetch semaphore allows.  Evicted cards are left to be refetched when next served.
+func (i *ImportLocationServer) handleModelCardsRefreshPost(c *gin.Context) {
+	all := false
+	if raw := c.Query(util.AllQueryParam); len(raw) > 0 {
+		var err error
+		all, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			c.Error(fmt.Errorf("the %s query parameter must be a boolean: %s", util.AllQueryParam, err.Error()))
+			return
+		}
+	}
+	i.lock.RLock()
+	storageKeys := map[string]string{}
+	for key, mcm := range i.modelcards {
+		if len(mcm.storageKey) > 0 && !mcm.evicted && (all || mcm.serving.pending()) {
+			storageKeys[key] = mcm.storageKey
+		}
+	}
+	i.lock.RUnlock()
+
+	wg := sync.WaitGroup{}
+	refreshed := atomic.Int32{}
+	for key, storageKey := range storageKeys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i.refetchModelCard(key, storageKey) {
+				refreshed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	resp := &ModelCardsRefreshResponse{Refreshed: int(refreshed.Load()), Failed: len(storageKeys) - int(refreshed.Load())}
+	klog.Infof("refreshed %d model cards from storage, %d failed", resp.Refreshed, resp.Failed)
+	content, err := json.Marshal(resp)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
+
+type ModelCardsReparseResponse struct {
+	Reparsed int `json:"reparsed"`
+	Failed   int `json:"failed"`
+	Evicted  int `json:"evicted"`
+}
+
+// reparseModelCards re-extracts the front-matter of the cached cards in place, so cards upserted before a change to
+// its parsing have the metadata it now extracts; a card whose front-matter no longer parses is left with none, as on
+// upsert.  Evicted cards are parsed when refetched, and headings are parsed whenever a table of contents is served.
+func (i *ImportLocationServer) reparseModelCards() *ModelCardsReparseResponse {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	resp := &ModelCardsReparseResponse{}
+	for key, mcm := range i.modelcards {
+		if mcm.evicted {
+			resp.Evicted++
+			continue
+		}
+		fm, err := parseFrontMatter(mcm.content)
+		if err != nil {
+			klog.Infof("model card %s front-matter ignored: %s", key, err.Error())
+			resp.Failed++
+		}
+		mcm.frontMatter = fm
+		i.modelcards[key] = mcm
+		resp.Reparsed++
+	}
+	return resp
+}
+
+// handleModelCardsReparsePost re-extracts the metadata of every cached card, responding with the number reparsed
+func (i *ImportLocationServer) handleModelCardsReparsePost(c *gin.Context) {
+	resp := i.reparseModelCards()
+	klog.Infof("reparsed %d model cards, %d with front-matter that failed to parse, %d evicted cards skipped", resp.Reparsed, resp.Failed, resp.Evicted)
+	content, err := json.Marshal(resp)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
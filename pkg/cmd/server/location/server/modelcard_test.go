@@ -0,0 +1,709 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+const (
+	frontMatterCard = `---
+name: mnist
+license: apache-2.0
+tags:
+  - vision
+  - onnx
+---
+# MNIST
+
+A handwritten digit classifier.
+`
+	plainCard = `# MNIST
+
+A handwritten digit classifier.
+`
+	badFrontMatterCard = `---
+name: [mnist
+---
+# MNIST
+`
+)
+
+func TestHandleModelCardMetaGet(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		card         string
+		queryKey     string
+		expectedSC   int
+		expectedBody string
+	}{
+		{
+			name:         "valid front-matter",
+			card:         frontMatterCard,
+			queryKey:     "mnist-card",
+			expectedSC:   http.StatusOK,
+			expectedBody: `{"key":"mnist-card","lastUpdateTimeSinceEpoch":"1","frontMatter":{"license":"apache-2.0","name":"mnist","tags":["vision","onnx"]}}`,
+		},
+		{
+			name:         "CRLF front-matter",
+			card:         "---\r\nname: mnist\r\n---\r\n# MNIST\r\n",
+			queryKey:     "mnist-card",
+			expectedSC:   http.StatusOK,
+			expectedBody: `{"key":"mnist-card","lastUpdateTimeSinceEpoch":"1","frontMatter":{"name":"mnist"}}`,
+		},
+		{
+			name:         "no front-matter",
+			card:         plainCard,
+			queryKey:     "mnist-card",
+			expectedSC:   http.StatusOK,
+			expectedBody: `{"key":"mnist-card","lastUpdateTimeSinceEpoch":"1"}`,
+		},
+		{
+			name:         "invalid front-matter",
+			card:         badFrontMatterCard,
+			queryKey:     "mnist-card",
+			expectedSC:   http.StatusOK,
+			expectedBody: `{"key":"mnist-card","lastUpdateTimeSinceEpoch":"1"}`,
+		},
+		{
+			name:         "unterminated front-matter",
+			card:         "---\nname: mnist\n# MNIST\n",
+			queryKey:     "mnist-card",
+			expectedSC:   http.StatusOK,
+			expectedBody: `{"key":"mnist-card","lastUpdateTimeSinceEpoch":"1"}`,
+		},
+		{
+			name:       "missing card",
+			card:       frontMatterCard,
+			queryKey:   "other-card",
+			expectedSC: http.StatusNotFound,
+		},
+	} {
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}}
+
+		testWriter := testgin.NewTestResponseWriter()
+		data, err := json.Marshal(rest.PostBody{Body: []byte("create"), ModelCardKey: "mnist-card", ModelCard: tc.card, LastUpdateTimeSinceEpoch: "1"})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+
+		testWriter = testgin.NewTestResponseWriter()
+		ctx, _ = gin.CreateTestContext(testWriter)
+		ctx.Request, _ = http.NewRequest(http.MethodGet, "/modelcard/meta?key="+tc.queryKey, nil)
+
+		ils.handleModelCardMetaGet(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedBody, testWriter.ResponseWriter.Body.String())
+	}
+}
+
+func TestHandleModelCardGetMaxAge(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte(mnistEntity)})
+	st.SetModelCard("mnist_v1", "mnist-card", "updated card", "2")
+	for _, tc := range []struct {
+		name             string
+		maxAge           time.Duration
+		cachedAt         time.Time
+		lastRefetch      time.Time
+		expectedBody     string
+		expectedFetches  int
+		expectedUpdateTS string
+	}{
+		{
+			name:             "refetching disabled",
+			cachedAt:         time.Now().Add(-time.Hour),
+			expectedBody:     "card",
+			expectedUpdateTS: "1",
+		},
+		{
+			name:             "younger than the max age",
+			maxAge:           time.Hour,
+			cachedAt:         time.Now(),
+			expectedBody:     "card",
+			expectedUpdateTS: "1",
+		},
+		{
+			name:             "older than the max age",
+			maxAge:           time.Minute,
+			cachedAt:         time.Now().Add(-time.Hour),
+			expectedBody:     "updated card",
+			expectedFetches:  1,
+			expectedUpdateTS: "2",
+		},
+		{
+			name:             "refetched too recently",
+			maxAge:           time.Minute,
+			cachedAt:         time.Now().Add(-time.Hour),
+			lastRefetch:      time.Now().Add(-time.Second),
+			expectedBody:     "card",
+			expectedUpdateTS: "1",
+		},
+	} {
+		before := st.FetchCount("mnist_v1")
+		ils := &ImportLocationServer{
+			content: map[string]*ImportLocation{},
+			modelcards: map[string]modelCardMetadata{
+				"mnist-card": {content: "card", lastUpdateTimeSinceEpoch: "1", serving: &modelCardServing{needToUpdate: true}, storageKey: "mnist_v1", cachedAt: tc.cachedAt, lastRefetch: tc.lastRefetch},
+			},
+			storage:                  st,
+			modelCardMaxAge:          tc.maxAge,
+			modelCardRefetchInterval: time.Minute,
+		}
+		// a hot card is refetched at most once per refetch interval
+		for range 3 {
+			testWriter := testgin.NewTestResponseWriter()
+			ctx, _ := gin.CreateTestContext(testWriter)
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/modelcard?key=mnist-card", nil)
+
+			ils.handleModelCardGet(ctx)
+
+			common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+			common.AssertEqual(t, tc.expectedBody, testWriter.ResponseWriter.Body.String())
+		}
+		common.AssertEqual(t, tc.expectedFetches, st.FetchCount("mnist_v1")-before)
+		common.AssertEqual(t, tc.expectedUpdateTS, ils.modelcards["mnist-card"].lastUpdateTimeSinceEpoch)
+	}
+}
+
+func TestHandleCatalogUpsertPostModelCardKeyConflicts(t *testing.T) {
+	for _, tc := range []struct {
+		name               string
+		conflicts          bool
+		rawQuery           string
+		expectedSC         int
+		expectedStorageKey string
+		expectUpserted     bool
+	}{
+		{
+			name:               "shared by default",
+			rawQuery:           "key=granite_v1",
+			expectedSC:         http.StatusCreated,
+			expectedStorageKey: "mnist_v1",
+			expectUpserted:     true,
+		},
+		{
+			name:               "conflict",
+			conflicts:          true,
+			rawQuery:           "key=granite_v1",
+			expectedSC:         http.StatusConflict,
+			expectedStorageKey: "mnist_v1",
+		},
+		{
+			name:               "same location is no conflict",
+			conflicts:          true,
+			rawQuery:           "key=mnist_v1",
+			expectedSC:         http.StatusCreated,
+			expectedStorageKey: "mnist_v1",
+			expectUpserted:     true,
+		},
+		{
+			name:               "override",
+			conflicts:          true,
+			rawQuery:           "key=granite_v1&override=true",
+			expectedSC:         http.StatusCreated,
+			expectedStorageKey: "granite_v1",
+			expectUpserted:     true,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:               map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), modelCardKey: "shared"}},
+			modelcards:            map[string]modelCardMetadata{"shared": {content: "# mnist", storageKey: "mnist_v1"}},
+			modelCardKeyConflicts: tc.conflicts,
+		}
+		data, err := json.Marshal(rest.PostBody{Body: []byte("update"), ModelCardKey: "shared", ModelCard: "# granite"})
+		common.AssertError(t, err)
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: tc.rawQuery}, Body: io.NopCloser(bytes.NewReader(data))}
+
+		ils.handleCatalogUpsertPost(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedStorageKey, ils.modelcards["shared"].storageKey)
+		upserted := 0
+		for _, il := range ils.content {
+			if string(il.content) == "update" {
+				upserted++
+			}
+		}
+		common.AssertEqual(t, tc.expectUpserted, upserted == 1)
+	}
+}
+
+func TestHandleModelCardUpsertPost(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		existing        map[string]*ImportLocation
+		body            rest.PostBody
+		expectedSC      int
+		expectedCard    string
+		expectedContent string
+	}{
+		{
+			name:            "card only update of an existing location",
+			existing:        map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), modelCardKey: "mnist-card"}},
+			body:            rest.PostBody{ModelCardKey: "mnist-card", ModelCard: "# mnist updated", LastUpdateTimeSinceEpoch: "2"},
+			expectedSC:      http.StatusCreated,
+			expectedCard:    "# mnist updated",
+			expectedContent: mnistEntity,
+		},
+		{
+			name:         "missing location",
+			existing:     map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {}},
+			body:         rest.PostBody{ModelCardKey: "mnist-card", ModelCard: "# mnist updated", LastUpdateTimeSinceEpoch: "2"},
+			expectedSC:   http.StatusNotFound,
+			expectedCard: "# mnist",
+		},
+		{
+			name:            "missing model card key",
+			existing:        map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), modelCardKey: "mnist-card"}},
+			body:            rest.PostBody{ModelCard: "# mnist updated"},
+			expectedSC:      http.StatusBadRequest,
+			expectedCard:    "# mnist",
+			expectedContent: mnistEntity,
+		},
+	} {
+		w := &recordingWriter{writes: map[string]string{}}
+		ils := &ImportLocationServer{
+			content:     tc.existing,
+			modelcards:  map[string]modelCardMetadata{"mnist-card": {content: "# mnist", lastUpdateTimeSinceEpoch: "1", storageKey: "mnist_v1"}},
+			writeBehind: newWriteBehind(w, time.Hour),
+		}
+		data, err := json.Marshal(tc.body)
+		common.AssertError(t, err)
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+
+		ils.handleModelCardUpsertPost(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedCard, ils.modelcards["mnist-card"].content)
+		common.AssertEqual(t, tc.expectedContent, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+		ils.writeBehind.close()
+		writes, _ := w.snapshot()
+		if tc.expectedSC == http.StatusCreated {
+			common.AssertEqual(t, true, ils.modelcards["mnist-card"].serving.pending())
+			common.AssertEqual(t, "2", ils.modelcards["mnist-card"].lastUpdateTimeSinceEpoch)
+			common.AssertEqual(t, map[string]string{"mnist_v1": mnistEntity}, writes)
+			continue
+		}
+		common.AssertEqual(t, map[string]string{}, writes)
+	}
+}
+
+func modelCardUpsertForTest(t *testing.T, ils *ImportLocationServer, key string, body rest.PostBody) int {
+	data, err := json.Marshal(body)
+	common.AssertError(t, err)
+	ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + key}, Body: io.NopCloser(bytes.NewReader(data))}
+	ils.handleModelCardUpsertPost(ctx)
+	return ctx.Writer.Status()
+}
+
+func TestHandleModelCardUpsertPostCardKeyChanged(t *testing.T) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":   {content: []byte(mnistEntity), modelCardKey: "mnist-card"},
+			"/granite/v1/catalog-info.yaml": {content: []byte(graniteEntity), modelCardKey: "granite-card"},
+		},
+		modelcards: map[string]modelCardMetadata{
+			"mnist-card":   {content: "# mnist", lastUpdateTimeSinceEpoch: "1", storageKey: "mnist_v1"},
+			"granite-card": {content: "# granite", lastUpdateTimeSinceEpoch: "1", storageKey: "granite_v1"},
+		},
+	}
+	common.AssertEqual(t, http.StatusCreated, modelCardUpsertForTest(t, ils, "mnist_v1", rest.PostBody{ModelCardKey: "mnist-card-v2", ModelCard: "# mnist v2", LastUpdateTimeSinceEpoch: "2"}))
+	common.AssertEqual(t, "mnist-card-v2", ils.content["/mnist/v1/catalog-info.yaml"].modelCardKey)
+	common.AssertEqual(t, "# mnist v2", ils.modelcards["mnist-card-v2"].content)
+	// the previous card, no longer the card of any location, is dropped
+	_, ok := ils.modelcards["mnist-card"]
+	common.AssertEqual(t, false, ok)
+
+	// the previous card of another key is dropped as well when the key takes over a card
+	common.AssertEqual(t, http.StatusCreated, modelCardUpsertForTest(t, ils, "granite_v1", rest.PostBody{ModelCardKey: "mnist-card-v2", ModelCard: "# granite", LastUpdateTimeSinceEpoch: "3"}))
+	_, ok = ils.modelcards["granite-card"]
+	common.AssertEqual(t, false, ok)
+}
+
+func TestHandleModelCardUpsertPostDeadLetters(t *testing.T) {
+	out := &bytes.Buffer{}
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		modelcards:  map[string]modelCardMetadata{},
+		deadLetters: newDeadLetterWriter(out),
+	}
+	ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+	body := `{"modelCardKey":"mnist-card","modelCard":"# mnist"}`
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(strings.NewReader(body))}
+	ctx.Set("requestId", "req-1")
+
+	ils.handleModelCardUpsertPost(ctx)
+
+	common.AssertEqual(t, http.StatusNotFound, ctx.Writer.Status())
+	dl := DeadLetter{}
+	common.AssertError(t, json.Unmarshal(out.Bytes(), &dl))
+	common.AssertEqual(t, "mnist_v1", dl.Key)
+	common.AssertEqual(t, http.StatusNotFound, dl.Status)
+	common.AssertEqual(t, body, dl.Payload)
+}
+
+func TestHandleModelCardUpsertPostReadThrough(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte(mnistEntity)})
+	st.SetModelCard("mnist_v1", "mnist-card", "# mnist", "1")
+	w := &recordingWriter{writes: map[string]string{}, cards: map[string]string{}}
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		modelcards:  map[string]modelCardMetadata{"mnist-card": {content: "# mnist", lastUpdateTimeSinceEpoch: "1", storageKey: "mnist_v1"}},
+		storage:     st,
+		readThrough: newReadThroughCache(time.Minute, defaultReadThroughCacheMaxEntries),
+		writeBehind: newWriteBehind(w, time.Hour),
+	}
+	common.AssertEqual(t, http.StatusCreated, modelCardUpsertForTest(t, ils, "mnist_v1", rest.PostBody{ModelCardKey: "mnist-card-v2", ModelCard: "# mnist v2", LastUpdateTimeSinceEpoch: "2"}))
+	common.AssertEqual(t, "# mnist v2", ils.modelcards["mnist-card-v2"].content)
+	_, ok := ils.modelcards["mnist-card"]
+	common.AssertEqual(t, false, ok)
+	// no location is held in read-through mode
+	common.AssertEqual(t, 0, len(ils.content))
+	common.AssertEqual(t, http.StatusNotFound, modelCardUpsertForTest(t, ils, "fraud_v1", rest.PostBody{ModelCardKey: "fraud-card", ModelCard: "# fraud"}))
+
+	ils.writeBehind.close()
+	writes, _ := w.snapshot()
+	// storage is written the card along with the unchanged content
+	common.AssertEqual(t, map[string]string{"mnist_v1": mnistEntity}, writes)
+	common.AssertEqual(t, map[string]string{"mnist-card-v2": "# mnist v2"}, w.cards)
+}
+
+func TestHandleCatalogUpsertPostEmptyModelCard(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		mode                 emptyModelCardMode
+		card                 string
+		expectedSC           int
+		expectedCardSC       int
+		expectedCard         string
+		expectedModelCardKey string
+	}{
+		{
+			name:           "skipped by default",
+			expectedSC:     http.StatusCreated,
+			expectedCardSC: http.StatusNotFound,
+		},
+		{
+			name:           "whitespace only card skipped",
+			mode:           emptyModelCardSkip,
+			card:           " \n",
+			expectedSC:     http.StatusCreated,
+			expectedCardSC: http.StatusNotFound,
+		},
+		{
+			name:           "rejected",
+			mode:           emptyModelCardReject,
+			expectedSC:     http.StatusBadRequest,
+			expectedCardSC: http.StatusNotFound,
+		},
+		{
+			name:                 "placeholder served",
+			mode:                 emptyModelCardPlaceholder,
+			expectedSC:           http.StatusCreated,
+			expectedCardSC:       http.StatusOK,
+			expectedCard:         defaultModelCardPlaceholder,
+			expectedModelCardKey: "mnist-card",
+		},
+		{
+			name:                 "non-empty card unaffected",
+			mode:                 emptyModelCardReject,
+			card:                 "# mnist",
+			expectedSC:           http.StatusCreated,
+			expectedCardSC:       http.StatusOK,
+			expectedCard:         "# mnist",
+			expectedModelCardKey: "mnist-card",
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:              map[string]*ImportLocation{},
+			modelcards:           map[string]modelCardMetadata{},
+			emptyModelCardMode:   tc.mode,
+			modelCardPlaceholder: defaultModelCardPlaceholder,
+		}
+		data, err := json.Marshal(rest.PostBody{Body: []byte(mnistEntity), ModelCardKey: "mnist-card", ModelCard: tc.card})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+
+		ils.handleCatalogUpsertPost(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if il, ok := ils.content["/mnist/v1/catalog-info.yaml"]; ok {
+			common.AssertEqual(t, tc.expectedModelCardKey, il.modelCardKey)
+		}
+
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ = gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist-card"}}
+
+		ils.handleModelCardGet(ctx)
+
+		common.AssertEqual(t, tc.expectedCardSC, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedCard, testWriter.ResponseWriter.Body.String())
+	}
+}
+
+func TestHandleModelCardUpsertPostEmptyModelCard(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		mode          emptyModelCardMode
+		expectedSC    int
+		expectedCard  string
+		expectedCards map[string]string
+	}{
+		{
+			name:          "skipped",
+			mode:          emptyModelCardSkip,
+			expectedSC:    http.StatusNoContent,
+			expectedCard:  "# mnist",
+			expectedCards: map[string]string{},
+		},
+		{
+			name:          "rejected",
+			mode:          emptyModelCardReject,
+			expectedSC:    http.StatusBadRequest,
+			expectedCard:  "# mnist",
+			expectedCards: map[string]string{},
+		},
+		{
+			name:         "placeholder",
+			mode:         emptyModelCardPlaceholder,
+			expectedSC:   http.StatusCreated,
+			expectedCard: "no card yet",
+			// storage is written the card as held
+			expectedCards: map[string]string{"mnist-card": "no card yet"},
+		},
+	} {
+		w := &recordingWriter{writes: map[string]string{}, cards: map[string]string{}}
+		ils := &ImportLocationServer{
+			content:              map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), modelCardKey: "mnist-card"}},
+			modelcards:           map[string]modelCardMetadata{"mnist-card": {content: "# mnist", lastUpdateTimeSinceEpoch: "1", storageKey: "mnist_v1"}},
+			emptyModelCardMode:   tc.mode,
+			modelCardPlaceholder: "no card yet",
+			writeBehind:          newWriteBehind(w, time.Hour),
+		}
+		data, err := json.Marshal(rest.PostBody{ModelCardKey: "mnist-card", LastUpdateTimeSinceEpoch: "2"})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+
+		ils.handleModelCardUpsertPost(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedCard, ils.modelcards["mnist-card"].content)
+		ils.writeBehind.close()
+		common.AssertEqual(t, tc.expectedCards, w.cards)
+	}
+}
+
+func TestParseEmptyModelCardMode(t *testing.T) {
+	common.AssertEqual(t, emptyModelCardSkip, parseEmptyModelCardMode(""))
+	common.AssertEqual(t, emptyModelCardReject, parseEmptyModelCardMode(" Reject "))
+	common.AssertEqual(t, emptyModelCardPlaceholder, parseEmptyModelCardMode("placeholder"))
+	common.AssertEqual(t, emptyModelCardSkip, parseEmptyModelCardMode("blank"))
+}
+
+func TestHandleModelCardsGet(t *testing.T) {
+	cursor := func(key string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(key))
+	}
+	for _, tc := range []struct {
+		name           string
+		query          string
+		expectedSC     int
+		expectedKeys   []string
+		expectedCursor string
+	}{
+		{
+			name:         "default page size",
+			expectedSC:   http.StatusOK,
+			expectedKeys: []string{"fraud-card", "granite-card", "mnist-card-v1", "mnist-card-v2", "mnist-card-v3"},
+		},
+		{
+			name:           "first page",
+			query:          "limit=2",
+			expectedSC:     http.StatusOK,
+			expectedKeys:   []string{"fraud-card", "granite-card"},
+			expectedCursor: cursor("granite-card"),
+		},
+		{
+			name:           "next page",
+			query:          "limit=2&cursor=" + cursor("granite-card"),
+			expectedSC:     http.StatusOK,
+			expectedKeys:   []string{"mnist-card-v1", "mnist-card-v2"},
+			expectedCursor: cursor("mnist-card-v2"),
+		},
+		{
+			name:         "last page",
+			query:        "limit=2&cursor=" + cursor("mnist-card-v2"),
+			expectedSC:   http.StatusOK,
+			expectedKeys: []string{"mnist-card-v3"},
+		},
+		{
+			name:         "page exactly filled",
+			query:        "limit=5",
+			expectedSC:   http.StatusOK,
+			expectedKeys: []string{"fraud-card", "granite-card", "mnist-card-v1", "mnist-card-v2", "mnist-card-v3"},
+		},
+		{
+			name:         "cursor of a removed key",
+			query:        "cursor=" + cursor("h"),
+			expectedSC:   http.StatusOK,
+			expectedKeys: []string{"mnist-card-v1", "mnist-card-v2", "mnist-card-v3"},
+		},
+		{
+			name:           "prefix",
+			query:          "prefix=mnist-&limit=1&cursor=" + cursor("mnist-card-v1"),
+			expectedSC:     http.StatusOK,
+			expectedKeys:   []string{"mnist-card-v2"},
+			expectedCursor: cursor("mnist-card-v2"),
+		},
+		{
+			name:       "invalid cursor",
+			query:      "cursor=not*base64",
+			expectedSC: http.StatusBadRequest,
+		},
+		{
+			name:       "zero limit",
+			query:      "limit=0",
+			expectedSC: http.StatusBadRequest,
+		},
+		{
+			name:       "limit above max",
+			query:      "limit=1001",
+			expectedSC: http.StatusBadRequest,
+		},
+	} {
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}}
+		for _, key := range []string{"mnist-card-v2", "granite-card", "mnist-card-v1", "fraud-card", "mnist-card-v3"} {
+			ils.modelcards[key] = newModelCardMetadata(key, plainCard, "1")
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: tc.query}}
+
+		ils.handleModelCardsGet(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if tc.expectedSC != http.StatusOK {
+			continue
+		}
+		resp := ModelCardsResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		keys := []string{}
+		for _, mc := range resp.ModelCards {
+			keys = append(keys, mc.Key)
+		}
+		common.AssertEqual(t, strings.Join(tc.expectedKeys, ","), strings.Join(keys, ","))
+		common.AssertEqual(t, tc.expectedCursor, resp.NextCursor)
+	}
+}
+
+func TestModelCardReconcile(t *testing.T) {
+	// Backstage has fetched the held card a few times, so it is no longer flagged
+	fetched := func() modelCardMetadata {
+		return modelCardMetadata{storageKey: "mnist_v1", content: "# mnist", lastUpdateTimeSinceEpoch: "1", serving: &modelCardServing{updateCount: 3}}
+	}
+	for _, tc := range []struct {
+		name              string
+		held              map[string]modelCardMetadata
+		content           string
+		lastUpdate        string
+		expectedFlag      bool
+		expectedCount     int
+		expectedContent   string
+		expectedTimestamp string
+	}{
+		{
+			name:              "unchanged card is not flagged",
+			held:              map[string]modelCardMetadata{"mnist-card": fetched()},
+			content:           "# mnist",
+			lastUpdate:        "1",
+			expectedCount:     3,
+			expectedContent:   "# mnist",
+			expectedTimestamp: "1",
+		},
+		{
+			name:              "unchanged card without a timestamp is not flagged",
+			held:              map[string]modelCardMetadata{"mnist-card": fetched()},
+			content:           "# mnist",
+			expectedCount:     3,
+			expectedContent:   "# mnist",
+			expectedTimestamp: "1",
+		},
+		{
+			name:              "changed timestamp is flagged",
+			held:              map[string]modelCardMetadata{"mnist-card": fetched()},
+			content:           "# mnist",
+			lastUpdate:        "2",
+			expectedFlag:      true,
+			expectedContent:   "# mnist",
+			expectedTimestamp: "2",
+		},
+		{
+			name:              "changed content is flagged",
+			held:              map[string]modelCardMetadata{"mnist-card": fetched()},
+			content:           "# mnist updated",
+			lastUpdate:        "1",
+			expectedFlag:      true,
+			expectedContent:   "# mnist updated",
+			expectedTimestamp: "1",
+		},
+		{
+			name:              "new card is flagged",
+			held:              map[string]modelCardMetadata{},
+			content:           "# mnist",
+			lastUpdate:        "1",
+			expectedFlag:      true,
+			expectedContent:   "# mnist",
+			expectedTimestamp: "1",
+		},
+	} {
+		isNew := len(tc.held) == 0
+		// the upsert pushed back by the storage service
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: tc.held}
+		_, err := ils.upsertKey("mnist_v1", "", false, rest.PostBody{Body: []byte(mnistEntity), ModelCardKey: "mnist-card", ModelCard: tc.content, LastUpdateTimeSinceEpoch: tc.lastUpdate})
+		common.AssertError(t, err)
+		mcm := ils.modelcards["mnist-card"]
+		if mcm.serving.pending() != tc.expectedFlag || mcm.serving.updateCount != tc.expectedCount {
+			t.Errorf("%s: upsert expected the flag %v and count %d but got %v and %d", tc.name, tc.expectedFlag, tc.expectedCount, mcm.serving.pending(), mcm.serving.updateCount)
+		}
+		common.AssertEqual(t, tc.expectedContent, mcm.content)
+		common.AssertEqual(t, tc.expectedTimestamp, mcm.lastUpdateTimeSinceEpoch)
+
+		// the refetch of the held card from storage
+		if isNew {
+			continue
+		}
+		st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte(mnistEntity)})
+		st.SetModelCard("mnist_v1", "mnist-card", tc.content, tc.lastUpdate)
+		ils = &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{"mnist-card": fetched()}, storage: st}
+		common.AssertEqual(t, true, ils.refetchModelCard("mnist-card", "mnist_v1"))
+		mcm = ils.modelcards["mnist-card"]
+		if mcm.serving.pending() != tc.expectedFlag || mcm.serving.updateCount != tc.expectedCount {
+			t.Errorf("%s: refetch expected the flag %v and count %d but got %v and %d", tc.name, tc.expectedFlag, tc.expectedCount, mcm.serving.pending(), mcm.serving.updateCount)
+		}
+		common.AssertEqual(t, tc.expectedContent, mcm.content)
+		common.AssertEqual(t, tc.expectedTimestamp, mcm.lastUpdateTimeSinceEpoch)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+// useModelCard marks the card for key as the most recently used, so it is the last to be evicted; callers must hold
+// the server lock, for reading at least
+func (i *ImportLocationServer) useModelCard(key string) {
+	if mcm, ok := i.modelcards[key]; ok {
+		mcm.serving.use(i.modelCardClock.Add(1))
+	}
+}
+
+// evictModelCards evicts the content of the least recently used cards while more than the maximum are resident,
+// keeping their metadata so that their content can be refetched from storage when next served.  Only cards with a
+// storage key can be evicted, and the card for keep never is.  Callers must hold the server lock.
+func (i *ImportLocationServer) evictModelCards(keep string) {
+	if i.modelCardMaxResident <= 0 {
+		return
+	}
+	resident := 0
+	for _, mcm := range i.modelcards {
+		if !mcm.evicted {
+			resident++
+		}
+	}
+	for resident > i.modelCardMaxResident {
+		victim := ""
+		var oldest uint64
+		for key, mcm := range i.modelcards {
+			if mcm.evicted || key == keep || len(mcm.storageKey) == 0 {
+				continue
+			}
+			if lastUsed := mcm.serving.used(); len(victim) == 0 || lastUsed < oldest {
+				victim, oldest = key, lastUsed
+			}
+		}
+		if len(victim) == 0 {
+			return
+		}
+		mcm := i.modelcards[victim]
+		mcm.content = ""
+		mcm.evicted = true
+		i.modelcards[victim] = mcm
+		resident--
+		klog.Infof("evicted the content of model card %s, to be refetched from storage key %s", victim, mcm.storageKey)
+	}
+}
+
+// restoreModelCard refetches the content of an evicted card from storage, returning whether the card is resident
+func (i *ImportLocationServer) restoreModelCard(key string) bool {
+	i.lock.RLock()
+	mcm, ok := i.modelcards[key]
+	i.lock.RUnlock()
+	if !ok || !mcm.evicted {
+		return ok
+	}
+
+	v, err, _ := i.fetches.Do(util.ModelCardURI+"/"+key, func() (interface{}, error) {
+		return i.fetchStorageBody(mcm.storageKey)
+	})
+	if err != nil {
+		klog.Errorf("refetch of evicted model card %s from storage key %s failed: %s", key, mcm.storageKey, err.Error())
+		return false
+	}
+	sb := v.(*types.StorageBody)
+	if sb.ModelCardKey != key {
+		klog.Errorf("storage key %s no longer has evicted model card %s", mcm.storageKey, key)
+		return false
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	mcm, ok = i.modelcards[key]
+	if !ok {
+		return false
+	}
+	if mcm.evicted {
+		mcm.content = i.heldModelCard(sb.ModelCard)
+		mcm.evicted = false
+		mcm.cachedAt = time.Now()
+		i.modelcards[key] = mcm
+	}
+	i.useModelCard(key)
+	i.evictModelCards(key)
+	return true
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestModelCardEviction(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{})
+	ils := &ImportLocationServer{
+		content:              map[string]*ImportLocation{},
+		modelcards:           map[string]modelCardMetadata{},
+		storage:              st,
+		modelCardMaxResident: 2,
+	}
+	upsertCard := func(key, cardKey, card string) {
+		st.SetContent(key, []byte(mnistEntity))
+		st.SetModelCard(key, cardKey, card, "1")
+		data, err := json.Marshal(rest.PostBody{Body: []byte(mnistEntity), ModelCardKey: cardKey, ModelCard: card, LastUpdateTimeSinceEpoch: "1"})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + key}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+	}
+	getCard := func(cardKey string) (int, string) {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + cardKey}}
+		ils.handleModelCardGet(ctx)
+		return ctx.Writer.Status(), testWriter.ResponseWriter.Body.String()
+	}
+	evicted := func() []string {
+		keys := []string{}
+		for _, k := range []string{"mnist-card", "granite-card", "llama-card"} {
+			if ils.modelcards[k].evicted {
+				keys = append(keys, k)
+			}
+		}
+		return keys
+	}
+
+	upsertCard("mnist_v1", "mnist-card", "# mnist")
+	upsertCard("granite_v1", "granite-card", "# granite")
+	common.AssertEqual(t, []string{}, evicted())
+
+	// the first card is least recently used once past the cap, keeping its metadata
+	upsertCard("llama_v1", "llama-card", "# llama")
+	common.AssertEqual(t, []string{"mnist-card"}, evicted())
+	common.AssertEqual(t, "", ils.modelcards["mnist-card"].content)
+	common.AssertEqual(t, "1", ils.modelcards["mnist-card"].lastUpdateTimeSinceEpoch)
+
+	// serving granite makes llama the least recently used, so refetching mnist evicts it
+	sc, body := getCard("granite-card")
+	common.AssertEqual(t, http.StatusOK, sc)
+	common.AssertEqual(t, "# granite", body)
+	sc, body = getCard("mnist-card")
+	common.AssertEqual(t, http.StatusOK, sc)
+	common.AssertEqual(t, "# mnist", body)
+	common.AssertEqual(t, 1, st.FetchCount("mnist_v1"))
+	common.AssertEqual(t, []string{"llama-card"}, evicted())
+
+	// a card that cannot be refetched is unavailable rather than served empty
+	st.FailKey("llama_v1", true)
+	sc, _ = getCard("llama-card")
+	common.AssertEqual(t, http.StatusServiceUnavailable, sc)
+	common.AssertEqual(t, []string{"llama-card"}, evicted())
+	st.FailKey("llama_v1", false)
+	sc, body = getCard("llama-card")
+	common.AssertEqual(t, http.StatusOK, sc)
+	common.AssertEqual(t, "# llama", body)
+	common.AssertEqual(t, 1, len(evicted()))
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fallbackModelCard caches the model card synthesized from a location's content, by the checksum of the content it
+// was synthesized from, so it is regenerated when the content changes
+type fallbackModelCard struct {
+	checksum string
+	card     []byte
+}
+
+// synthesizeModelCard writes a minimal markdown model card from the metadata of the model's entity in content, i.e.
+// its Component, or else its first named entity; content without Backstage entities yields no card
+func synthesizeModelCard(content []byte) ([]byte, bool) {
+	entities, err := parseEntities(content)
+	if err != nil {
+		return nil, false
+	}
+	var entity map[string]interface{}
+	for _, e := range entities {
+		if _, ok := entityRef(e); !ok {
+			continue
+		}
+		if kind, _ := e["kind"].(string); strings.EqualFold(kind, "component") {
+			entity = e
+			break
+		}
+		if entity == nil {
+			entity = e
+		}
+	}
+	if entity == nil {
+		return nil, false
+	}
+	metadata, _ := entity["metadata"].(map[string]interface{})
+	spec, _ := entity["spec"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	title, _ := metadata["title"].(string)
+	if len(title) == 0 {
+		title = name
+	}
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# %s\n\n", title)
+	if description, _ := metadata["description"].(string); len(strings.TrimSpace(description)) > 0 {
+		fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(description))
+	}
+	fmt.Fprintf(b, "- **Name:** %s\n", name)
+	if owner, _ := spec["owner"].(string); len(owner) > 0 {
+		fmt.Fprintf(b, "- **Owner:** %s\n", owner)
+	}
+	if tags, _ := metadata["tags"].([]interface{}); len(tags) > 0 {
+		strs := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			strs = append(strs, fmt.Sprintf("%v", tag))
+		}
+		fmt.Fprintf(b, "- **Tags:** %s\n", strings.Join(strs, ", "))
+	}
+	b.WriteString("\n_This model card was generated from the model's catalog entry, as none was provided._\n")
+	return []byte(b.String()), true
+}
+
+// fallbackModelCard returns the card synthesized from the content of the location for the model card key, taken as
+// the location's key, when fallback cards are enabled; the card is cached with the location until its content
+// changes.  Callers must hold the server lock, for reading at least.
+func (i *ImportLocationServer) fallbackModelCard(key string) ([]byte, bool) {
+	if !i.modelCardFallback {
+		return nil, false
+	}
+	segs := strings.Split(key, "_")
+	if len(segs) < 2 {
+		return nil, false
+	}
+	for _, uri := range i.candidateURIs(segs[0], segs[1]) {
+		il, ok := i.content[uri]
+		if !ok || il.content == nil {
+			continue
+		}
+		content := i.plaintext(il.content)
+		sum := i.checksum(content)
+		i.cacheLock.Lock()
+		cached := il.fallbackCard
+		i.cacheLock.Unlock()
+		if cached.checksum == sum {
+			return cached.card, cached.card != nil
+		}
+		card, ok := synthesizeModelCard(content)
+		i.cacheLock.Lock()
+		il.fallbackCard = fallbackModelCard{checksum: sum, card: card}
+		i.cacheLock.Unlock()
+		return card, ok
+	}
+	return nil, false
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+)
+
+const fraudEntity = `apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  name: fraud-v1
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: fraud
+  title: Fraud Detection
+  description: Flags suspicious transactions.
+  tags:
+    - finance
+    - onnx
+spec:
+  owner: risk-team
+`
+
+func TestHandleModelCardGetFallback(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		fallback     bool
+		key          string
+		expectedSC   int
+		expectedCard string
+	}{
+		{
+			name:       "synthesized from a populated entity",
+			fallback:   true,
+			key:        "fraud_v1",
+			expectedSC: http.StatusOK,
+			expectedCard: `# Fraud Detection
+
+Flags suspicious transactions.
+
+- **Name:** fraud
+- **Owner:** risk-team
+- **Tags:** finance, onnx
+
+_This model card was generated from the model's catalog entry, as none was provided._
+`,
+		},
+		{
+			name:       "disabled",
+			key:        "fraud_v1",
+			expectedSC: http.StatusNotFound,
+		},
+		{
+			name:       "no location",
+			fallback:   true,
+			key:        "granite_v1",
+			expectedSC: http.StatusNotFound,
+		},
+		{
+			name:       "content without entities",
+			fallback:   true,
+			key:        "iris_v1",
+			expectedSC: http.StatusNotFound,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content: map[string]*ImportLocation{
+				"/fraud/v1/catalog-info.yaml": {content: []byte(fraudEntity)},
+				"/iris/v1/catalog-info.yaml":  {content: []byte(`[{"modelName":"iris"}]`)},
+			},
+			modelcards:        map[string]modelCardMetadata{},
+			format:            types.CatalogInfoYamlFormat,
+			modelCardFallback: tc.fallback,
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + tc.key}}
+
+		ils.handleModelCardGet(ctx)
+
+		if ctx.Writer.Status() != tc.expectedSC {
+			t.Errorf("%s: expected status %d but got %d", tc.name, tc.expectedSC, ctx.Writer.Status())
+			continue
+		}
+		common.AssertEqual(t, tc.expectedCard, testWriter.ResponseWriter.Body.String())
+	}
+}
+
+func TestFallbackModelCardCache(t *testing.T) {
+	il := &ImportLocation{content: []byte(fraudEntity)}
+	ils := &ImportLocationServer{
+		content:           map[string]*ImportLocation{"/fraud/v1/catalog-info.yaml": il},
+		format:            types.CatalogInfoYamlFormat,
+		modelCardFallback: true,
+	}
+	card, ok := ils.fallbackModelCard("fraud_v1")
+	common.AssertEqual(t, true, ok)
+	common.AssertEqual(t, hashSHA256.sum([]byte(fraudEntity)), il.fallbackCard.checksum)
+	cached, _ := ils.fallbackModelCard("fraud_v1")
+	common.AssertEqual(t, &card[0], &cached[0])
+
+	// changed content is synthesized anew
+	il.content = []byte(mnistEntity)
+	card, ok = ils.fallbackModelCard("fraud_v1")
+	common.AssertEqual(t, true, ok)
+	common.AssertEqual(t, "# mnist\n\n- **Name:** mnist\n- **Owner:** rhdh-rhoai-bridge\n\n_This model card was generated from the model's catalog entry, as none was provided._\n", string(card))
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"golang.org/x/sync/semaphore"
+)
+
+func TestHandleModelCardsRefreshPost(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		query             string
+		expectedSC        int
+		expected          ModelCardsRefreshResponse
+		expectedRefetched []string
+	}{
+		{
+			name:              "stale cards",
+			expectedSC:        http.StatusOK,
+			expected:          ModelCardsRefreshResponse{Refreshed: 2, Failed: 1},
+			expectedRefetched: []string{"mnist_v1", "granite_v1", "fraud_v1"},
+		},
+		{
+			name:              "all cards",
+			query:             "?all=true",
+			expectedSC:        http.StatusOK,
+			expected:          ModelCardsRefreshResponse{Refreshed: 3, Failed: 1},
+			expectedRefetched: []string{"mnist_v1", "granite_v1", "fraud_v1", "iris_v1"},
+		},
+		{
+			name:       "bad parameter",
+			query:      "?all=maybe",
+			expectedSC: http.StatusBadRequest,
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{
+			"mnist_v1":   []byte("mnist"),
+			"granite_v1": []byte("granite"),
+			"fraud_v1":   []byte("fraud"),
+			"iris_v1":    []byte("iris"),
+			"llama_v1":   []byte("llama"),
+		})
+		st.FetchDelay = 10 * time.Millisecond
+		st.SetModelCard("mnist_v1", "mnist-card", "# mnist updated", "2")
+		st.SetModelCard("granite_v1", "granite-card", "# granite", "1")
+		st.SetModelCard("iris_v1", "iris-card", "# iris updated", "2")
+		st.SetModelCard("llama_v1", "llama-card", "# llama updated", "2")
+		st.FailKey("fraud_v1", true)
+		card := func(storageKey, content string, needToUpdate, evicted bool) modelCardMetadata {
+			return modelCardMetadata{storageKey: storageKey, content: content, lastUpdateTimeSinceEpoch: "1", serving: &modelCardServing{needToUpdate: needToUpdate}, evicted: evicted}
+		}
+		ils := &ImportLocationServer{
+			modelcards: map[string]modelCardMetadata{
+				// stale, and changed in storage
+				"mnist-card": card("mnist_v1", "# mnist", true, false),
+				// stale, and unchanged in storage
+				"granite-card": card("granite_v1", "# granite", true, false),
+				// stale, and failing to fetch
+				"fraud-card": card("fraud_v1", "# fraud", true, false),
+				// fresh, and changed in storage
+				"iris-card": card("iris_v1", "# iris", false, false),
+				// evicted
+				"llama-card": card("llama_v1", "", true, true),
+			},
+			storage:    st,
+			fetchSem:   semaphore.NewWeighted(2),
+			adminToken: "admin-secret",
+		}
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		r.POST(util.ModelCardsRefreshURI, adminAuth(ils.adminToken), ils.handleModelCardsRefreshPost)
+		req := httptest.NewRequest(http.MethodPost, util.ModelCardsRefreshURI+tc.query, nil)
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		common.AssertEqual(t, tc.expectedSC, rec.Code)
+		if tc.expectedSC != http.StatusOK {
+			continue
+		}
+		resp := ModelCardsRefreshResponse{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		common.AssertEqual(t, tc.expected, resp)
+		for _, key := range tc.expectedRefetched {
+			common.AssertEqual(t, 1, st.FetchCount(key))
+		}
+		common.AssertEqual(t, 0, st.FetchCount("llama_v1"))
+		// the fetches are bounded by the fetch semaphore
+		common.AssertEqual(t, true, st.MaxConcurrentFetches() <= 2)
+
+		common.AssertEqual(t, "# mnist updated", ils.modelcards["mnist-card"].content)
+		common.AssertEqual(t, "2", ils.modelcards["mnist-card"].lastUpdateTimeSinceEpoch)
+		common.AssertEqual(t, "# fraud", ils.modelcards["fraud-card"].content)
+		expectedIris := "# iris"
+		if len(tc.query) > 0 {
+			expectedIris = "# iris updated"
+		}
+		common.AssertEqual(t, expectedIris, ils.modelcards["iris-card"].content)
+		common.AssertEqual(t, "", ils.modelcards["llama-card"].content)
+	}
+}
+
+func TestHandleModelCardsReparsePost(t *testing.T) {
+	ils := &ImportLocationServer{
+		modelcards: map[string]modelCardMetadata{
+			// cached before its front-matter was extracted
+			"mnist-card": {content: "---\nlicense: apache-2.0\n---\n# mnist", frontMatter: nil},
+			// cached with front-matter the card no longer has
+			"granite-card": {content: "# granite", frontMatter: map[string]interface{}{"license": "mit"}},
+			"fraud-card":   {content: "---\nlicense: [\n---\n# fraud", frontMatter: map[string]interface{}{"license": "mit"}},
+			"iris-card":    {storageKey: "iris_v1", evicted: true, frontMatter: map[string]interface{}{"license": "mit"}},
+		},
+		adminToken: "admin-secret",
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.POST(util.ModelCardsReparseURI, adminAuth(ils.adminToken), ils.handleModelCardsReparsePost)
+	r.GET(util.ModelCardMetaURI, ils.handleModelCardMetaGet)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, util.ModelCardsReparseURI, nil))
+	common.AssertEqual(t, http.StatusUnauthorized, rec.Code)
+	common.AssertEqual(t, true, ils.modelcards["mnist-card"].frontMatter == nil)
+
+	req := httptest.NewRequest(http.MethodPost, util.ModelCardsReparseURI, nil)
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	resp := ModelCardsReparseResponse{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	common.AssertEqual(t, ModelCardsReparseResponse{Reparsed: 3, Failed: 1, Evicted: 1}, resp)
+
+	meta := func(key string) ModelCardMetaResponse {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ModelCardMetaURI+"?key="+key, nil))
+		common.AssertEqual(t, http.StatusOK, rec.Code)
+		m := ModelCardMetaResponse{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), &m))
+		return m
+	}
+	common.AssertEqual(t, map[string]interface{}{"license": "apache-2.0"}, meta("mnist-card").FrontMatter)
+	common.AssertEqual(t, true, meta("granite-card").FrontMatter == nil)
+	common.AssertEqual(t, true, meta("fraud-card").FrontMatter == nil)
+	// evicted cards are left to be parsed when refetched
+	common.AssertEqual(t, map[string]interface{}{"license": "mit"}, meta("iris-card").FrontMatter)
+}
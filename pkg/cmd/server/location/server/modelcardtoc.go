@@ -0,0 +1,127 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+// ModelCardHeading is an entry of a model card's table of contents, with the anchor the heading is rendered with
+type ModelCardHeading struct {
+	Text   string `json:"text"`
+	Level  int    `json:"level"`
+	Anchor string `json:"anchor"`
+}
+
+type ModelCardTOCResponse struct {
+	Key      string             `json:"key"`
+	Headings []ModelCardHeading `json:"headings"`
+}
+
+// parseTOC returns the ATX headings, i.e. '## Usage', of a markdown model card in order, skipping its front-matter
+// and fenced code blocks
+func parseTOC(card string) []ModelCardHeading {
+	headings := []ModelCardHeading{}
+	lines := strings.Split(strings.ReplaceAll(card, "\r\n", "\n"), "\n")
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == frontMatterDelimiter {
+		for idx := 1; idx < len(lines); idx++ {
+			if strings.TrimSpace(lines[idx]) == frontMatterDelimiter {
+				start = idx + 1
+				break
+			}
+		}
+	}
+	anchors := map[string]int{}
+	fence := ""
+	for _, line := range lines[start:] {
+		trimmed := strings.TrimSpace(line)
+		if len(fence) > 0 {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		// headings are indented by at most three spaces
+		if len(line)-len(strings.TrimLeft(line, " ")) > 3 {
+			continue
+		}
+		level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+		if level < 1 || level > 6 || (len(trimmed) > level && trimmed[level] != ' ' && trimmed[level] != '\t') {
+			continue
+		}
+		text := strings.TrimSpace(trimmed[level:])
+		// an optional closing sequence of '#'s is not part of the heading
+		if closed := strings.TrimRight(text, "#"); len(closed) < len(text) && (len(closed) == 0 || strings.HasSuffix(closed, " ")) {
+			text = strings.TrimSpace(closed)
+		}
+		if len(text) == 0 {
+			continue
+		}
+		headings = append(headings, ModelCardHeading{Text: text, Level: level, Anchor: headingAnchor(text, anchors)})
+	}
+	return headings
+}
+
+// headingAnchor returns the anchor a heading is rendered with, as GitHub does: lower case, with punctuation other than
+// '-' and '_' dropped and spaces replaced with '-', suffixed with '-1', '-2', etc. when repeated; seen counts the
+// anchors returned so far
+func headingAnchor(text string, seen map[string]int) string {
+	b := strings.Builder{}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	anchor := b.String()
+	n := seen[anchor]
+	seen[anchor] = n + 1
+	if n > 0 {
+		anchor += "-" + strconv.Itoa(n)
+	}
+	return anchor
+}
+
+// handleModelCardTOCGet responds with the table of contents of the model card with the key parameter
+func (i *ImportLocationServer) handleModelCardTOCGet(c *gin.Context) {
+	key := c.Query(util.KeyQueryParam)
+	if !i.restoreModelCard(key) {
+		i.lock.RLock()
+		_, ok := i.modelcards[key]
+		i.lock.RUnlock()
+		if ok {
+			c.Status(http.StatusServiceUnavailable)
+			c.Error(fmt.Errorf("the evicted model card %s could not be refetched from storage", key))
+			return
+		}
+	}
+	i.lock.RLock()
+	mcm, ok := i.modelcards[key]
+	i.lock.RUnlock()
+	if !ok {
+		klog.Infof("no model card found for %s", key)
+		c.Status(http.StatusNotFound)
+		return
+	}
+	content, err := json.Marshal(&ModelCardTOCResponse{Key: key, Headings: parseTOC(mcm.content)})
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
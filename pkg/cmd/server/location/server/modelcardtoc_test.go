@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleModelCardTOCGet(t *testing.T) {
+	nested := `---
+license: apache-2.0
+# not a heading
+---
+# MNIST Classifier
+
+## Model Details
+### Developed By
+Some text with a # in it.
+### Model Type ###
+## Uses
+` + "```python\n# not a heading either\n```" + `
+## Uses
+####### too deep
+#hashtag
+    # indented code
+## Evaluation: Results & Metrics!
+`
+	ils := &ImportLocationServer{
+		modelcards: map[string]modelCardMetadata{
+			"mnist-card": newModelCardMetadata("mnist-card", nested, "1"),
+			"plain-card": newModelCardMetadata("plain-card", "Just a description, no headings.\n", "1"),
+		},
+	}
+	for _, tc := range []struct {
+		name       string
+		key        string
+		expectedSC int
+		expected   []ModelCardHeading
+	}{
+		{
+			name:       "nested headings",
+			key:        "mnist-card",
+			expectedSC: http.StatusOK,
+			expected: []ModelCardHeading{
+				{Text: "MNIST Classifier", Level: 1, Anchor: "mnist-classifier"},
+				{Text: "Model Details", Level: 2, Anchor: "model-details"},
+				{Text: "Developed By", Level: 3, Anchor: "developed-by"},
+				{Text: "Model Type", Level: 3, Anchor: "model-type"},
+				{Text: "Uses", Level: 2, Anchor: "uses"},
+				{Text: "Uses", Level: 2, Anchor: "uses-1"},
+				{Text: "Evaluation: Results & Metrics!", Level: 2, Anchor: "evaluation-results--metrics"},
+			},
+		},
+		{
+			name:       "no headings",
+			key:        "plain-card",
+			expectedSC: http.StatusOK,
+			expected:   []ModelCardHeading{},
+		},
+		{
+			name:       "missing card",
+			key:        "fraud-card",
+			expectedSC: http.StatusNotFound,
+		},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + tc.key}}
+		ils.handleModelCardTOCGet(ctx)
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if tc.expectedSC != http.StatusOK {
+			continue
+		}
+		resp := ModelCardTOCResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		common.AssertEqual(t, tc.key, resp.Key)
+		common.AssertEqual(t, tc.expected, resp.Headings)
+	}
+}
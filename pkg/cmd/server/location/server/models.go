@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+// ModelVersions lists the versions of a model that are served
+type ModelVersions struct {
+	Model    string   `json:"model"`
+	Versions []string `json:"versions"`
+}
+
+type ModelsResponse struct {
+	Models []ModelVersions `json:"models"`
+}
+
+// handleModelsGet responds with the served locations grouped by model, with the models and their versions sorted.
+// Models whose versions have all been removed are omitted, unless the server is configured, or the empty
+// query parameter asks, to list them with no versions.
+func (i *ImportLocationServer) handleModelsGet(c *gin.Context) {
+	if i.readThrough != nil {
+		c.Status(http.StatusNotImplemented)
+		c.Error(fmt.Errorf("listing models is not supported in read-through mode"))
+		return
+	}
+	includeEmpty := i.modelsIncludeEmpty
+	if raw := c.Query(util.EmptyQueryParam); len(raw) > 0 {
+		var err error
+		includeEmpty, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			c.Error(fmt.Errorf("the %s query parameter must be a boolean: %s", util.EmptyQueryParam, err.Error()))
+			return
+		}
+	}
+	t := i.uriTemplate.Load()
+	if t == nil {
+		t, _ = parseURITemplate(defaultURITemplate)
+	}
+
+	i.lock.RLock()
+	versions := map[string]map[string]bool{}
+	for uri, il := range i.content {
+		model, version, _, ok := t.parse(uri)
+		if !ok {
+			klog.Errorf("URI %s does not match the URI template %s", uri, t.String())
+			continue
+		}
+		if _, ok = versions[model]; !ok {
+			versions[model] = map[string]bool{}
+		}
+		// removed locations are kept with nil content, so a model is known even when none of its versions are served
+		if il.content != nil {
+			versions[model][version] = true
+		}
+	}
+	i.lock.RUnlock()
+
+	resp := &ModelsResponse{Models: []ModelVersions{}}
+	for model, served := range versions {
+		if len(served) == 0 && !includeEmpty {
+			continue
+		}
+		mv := ModelVersions{Model: model, Versions: []string{}}
+		for version := range served {
+			mv.Versions = append(mv.Versions, version)
+		}
+		sort.Strings(mv.Versions)
+		resp.Models = append(resp.Models, mv)
+	}
+	sort.Slice(resp.Models, func(a, b int) bool {
+		return resp.Models[a].Model < resp.Models[b].Model
+	})
+	content, err := json.Marshal(resp)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
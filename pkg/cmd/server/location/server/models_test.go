@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestHandleModelsGet(t *testing.T) {
+	content := map[string]*ImportLocation{
+		"/mnist/v1/catalog-info.yaml":   {content: []byte("mnist v1")},
+		"/mnist/v2/catalog-info.yaml":   {},
+		"/fraud/v1/catalog-info.yaml":   {},
+		"/fraud/v2/catalog-info.yaml":   {},
+		"/granite/v3/catalog-info.yaml": {content: []byte("granite v3")},
+	}
+	for _, tc := range []struct {
+		name         string
+		includeEmpty bool
+		query        string
+		expectedSC   int
+		expected     []ModelVersions
+	}{
+		{
+			name:       "models with all versions removed omitted",
+			expectedSC: http.StatusOK,
+			expected: []ModelVersions{
+				{Model: "granite", Versions: []string{"v3"}},
+				{Model: "mnist", Versions: []string{"v1"}},
+			},
+		},
+		{
+			name:         "models with all versions removed included",
+			includeEmpty: true,
+			expectedSC:   http.StatusOK,
+			expected: []ModelVersions{
+				{Model: "fraud", Versions: []string{}},
+				{Model: "granite", Versions: []string{"v3"}},
+				{Model: "mnist", Versions: []string{"v1"}},
+			},
+		},
+		{
+			name:       "included per request",
+			query:      "empty=true",
+			expectedSC: http.StatusOK,
+			expected: []ModelVersions{
+				{Model: "fraud", Versions: []string{}},
+				{Model: "granite", Versions: []string{"v3"}},
+				{Model: "mnist", Versions: []string{"v1"}},
+			},
+		},
+		{
+			name:         "omitted per request",
+			includeEmpty: true,
+			query:        "empty=false",
+			expectedSC:   http.StatusOK,
+			expected: []ModelVersions{
+				{Model: "granite", Versions: []string{"v3"}},
+				{Model: "mnist", Versions: []string{"v1"}},
+			},
+		},
+		{
+			name:       "bad parameter",
+			query:      "empty=sometimes",
+			expectedSC: http.StatusBadRequest,
+		},
+	} {
+		ils := &ImportLocationServer{content: content, modelsIncludeEmpty: tc.includeEmpty}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: tc.query}}
+		ils.handleModelsGet(ctx)
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if tc.expectedSC != http.StatusOK {
+			continue
+		}
+		resp := ModelsResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		common.AssertEqual(t, tc.expected, resp.Models)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+)
+
+// namespacedStorageClient is the storage the location service reads from, writes to and deletes from
+type namespacedStorageClient interface {
+	storageClient
+	storageWriter
+	storageDeleter
+}
+
+// namespacedStorage isolates the keys of location service instances sharing a storage backend by prepending a prefix
+// to the keys read from, written to and deleted from storage; only the keys under the prefix are listed, and they
+// are listed without it, so the URIs derived from them are those of an unshared backend
+type namespacedStorage struct {
+	prefix string
+	client namespacedStorageClient
+}
+
+func newNamespacedStorage(prefix string, client namespacedStorageClient) *namespacedStorage {
+	return &namespacedStorage{prefix: prefix, client: client}
+}
+
+// keys returns the keys under the prefix, without it
+func (n *namespacedStorage) keys(storageKeys []string) []string {
+	keys := []string{}
+	for _, sk := range storageKeys {
+		if key, ok := strings.CutPrefix(sk, n.prefix); ok && len(key) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// strip removes the prefix from a key pushed back by the storage service, leaving keys without it as they are
+func (n *namespacedStorage) strip(key string) string {
+	if n == nil {
+		return key
+	}
+	return strings.TrimPrefix(key, n.prefix)
+}
+
+func (n *namespacedStorage) ListModelsKeys() (int, string, error, []string) {
+	rc, msg, err, storageKeys := n.client.ListModelsKeys()
+	if err != nil {
+		return rc, msg, err, nil
+	}
+	return rc, msg, err, n.keys(storageKeys)
+}
+
+func (n *namespacedStorage) FetchModel(key string) (int, string, error, []byte) {
+	return n.client.FetchModel(n.prefix + key)
+}
+
+func (n *namespacedStorage) UpsertModel(importKey, normalizerType, lastUpdateTimeSinceEpoch, modelCardKey string, modelCard *string, buf []byte) (int, string, *rest.PostBody, error) {
+	return n.client.UpsertModel(n.prefix+importKey, normalizerType, lastUpdateTimeSinceEpoch, modelCardKey, modelCard, buf)
+}
+
+func (n *namespacedStorage) DeleteModel(importKey string) (int, string, error) {
+	return n.client.DeleteModel(n.prefix + importKey)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestNamespacedStorage(t *testing.T) {
+	// one storage backend shared by two instances, along with keys of neither
+	st := stubstorage.NewStubStorageClient(map[string][]byte{
+		"team-a.mnist_v1": []byte("team a mnist"),
+		"team-b.mnist_v1": []byte("team b mnist"),
+		"team-b.fraud_v1": []byte("team b fraud"),
+		"granite_v3":      []byte("granite"),
+	})
+	newInstance := func(prefix string) *ImportLocationServer {
+		ns := newNamespacedStorage(prefix, st)
+		return &ImportLocationServer{
+			content:           map[string]*ImportLocation{},
+			modelcards:        map[string]modelCardMetadata{},
+			storage:           ns,
+			namespace:         ns,
+			deleter:           ns,
+			writeBehind:       newWriteBehind(ns, time.Hour),
+			storageDeleteMode: storageDeleteStrict,
+			format:            types.CatalogInfoYamlFormat,
+		}
+	}
+	a, b := newInstance("team-a."), newInstance("team-b.")
+
+	// each instance loads only the keys under its prefix, served at the URIs of the keys without it
+	for _, tc := range []struct {
+		ils      *ImportLocationServer
+		expected map[string]string
+	}{
+		{ils: a, expected: map[string]string{"/mnist/v1/catalog-info.yaml": "team a mnist"}},
+		{ils: b, expected: map[string]string{"/mnist/v1/catalog-info.yaml": "team b mnist", "/fraud/v1/catalog-info.yaml": "team b fraud"}},
+	} {
+		_, err := tc.ils.reloadFromStorage()
+		common.AssertError(t, err)
+		common.AssertEqual(t, len(tc.expected), len(tc.ils.content))
+		for uri, content := range tc.expected {
+			common.AssertEqual(t, content, string(tc.ils.content[uri].content))
+		}
+	}
+
+	// upserts are written under the instance's prefix
+	data, err := json.Marshal(rest.PostBody{Body: []byte("team a fraud")})
+	common.AssertError(t, err)
+	ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=fraud_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+	a.handleCatalogUpsertPost(ctx)
+	common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+	a.writeBehind.flush()
+	_, _, _, keys := st.ListModelsKeys()
+	common.AssertEqual(t, []string{"granite_v3", "team-a.fraud_v1", "team-a.mnist_v1", "team-b.fraud_v1", "team-b.mnist_v1"}, keys)
+	_, err = b.reloadFromStorage()
+	common.AssertError(t, err)
+	common.AssertEqual(t, "team b fraud", string(b.content["/fraud/v1/catalog-info.yaml"].content))
+
+	// the upsert pushed back by the storage service with the prefix is that of the key without it
+	ctx, _ = gin.CreateTestContext(testgin.NewTestResponseWriter())
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=team-a.fraud_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+	a.handleCatalogUpsertPost(ctx)
+	common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+	_, ok := a.content["/team-a.fraud/v1/catalog-info.yaml"]
+	common.AssertEqual(t, false, ok)
+
+	// removals delete only the instance's key
+	ctx, _ = gin.CreateTestContext(testgin.NewTestResponseWriter())
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}}
+	a.handleCatalogDelete(ctx)
+	common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+	common.AssertEqual(t, 1, st.DeleteCount("team-a.mnist_v1"))
+	common.AssertEqual(t, 0, st.DeleteCount("team-b.mnist_v1"))
+	_, err = b.reloadFromStorage()
+	common.AssertError(t, err)
+	common.AssertEqual(t, "team b mnist", string(b.content["/mnist/v1/catalog-info.yaml"].content))
+}
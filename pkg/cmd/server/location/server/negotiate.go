@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// maxServedVariants bounds the number of transformed copies of a location's content cached for serving
+const maxServedVariants = 4
+
+// servedVariant is a location's content as transformed for serving in a negotiated variant, where the empty variant
+// is the content's own representation; checksum is that of the content it was transformed from
+type servedVariant struct {
+	checksum string
+	variant  string
+	content  []byte
+}
+
+// servedCache caches the transformed content of a location by content checksum and negotiated variant, so repeated
+// identical requests reuse the transformed bytes; it holds the most recently used maxServedVariants, and only those
+// of the current content.  The zero value is an empty cache.
+type servedCache struct {
+	entries []servedVariant
+}
+
+// get returns the cached content for checksum and variant, marking it most recently used
+func (sc *servedCache) get(checksum, variant string) ([]byte, bool) {
+	for idx, e := range sc.entries {
+		if e.checksum == checksum && e.variant == variant {
+			copy(sc.entries[1:idx+1], sc.entries[:idx])
+			sc.entries[0] = e
+			return e.content, true
+		}
+	}
+	return nil, false
+}
+
+// put caches content for checksum and variant, dropping the entries of other content, which has since changed, and
+// the least recently used beyond maxServedVariants
+func (sc *servedCache) put(checksum, variant string, content []byte) {
+	entries := []servedVariant{{checksum: checksum, variant: variant, content: content}}
+	for _, e := range sc.entries {
+		if e.checksum == checksum && e.variant != variant && len(entries) < maxServedVariants {
+			entries = append(entries, e)
+		}
+	}
+	sc.entries = entries
+}
+
+// representation returns the content type of the representation content of format is stored in, i.e. YAML or JSON
+func representation(format types.NormalizerFormat) string {
+	if ct, ok := defaultContentTypes[format]; ok {
+		return ct
+	}
+	return mimeJSON
+}
+
+// negotiateVariant selects the variant a lookup of content of format is served in from the request's Accept
+// header, along with its content type: the stored representation, as the empty variant, or its YAML or JSON
+// alternative.  Without content negotiation, or an acceptable alternative, the stored representation is served.
+func (i *ImportLocationServer) negotiateVariant(c *gin.Context, format types.NormalizerFormat) (string, string) {
+	if !i.contentNegotiation {
+		return "", i.contentTypeFor(format)
+	}
+	c.Header("Vary", "Accept")
+	stored := representation(format)
+	alternative := mimeYAML
+	if stored == mimeYAML {
+		alternative = mimeJSON
+	}
+	if c.NegotiateFormat(stored, alternative) == alternative {
+		return alternative, alternative
+	}
+	return "", i.contentTypeFor(format)
+}
+
+// convertVariant converts content to the representation of variant; YAML streams of several entities become a
+// JSON array
+func convertVariant(content []byte, variant string) ([]byte, error) {
+	switch variant {
+	case mimeJSON:
+		if isJSON(content) {
+			return content, nil
+		}
+		entities, err := parseEntities(content)
+		if err != nil {
+			return nil, fmt.Errorf("converting content to JSON failed: %s", err.Error())
+		}
+		if len(entities) == 1 {
+			return json.Marshal(entities[0])
+		}
+		return json.Marshal(entities)
+	case mimeYAML:
+		if !isJSON(content) {
+			return content, nil
+		}
+		converted, err := yaml.JSONToYAML(content)
+		if err != nil {
+			return nil, fmt.Errorf("converting content to YAML failed: %s", err.Error())
+		}
+		return converted, nil
+	}
+	return content, nil
+}
+
+// prettyJSON re-indents JSON content for readability; content that is not valid JSON is returned as is
+func prettyJSON(content []byte) []byte {
+	if !isJSON(content) {
+		return content
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Indent(buf, content, "", "  "); err != nil {
+		return content
+	}
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// servedContent returns the content to serve for a location in variant, decrypted, applying the serve transformers
+// and converting it to the variant, along with the variant served.  The result is cached with the location by the
+// content's checksum and the variant.  If the transformers fail, the stored content is served, and if the
+// conversion fails, the transformed content in its own representation is; neither is cached.  Callers must hold
+// the server lock, for reading at least; the cache is guarded by the cache lock, which is not held while
+// transforming.
+func (i *ImportLocationServer) servedContent(uri string, il *ImportLocation, variant string) ([]byte, string) {
+	content := i.plaintext(il.content)
+	if content == nil || (len(i.serveTransformers) == 0 && len(variant) == 0) {
+		return content, ""
+	}
+	sum := i.checksum(content)
+	i.cacheLock.Lock()
+	served, ok := il.served.get(sum, variant)
+	i.cacheLock.Unlock()
+	if ok {
+		return i.plaintext(served), variant
+	}
+	served, err := i.serveTransformers.Transform(uri, content)
+	if err != nil {
+		klog.Error(err.Error())
+		return content, ""
+	}
+	converted, err := convertVariant(served, variant)
+	if err != nil {
+		klog.Errorf("serving content of %s as stored: %s", uri, err.Error())
+		return served, ""
+	}
+	i.cacheLock.Lock()
+	il.served.put(sum, variant, i.cipher.seal(converted))
+	i.cacheLock.Unlock()
+	return converted, variant
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+)
+
+func TestHandleCatalogLookupGetNegotiated(t *testing.T) {
+	const stored = "kind: Component\nmetadata:\n  name: mnist\n"
+	for _, tc := range []struct {
+		name                string
+		negotiation         bool
+		accept              string
+		expectedContent     string
+		expectedContentType string
+	}{
+		{
+			name:                "negotiation disabled",
+			accept:              mimeJSON,
+			expectedContent:     stored,
+			expectedContentType: mimeYAML,
+		},
+		{
+			name:                "no accept header",
+			negotiation:         true,
+			expectedContent:     stored,
+			expectedContentType: mimeYAML,
+		},
+		{
+			name:                "yaml accepted",
+			negotiation:         true,
+			accept:              "application/yaml, application/json;q=0.5",
+			expectedContent:     stored,
+			expectedContentType: mimeYAML,
+		},
+		{
+			name:                "json accepted",
+			negotiation:         true,
+			accept:              mimeJSON,
+			expectedContent:     `{"kind":"Component","metadata":{"name":"mnist"}}`,
+			expectedContentType: mimeJSON,
+		},
+		{
+			name:                "neither accepted",
+			negotiation:         true,
+			accept:              "text/html",
+			expectedContent:     stored,
+			expectedContentType: mimeYAML,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:            map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(stored)}},
+			format:             types.CatalogInfoYamlFormat,
+			contentNegotiation: tc.negotiation,
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{}, Header: http.Header{}}
+		if len(tc.accept) > 0 {
+			ctx.Request.Header.Set("Accept", tc.accept)
+		}
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: catalogInfoFileName}}
+
+		ils.handleCatalogLookupGet(ctx)
+
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedContent, testWriter.ResponseWriter.Body.String())
+		common.AssertEqual(t, tc.expectedContentType, testWriter.Header().Get("Content-Type"))
+	}
+}
+
+func TestServedContentCache(t *testing.T) {
+	calls := 0
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte("kind: Component\nmetadata:\n  name: mnist\n")},
+		},
+		format:             types.CatalogInfoYamlFormat,
+		contentNegotiation: true,
+	}
+	ils.AddServeTransformer(ServeTransformerFunc(func(uri string, content []byte) ([]byte, error) {
+		calls++
+		return content, nil
+	}))
+	lookup := func(accept string) string {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{}, Header: http.Header{"Accept": []string{accept}}}
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: catalogInfoFileName}}
+		ils.handleCatalogLookupGet(ctx)
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		return testWriter.ResponseWriter.Body.String()
+	}
+
+	// repeated identical negotiated requests are cache hits
+	for range 3 {
+		common.AssertEqual(t, "kind: Component\nmetadata:\n  name: mnist\n", lookup(mimeYAML))
+	}
+	common.AssertEqual(t, 1, calls)
+	for range 3 {
+		common.AssertEqual(t, `{"kind":"Component","metadata":{"name":"mnist"}}`, lookup(mimeJSON))
+	}
+	common.AssertEqual(t, 2, calls)
+	il := ils.content["/mnist/v1/catalog-info.yaml"]
+	common.AssertEqual(t, 2, len(il.served.entries))
+
+	// changed content invalidates the cached variants
+	il.content = []byte("kind: Component\nmetadata:\n  name: fraud\n")
+	common.AssertEqual(t, `{"kind":"Component","metadata":{"name":"fraud"}}`, lookup(mimeJSON))
+	common.AssertEqual(t, 3, calls)
+	common.AssertEqual(t, 1, len(il.served.entries))
+	lookup(mimeJSON)
+	common.AssertEqual(t, 3, calls)
+}
+
+func TestServedCacheBound(t *testing.T) {
+	sc := servedCache{}
+	for n := range maxServedVariants + 2 {
+		sc.put("sha256:a", fmt.Sprintf("variant-%d", n), []byte{byte(n)})
+	}
+	common.AssertEqual(t, maxServedVariants, len(sc.entries))
+	// the least recently used are evicted
+	_, ok := sc.get("sha256:a", "variant-0")
+	common.AssertEqual(t, false, ok)
+	_, ok = sc.get("sha256:a", "variant-2")
+	common.AssertEqual(t, true, ok)
+	sc.put("sha256:a", "variant-6", nil)
+	_, ok = sc.get("sha256:a", "variant-2")
+	common.AssertEqual(t, true, ok)
+	_, ok = sc.get("sha256:a", "variant-3")
+	common.AssertEqual(t, false, ok)
+}
+
+func TestConvertVariant(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		content   string
+		variant   string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "own representation",
+			content:  "kind: Component\n",
+			expected: "kind: Component\n",
+		},
+		{
+			name:     "yaml stream to json array",
+			content:  "kind: Component\n---\nkind: API\n",
+			variant:  mimeJSON,
+			expected: `[{"kind":"Component"},{"kind":"API"}]`,
+		},
+		{
+			name:     "json array to yaml",
+			content:  `[{"kind":"Component"}]`,
+			variant:  mimeYAML,
+			expected: "- kind: Component\n",
+		},
+		{
+			name:      "malformed yaml",
+			content:   "kind: [Component\n",
+			variant:   mimeJSON,
+			expectErr: true,
+		},
+	} {
+		converted, err := convertVariant([]byte(tc.content), tc.variant)
+		common.AssertEqual(t, tc.expectErr, err != nil)
+		if !tc.expectErr {
+			common.AssertEqual(t, tc.expected, string(converted))
+		}
+	}
+}
+
+func TestHandleCatalogLookupGetPretty(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		content         string
+		query           string
+		expectedContent string
+	}{
+		{
+			name:            "raw json",
+			content:         `{"kind":"Component","metadata":{"name":"mnist"}}`,
+			expectedContent: `{"kind":"Component","metadata":{"name":"mnist"}}`,
+		},
+		{
+			name:            "pretty json",
+			content:         `{"kind":"Component","metadata":{"name":"mnist"}}`,
+			query:           "pretty=true",
+			expectedContent: "{\n  \"kind\": \"Component\",\n  \"metadata\": {\n    \"name\": \"mnist\"\n  }\n}\n",
+		},
+		{
+			name:            "pretty disabled",
+			content:         `{"kind":"Component"}`,
+			query:           "pretty=false",
+			expectedContent: `{"kind":"Component"}`,
+		},
+		{
+			name:            "yaml unchanged",
+			content:         "kind: Component\nmetadata:\n  name: mnist\n",
+			query:           "pretty=true",
+			expectedContent: "kind: Component\nmetadata:\n  name: mnist\n",
+		},
+		{
+			name:            "invalid json unchanged",
+			content:         `{"kind":`,
+			query:           "pretty=true",
+			expectedContent: `{"kind":`,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content: map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(tc.content)}},
+			format:  types.CatalogInfoYamlFormat,
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: tc.query}}
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: catalogInfoFileName}}
+
+		ils.handleCatalogLookupGet(ctx)
+
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedContent, testWriter.ResponseWriter.Body.String())
+		// the stored content is left untouched
+		common.AssertEqual(t, tc.content, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+	}
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"bytes"
+)
+
+// normalizeWhitespace converts CRLF and lone CR line endings to LF, and strips the spaces and tabs trailing each line,
+// so content posted from different operating systems or editors compares, and checksums, equal when it differs
+// only in those
+func normalizeWhitespace(content []byte) []byte {
+	if len(content) == 0 {
+		return content
+	}
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	content = bytes.ReplaceAll(content, []byte("\r"), []byte("\n"))
+	lines := bytes.Split(content, []byte("\n"))
+	for idx, line := range lines {
+		lines[idx] = bytes.TrimRight(line, " \t")
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestNormalizeWhitespace(t *testing.T) {
+	lf := "apiVersion: backstage.io/v1alpha1\nkind: Component\nmetadata:\n  name: mnist\n"
+	crlf := "apiVersion: backstage.io/v1alpha1 \r\nkind: Component\t\r\nmetadata:\r\n  name: mnist  \r\n"
+	common.AssertEqual(t, lf, string(normalizeWhitespace([]byte(crlf))))
+	common.AssertEqual(t, lf, string(normalizeWhitespace([]byte(strings.ReplaceAll(lf, "\n", "\r")))))
+	common.AssertEqual(t, lf, string(normalizeWhitespace([]byte(lf))))
+
+	for _, tc := range []struct {
+		name          string
+		normalize     bool
+		expectChanged bool
+	}{
+		{
+			name:      "normalized variants checksum equal",
+			normalize: true,
+		},
+		{
+			name:          "variants differ without normalization",
+			expectChanged: true,
+		},
+	} {
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, normalizeWhitespace: tc.normalize}
+		sc, err := ils.upsertKey("mnist_v1", "", false, rest.PostBody{Body: []byte(lf)})
+		common.AssertError(t, err)
+		common.AssertEqual(t, http.StatusCreated, sc)
+		lfSum := hashSHA256.sum(ils.content["/mnist/v1/catalog-info.yaml"].content)
+
+		sc, err = ils.upsertKey("mnist_v1", "", false, rest.PostBody{Body: []byte(crlf)})
+		common.AssertError(t, err)
+		common.AssertEqual(t, http.StatusCreated, sc)
+		crlfSum := hashSHA256.sum(ils.content["/mnist/v1/catalog-info.yaml"].content)
+		if (lfSum != crlfSum) != tc.expectChanged {
+			t.Errorf("%s: expected the checksums to differ %v but got %s and %s", tc.name, tc.expectChanged, lfSum, crlfSum)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// maxNotFoundSuggestions bounds the URIs suggested by a lookup's 404
+const maxNotFoundSuggestions = 5
+
+// NotFoundResponse guides a client whose lookup found nothing to the discovery endpoint, and to the URIs of other
+// versions of the model it looked up, if any are served
+type NotFoundResponse struct {
+	Message     string   `json:"message"`
+	Discovery   string   `json:"discovery"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// suggestURIs returns the served URIs of the model's other versions, matching the model case-insensitively, sorted
+// and at most maxNotFoundSuggestions of them; callers must hold the server lock
+func (i *ImportLocationServer) suggestURIs(model string) []string {
+	t := i.uriTemplate.Load()
+	if t == nil {
+		t, _ = parseURITemplate(defaultURITemplate)
+	}
+	suggestions := []string{}
+	for uri, il := range i.content {
+		if il.content == nil {
+			continue
+		}
+		if m, _, _, ok := t.parse(uri); ok && strings.EqualFold(m, model) {
+			suggestions = append(suggestions, uri)
+		}
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > maxNotFoundSuggestions {
+		suggestions = suggestions[:maxNotFoundSuggestions]
+	}
+	return suggestions
+}
+
+// lookupNotFound fails a lookup of a model version with a 404 which, with not found guidance enabled, has a body
+// pointing the client to the discovery endpoint and suggesting the URIs of the model's other versions
+func (i *ImportLocationServer) lookupNotFound(c *gin.Context, model, version string) {
+	if !i.lookupNotFoundGuidance {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	resp := &NotFoundResponse{
+		Message:   fmt.Sprintf("version %s of model %s is not in the catalog; discover the URIs it serves from %s", version, model, util.ListURI),
+		Discovery: util.ListURI,
+	}
+	if i.lockForRead(c) {
+		resp.Suggestions = i.suggestURIs(model)
+		i.lock.RUnlock()
+	}
+	content, err := json.Marshal(resp)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusNotFound, "Content-Type: application/json", content)
+}
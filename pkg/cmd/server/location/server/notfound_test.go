@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestLookupNotFound(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		guidance         bool
+		path             string
+		expectedResponse *NotFoundResponse
+	}{
+		{
+			name: "plain 404 by default",
+			path: "/mnist/v2/catalog-info.yaml",
+		},
+		{
+			name:     "other versions of the model suggested",
+			guidance: true,
+			path:     "/MNIST/v2/catalog-info.yaml",
+			expectedResponse: &NotFoundResponse{
+				Message:     "version v2 of model MNIST is not in the catalog; discover the URIs it serves from /list",
+				Discovery:   util.ListURI,
+				Suggestions: []string{"/mnist/v1/catalog-info.yaml", "/mnist/v3/catalog-info.yaml"},
+			},
+		},
+		{
+			name:     "no other versions of the model",
+			guidance: true,
+			path:     "/llama/v1/catalog-info.yaml",
+			expectedResponse: &NotFoundResponse{
+				Message:   "version v1 of model llama is not in the catalog; discover the URIs it serves from /list",
+				Discovery: util.ListURI,
+			},
+		},
+	} {
+		ils := &ImportLocationServer{
+			content: map[string]*ImportLocation{
+				"/mnist/v1/catalog-info.yaml":   {content: []byte(mnistEntity)},
+				"/mnist/v3/catalog-info.yaml":   {content: []byte(mnistUpdatedEntity)},
+				"/mnist/v4/catalog-info.yaml":   {},
+				"/granite/v2/catalog-info.yaml": {content: []byte(graniteEntity)},
+			},
+			lookupNotFoundGuidance: tc.guidance,
+		}
+		r := gin.New()
+		r.GET("/:model/:version/:format", ils.handleCatalogLookupGet)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		common.AssertEqual(t, http.StatusNotFound, rec.Code)
+		if tc.expectedResponse == nil {
+			common.AssertEqual(t, 0, rec.Body.Len())
+			continue
+		}
+		resp := &NotFoundResponse{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+		common.AssertEqual(t, tc.expectedResponse, resp)
+	}
+}
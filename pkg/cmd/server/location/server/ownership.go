@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"k8s.io/klog/v2"
+)
+
+// entityOwnership records the source that first created each entity reference, trusting it on first use, so that a
+// different source claiming the same entity, most likely by mistake, is rejected.  When backed by a file, the owners
+// are loaded from it and it is rewritten as they change, so the claims survive restarts.  Its owners are guarded by
+// the server lock.
+type entityOwnership struct {
+	path   string
+	owners map[string]string
+}
+
+// newEntityOwnership creates the ownership record, loading the owners from path when set and the file exists
+func newEntityOwnership(path string) (*entityOwnership, error) {
+	o := &entityOwnership{path: path, owners: map[string]string{}}
+	if len(path) == 0 {
+		return o, nil
+	}
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return o, fmt.Errorf("reading entity ownership file %s failed: %s", path, err.Error())
+	}
+	if err = json.Unmarshal(buf, &o.owners); err != nil {
+		return o, fmt.Errorf("parsing entity ownership file %s failed: %s", path, err.Error())
+	}
+	return o, nil
+}
+
+// check returns an error if any of the entity references is owned by a source other than source; a nil record, or
+// an unknown source, checks nothing.  Callers must hold the server lock.
+func (o *entityOwnership) check(refs []string, source string) error {
+	if o == nil || len(source) == 0 {
+		return nil
+	}
+	for _, ref := range refs {
+		if owner, ok := o.owners[ref]; ok && owner != source {
+			return fmt.Errorf("entity %s is owned by source %s, which first created it", ref, owner)
+		}
+	}
+	return nil
+}
+
+// claim records source as the owner of the entity references not yet owned, or of all of them when transfer is
+// set, persisting the owners when they change.  Callers must hold the server lock.
+func (o *entityOwnership) claim(refs []string, source string, transfer bool) {
+	if o == nil || len(source) == 0 {
+		return
+	}
+	changed := false
+	for _, ref := range refs {
+		if owner, ok := o.owners[ref]; !ok || (transfer && owner != source) {
+			o.owners[ref] = source
+			changed = true
+		}
+	}
+	if changed {
+		o.persist()
+	}
+}
+
+// persist writes the owners to a temporary file renamed over the file, so a crash cannot leave it truncated
+func (o *entityOwnership) persist() {
+	if len(o.path) == 0 {
+		return
+	}
+	buf, err := json.Marshal(o.owners)
+	if err == nil {
+		err = os.WriteFile(o.path+".tmp", buf, 0600)
+	}
+	if err == nil {
+		err = os.Rename(o.path+".tmp", o.path)
+	}
+	if err != nil {
+		klog.Errorf("persisting entity ownership to %s failed: %s", o.path, err.Error())
+	}
+}
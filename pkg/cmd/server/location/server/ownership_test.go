@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestEntityOwnership(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owners.json")
+	ownership, err := newEntityOwnership(path)
+	common.AssertError(t, err)
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{},
+		ownership:  ownership,
+	}
+
+	for _, tc := range []struct {
+		name          string
+		key           string
+		source        string
+		override      bool
+		body          string
+		expectedSC    int
+		expectedOwner string
+	}{
+		{
+			name:          "first claim",
+			key:           "mnist_v1",
+			source:        types.KServeNormalizer,
+			body:          mnistEntity,
+			expectedSC:    http.StatusCreated,
+			expectedOwner: types.KServeNormalizer,
+		},
+		{
+			name:          "same source update",
+			key:           "mnist_v1",
+			source:        types.KServeNormalizer,
+			body:          mnistUpdatedEntity,
+			expectedSC:    http.StatusCreated,
+			expectedOwner: types.KServeNormalizer,
+		},
+		{
+			name:          "conflicting source",
+			key:           "mnist_v1",
+			source:        types.KubeflowNormalizer,
+			body:          mnistEntity,
+			expectedSC:    http.StatusConflict,
+			expectedOwner: types.KServeNormalizer,
+		},
+		{
+			name:          "unknown source is not checked",
+			key:           "mnist_v1",
+			body:          mnistEntity,
+			expectedSC:    http.StatusCreated,
+			expectedOwner: types.KServeNormalizer,
+		},
+		{
+			name:          "override transfers ownership",
+			key:           "mnist_v1",
+			source:        types.KubeflowNormalizer,
+			override:      true,
+			body:          mnistUpdatedEntity,
+			expectedSC:    http.StatusCreated,
+			expectedOwner: types.KubeflowNormalizer,
+		},
+	} {
+		sc, err := ils.upsertKey(tc.key, tc.source, tc.override, rest.PostBody{Body: []byte(tc.body)})
+		if sc != tc.expectedSC {
+			t.Errorf("%s: expected status %d but got %d: %v", tc.name, tc.expectedSC, sc, err)
+		}
+		common.AssertEqual(t, tc.expectedOwner, ownership.owners["component:default/mnist"])
+	}
+	common.AssertEqual(t, mnistUpdatedEntity, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+
+	// the owners are persisted and loaded on restart
+	restarted, err := newEntityOwnership(path)
+	common.AssertError(t, err)
+	common.AssertEqual(t, ownership.owners, restarted.owners)
+	common.AssertContains(t, restarted.check([]string{"component:default/mnist"}, types.KServeNormalizer).Error(), []string{"owned by source kubeflow"})
+
+	// without a file the owners are held in memory only
+	inMemory, err := newEntityOwnership("")
+	common.AssertError(t, err)
+	inMemory.claim([]string{"component:default/mnist"}, types.KServeNormalizer, false)
+	common.AssertEqual(t, map[string]string{"component:default/mnist": types.KServeNormalizer}, inMemory.owners)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// page is a request for a page of a sorted list of keys, starting after the key encoded in the cursor
+type page struct {
+	limit  int
+	after  string
+	prefix string
+}
+
+// parsePage reads the limit, cursor and prefix query parameters of a paginated list request
+func parsePage(c *gin.Context) (page, error) {
+	p := page{limit: defaultPageLimit, prefix: c.Query(util.PrefixQueryParam)}
+	if str := c.Query(util.LimitQueryParam); len(str) > 0 {
+		limit, err := strconv.Atoi(str)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return p, fmt.Errorf("the '%s' parameter must be between 1 and %d: %s", util.LimitQueryParam, maxPageLimit, str)
+		}
+		p.limit = limit
+	}
+	if str := c.Query(util.CursorQueryParam); len(str) > 0 {
+		after, err := base64.RawURLEncoding.DecodeString(str)
+		if err != nil {
+			return p, fmt.Errorf("invalid '%s' parameter %s: %s", util.CursorQueryParam, str, err.Error())
+		}
+		p.after = string(after)
+	}
+	return p, nil
+}
+
+// apply sorts the keys and returns those in the page, along with the cursor for the next page, which is empty when
+// there are no further pages; since the cursor is the last key returned rather than an offset, paging remains stable
+// as keys are added or removed
+func (p page) apply(keys []string) ([]string, string) {
+	sort.Strings(keys)
+	start := sort.SearchStrings(keys, p.after)
+	if start < len(keys) && keys[start] == p.after && len(p.after) > 0 {
+		start++
+	}
+	selected := []string{}
+	for _, key := range keys[start:] {
+		if !strings.HasPrefix(key, p.prefix) {
+			continue
+		}
+		if len(selected) == p.limit {
+			return selected, base64.RawURLEncoding.EncodeToString([]byte(selected[len(selected)-1]))
+		}
+		selected = append(selected, key)
+	}
+	return selected, ""
+}
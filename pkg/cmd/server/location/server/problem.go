@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+const mimeProblemJSON = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body, extended with the ID of the request, which the dead letter record of
+// a rejected upsert shares, so a client error can be traced to the record
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestId string `json:"requestId,omitempty"`
+}
+
+// problemDetails answers the mutating requests a handler rejects or fails without writing a body with a problem
+// details body, detailing the last error the handler recorded, as the dead letter log does
+func problemDetails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		sc := c.Writer.Status()
+		e := c.Errors.Last()
+		if c.Writer.Written() || sc < http.StatusBadRequest || e == nil {
+			return
+		}
+		content, err := json.Marshal(&Problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(sc),
+			Status:    sc,
+			Detail:    e.Error(),
+			Instance:  c.Request.URL.Path,
+			RequestId: c.GetString("requestId"),
+		})
+		if err != nil {
+			klog.Errorf("encoding the problem details of %s failed: %s", c.Request.URL.Path, err.Error())
+			return
+		}
+		c.Data(sc, mimeProblemJSON, content)
+	}
+}
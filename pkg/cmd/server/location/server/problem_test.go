@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestProblemDetails(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		requestId      string
+		method         string
+		path           string
+		body           string
+		expectedSC     int
+		expectedDetail string
+		deadLettered   bool
+	}{
+		{
+			name:           "rejected upsert with a client request ID",
+			requestId:      "client-id-1",
+			method:         http.MethodPost,
+			path:           util.UpsertURI + "?key=badkey",
+			body:           "kind: Component\n",
+			expectedSC:     http.StatusBadRequest,
+			expectedDetail: "bad key format: badkey",
+			deadLettered:   true,
+		},
+		{
+			name:           "rejected upsert with a generated request ID",
+			method:         http.MethodPost,
+			path:           util.UpsertURI,
+			body:           "kind: Component\n",
+			expectedSC:     http.StatusBadRequest,
+			expectedDetail: "need a 'key' parameter",
+			deadLettered:   true,
+		},
+		{
+			name:           "rejected removal",
+			method:         http.MethodDelete,
+			path:           util.RemoveURI + "?key=badkey",
+			expectedSC:     http.StatusBadRequest,
+			expectedDetail: "bad key format: badkey",
+		},
+	} {
+		deadLetters := &bytes.Buffer{}
+		ils := &ImportLocationServer{
+			content:     map[string]*ImportLocation{},
+			modelcards:  map[string]modelCardMetadata{},
+			deadLetters: newDeadLetterWriter(deadLetters),
+		}
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		ils.registerRoutes(r, nil)
+		var body io.Reader
+		if len(tc.body) > 0 {
+			data, err := json.Marshal(rest.PostBody{Body: []byte(tc.body)})
+			common.AssertError(t, err)
+			body = bytes.NewReader(data)
+		}
+		req := httptest.NewRequest(tc.method, tc.path, body)
+		if len(tc.requestId) > 0 {
+			req.Header.Set(defaultRequestIdHeader, tc.requestId)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		common.AssertEqual(t, tc.expectedSC, rec.Code)
+		common.AssertEqual(t, mimeProblemJSON, rec.Header().Get("Content-Type"))
+		problem := &Problem{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), problem))
+		common.AssertEqual(t, tc.expectedSC, problem.Status)
+		common.AssertEqual(t, tc.expectedDetail, problem.Detail)
+		requestId := rec.Header().Get(defaultRequestIdHeader)
+		if len(tc.requestId) > 0 {
+			common.AssertEqual(t, tc.requestId, requestId)
+		}
+		common.AssertEqual(t, requestId, problem.RequestId)
+		if !tc.deadLettered {
+			common.AssertEqual(t, 0, deadLetters.Len())
+			continue
+		}
+		// the dead letter record of the rejection shares the request ID and reason
+		dl := DeadLetter{}
+		common.AssertError(t, json.Unmarshal(deadLetters.Bytes(), &dl))
+		common.AssertEqual(t, problem.RequestId, dl.RequestId)
+		common.AssertEqual(t, problem.Detail, dl.Reason)
+	}
+
+	// successful mutations are answered as before
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	ils.registerRoutes(r, nil)
+	data, err := json.Marshal(rest.PostBody{Body: []byte(mnistEntity)})
+	common.AssertError(t, err)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, util.UpsertURI+"?key=mnist_v1", bytes.NewReader(data)))
+	common.AssertEqual(t, http.StatusCreated, rec.Code)
+	common.AssertEqual(t, 0, rec.Body.Len())
+}
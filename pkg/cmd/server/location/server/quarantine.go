@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultQuarantineBaseBackoff = 30 * time.Second
+	defaultQuarantineMaxBackoff  = time.Hour
+)
+
+// quarantine tracks consecutive fetch failures per storage key; once a key reaches the failure threshold, reconciles
+// skip it for a backoff period that doubles with each further failure, up to the max backoff.  A nil quarantine
+// never skips a key.
+type quarantine struct {
+	lock        sync.Mutex
+	threshold   int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+	keys        map[string]*quarantineEntry
+}
+
+type quarantineEntry struct {
+	failures int
+	until    time.Time
+}
+
+func newQuarantine(threshold int, baseBackoff, maxBackoff time.Duration) *quarantine {
+	return &quarantine{
+		threshold:   threshold,
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+		now:         time.Now,
+		keys:        map[string]*quarantineEntry{},
+	}
+}
+
+// skip returns true if the key is quarantined and its backoff has not yet elapsed
+func (q *quarantine) skip(key string) bool {
+	if q == nil {
+		return false
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	e, ok := q.keys[key]
+	return ok && q.now().Before(e.until)
+}
+
+// failure records a failed fetch of the key, quarantining it once it has reached the failure threshold
+func (q *quarantine) failure(key string) {
+	if q == nil {
+		return
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	e, ok := q.keys[key]
+	if !ok {
+		e = &quarantineEntry{}
+		q.keys[key] = e
+	}
+	e.failures++
+	if e.failures < q.threshold {
+		return
+	}
+	backoff := q.baseBackoff
+	for n := q.threshold; n < e.failures && backoff < q.maxBackoff; n++ {
+		backoff *= 2
+	}
+	if backoff > q.maxBackoff {
+		backoff = q.maxBackoff
+	}
+	e.until = q.now().Add(backoff)
+	klog.Infof("storage key %s quarantined for %s after %d consecutive fetch failures", key, backoff.String(), e.failures)
+	q.updateMetric()
+}
+
+// success clears any failures recorded for the key
+func (q *quarantine) success(key string) {
+	if q == nil {
+		return
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if _, ok := q.keys[key]; !ok {
+		return
+	}
+	delete(q.keys, key)
+	q.updateMetric()
+}
+
+// updateMetric sets the quarantined keys gauge; callers must hold the quarantine lock
+func (q *quarantine) updateMetric() {
+	count := 0
+	for _, e := range q.keys {
+		if e.failures >= q.threshold {
+			count++
+		}
+	}
+	storageKeysQuarantined.Set(float64(count))
+}
+
+type QuarantinedKey struct {
+	Key      string    `json:"key"`
+	Failures int       `json:"failures"`
+	Until    time.Time `json:"until"`
+}
+
+// list returns the keys that have reached the failure threshold, sorted by key
+func (q *quarantine) list() []QuarantinedKey {
+	keys := []QuarantinedKey{}
+	if q == nil {
+		return keys
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for key, e := range q.keys {
+		if e.failures < q.threshold {
+			continue
+		}
+		keys = append(keys, QuarantinedKey{Key: key, Failures: e.failures, Until: e.until})
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a].Key < keys[b].Key
+	})
+	return keys
+}
+
+type QuarantineResponse struct {
+	Keys []QuarantinedKey `json:"keys"`
+}
+
+func (i *ImportLocationServer) handleQuarantineGet(c *gin.Context) {
+	content, err := json.Marshal(&QuarantineResponse{Keys: i.quarantine.list()})
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestQuarantineBackoff(t *testing.T) {
+	now := time.Unix(1000, 0)
+	q := newQuarantine(2, 10*time.Second, 35*time.Second)
+	q.now = func() time.Time { return now }
+	st := stubstorage.NewStubStorageClient(map[string][]byte{
+		"mnist_v1": []byte(mnistEntity),
+		"mnist_v2": []byte(mnistUpdatedEntity),
+	})
+	st.FailKey("mnist_v1", true)
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, storage: st, quarantine: q}
+
+	for _, tc := range []struct {
+		name            string
+		advance         time.Duration
+		fixed           bool
+		expectedFetches int
+		expectedLoaded  bool
+		expectedKeys    []QuarantinedKey
+	}{
+		{
+			name:            "first failure is below the threshold",
+			expectedFetches: 1,
+			expectedKeys:    []QuarantinedKey{},
+		},
+		{
+			name:            "threshold reached quarantines for the base backoff",
+			expectedFetches: 2,
+			expectedKeys:    []QuarantinedKey{{Key: "mnist_v1", Failures: 2, Until: time.Unix(1010, 0)}},
+		},
+		{
+			name:            "skipped while quarantined",
+			advance:         5 * time.Second,
+			expectedFetches: 2,
+			expectedLoaded:  true,
+			expectedKeys:    []QuarantinedKey{{Key: "mnist_v1", Failures: 2, Until: time.Unix(1010, 0)}},
+		},
+		{
+			name:            "retried after the backoff and the backoff doubles",
+			advance:         5 * time.Second,
+			expectedFetches: 3,
+			expectedKeys:    []QuarantinedKey{{Key: "mnist_v1", Failures: 3, Until: time.Unix(1030, 0)}},
+		},
+		{
+			name:            "backoff capped at the max",
+			advance:         20 * time.Second,
+			expectedFetches: 4,
+			expectedKeys:    []QuarantinedKey{{Key: "mnist_v1", Failures: 4, Until: time.Unix(1065, 0)}},
+		},
+		{
+			name:            "success clears the quarantine",
+			advance:         35 * time.Second,
+			fixed:           true,
+			expectedFetches: 5,
+			expectedLoaded:  true,
+			expectedKeys:    []QuarantinedKey{},
+		},
+	} {
+		now = now.Add(tc.advance)
+		if tc.fixed {
+			st.FailKey("mnist_v1", false)
+		}
+		loaded, err := ils.loadFromStorage()
+		common.AssertError(t, err)
+		common.AssertEqual(t, tc.expectedLoaded, loaded)
+		common.AssertEqual(t, tc.expectedFetches, st.FetchCount("mnist_v1"))
+		common.AssertEqual(t, tc.expectedKeys, q.list())
+
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{}}
+		ils.handleQuarantineGet(ctx)
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		resp := QuarantineResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		common.AssertEqual(t, len(tc.expectedKeys), len(resp.Keys))
+	}
+	_, ok := ils.content["/mnist/v1/catalog-info.yaml"]
+	common.AssertEqual(t, true, ok)
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+const defaultReadThroughCacheMaxEntries = 1000
+
+// readThroughCache caches, by storage key, the locations fetched from storage in read-through mode for at most ttl,
+// holding at most maxEntries of them and evicting the oldest first, so memory stays bounded however large the
+// catalog is.  A zero ttl caches nothing, so every lookup fetches from storage.
+type readThroughCache struct {
+	lock       sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+	entries    map[string]readThroughEntry
+	// order is the cached keys, oldest first
+	order []string
+}
+
+type readThroughEntry struct {
+	fetched *fetchedLocation
+	expires time.Time
+}
+
+func newReadThroughCache(ttl time.Duration, maxEntries int) *readThroughCache {
+	return &readThroughCache{ttl: ttl, maxEntries: maxEntries, now: time.Now, entries: map[string]readThroughEntry{}}
+}
+
+// get returns the location cached for key, unless it has expired
+func (r *readThroughCache) get(key string) (*fetchedLocation, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	e, ok := r.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !r.now().Before(e.expires) {
+		r.remove(key)
+		return nil, false
+	}
+	return e.fetched, true
+}
+
+// put caches the location fetched for key, evicting the oldest entries beyond maxEntries
+func (r *readThroughCache) put(key string, fetched *fetchedLocation) {
+	if r.ttl <= 0 || r.maxEntries <= 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.remove(key)
+	r.entries[key] = readThroughEntry{fetched: fetched, expires: r.now().Add(r.ttl)}
+	r.order = append(r.order, key)
+	for len(r.order) > r.maxEntries {
+		delete(r.entries, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// evict drops any location cached for key, so the next lookup fetches it from storage; a nil cache has nothing to
+// evict
+func (r *readThroughCache) evict(key string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.remove(key)
+}
+
+// remove drops key from the cache; callers must hold the cache lock
+func (r *readThroughCache) remove(key string) {
+	if _, ok := r.entries[key]; !ok {
+		return
+	}
+	delete(r.entries, key)
+	for idx, k := range r.order {
+		if k == key {
+			r.order = append(r.order[:idx], r.order[idx+1:]...)
+			break
+		}
+	}
+}
+
+// fetchThrough fetches the location for a key from storage in read-through mode, or takes it from the short-lived
+// cache, returning it if it is served at the looked up URI
+func (i *ImportLocationServer) fetchThrough(seg1, seg2, key, uri string) (*ImportLocation, bool, error) {
+	fetched, err := i.resolveThrough(seg1, seg2, key)
+	if err != nil || fetched == nil || fetched.uri != uri {
+		return nil, false, err
+	}
+	return fetched.il, true, nil
+}
+
+// resolveThrough returns the location for a key, and the URI it is served at in the format of its content, from the
+// short-lived cache or else fetched from storage, or nil if storage does not have the key; concurrent fetches of the
+// same key are coalesced
+func (i *ImportLocationServer) resolveThrough(seg1, seg2, key string) (*fetchedLocation, error) {
+	if fetched, ok := i.readThrough.get(key); ok {
+		return fetched, nil
+	}
+	v, err, _ := i.fetches.Do("readthrough:"+key, func() (interface{}, error) {
+		sb, err := i.fetchStorageBody(key)
+		// the storage service returns an empty body for keys it does not have
+		if err != nil || len(sb.Body) == 0 {
+			return nil, err
+		}
+		_, fetchedURI := i.buildKeyAndURI(seg1, seg2, i.formatFor(sb.Body))
+		f := &fetchedLocation{uri: fetchedURI, il: i.newFetchedLocation(sb)}
+		i.readThrough.put(key, f)
+		return f, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read-through fetch of key %s failed: %s", key, err.Error())
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return v.(*fetchedLocation), nil
+}
+
+// upsertThrough completes an upsert in read-through mode: storage, which pushes the upsert, is the source of truth,
+// so the content is not held, only any cached copy dropped, while its model card is held as with any upsert
+func (u *ImportLocationServer) upsertThrough(key string, postBody rest.PostBody, cardContent string, holdCard, override bool) (int, error) {
+	u.readThrough.evict(key)
+	if !holdCard || len(postBody.ModelCardKey) == 0 {
+		return http.StatusCreated, nil
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err := u.checkModelCardKey(postBody.ModelCardKey, key, override); err != nil {
+		klog.Error(err.Error())
+		return http.StatusConflict, err
+	}
+	u.holdModelCard(key, postBody, cardContent, override)
+	return http.StatusCreated, nil
+}
+
+// upsertModelCardThrough completes an upsert of only the model card of key in read-through mode, where the location
+// is not held: the location is fetched from storage, which is written the card along with its unchanged content, and
+// the card is held as with any upsert
+func (u *ImportLocationServer) upsertModelCardThrough(key string, segs []string, postBody rest.PostBody, cardContent string, override bool) (int, error) {
+	fetched, err := u.resolveThrough(segs[0], segs[1], key)
+	if err != nil {
+		klog.Error(err.Error())
+		return http.StatusServiceUnavailable, err
+	}
+	if fetched == nil {
+		return http.StatusNotFound, fmt.Errorf("no location for key %s to update the model card of", key)
+	}
+	u.readThrough.evict(key)
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err = u.checkModelCardKey(postBody.ModelCardKey, key, override); err != nil {
+		klog.Error(err.Error())
+		return http.StatusConflict, err
+	}
+	u.holdModelCard(key, postBody, cardContent, override)
+	if prev := fetched.il.modelCardKey; len(prev) > 0 && prev != postBody.ModelCardKey {
+		u.unlinkModelCard(prev, key, fetched.uri)
+	}
+	u.writeBehind.enqueue(key, fetched.il.source, rest.PostBody{
+		Body:                     u.plaintext(fetched.il.content),
+		ModelCardKey:             postBody.ModelCardKey,
+		ModelCard:                cardContent,
+		LastUpdateTimeSinceEpoch: postBody.LastUpdateTimeSinceEpoch,
+	})
+	klog.Infof("Upserting model card %s of len %d for key %s read through", postBody.ModelCardKey, len(postBody.ModelCard), key)
+	return http.StatusCreated, nil
+}
+
+// discoverThrough responds with the URIs of the keys storage lists in read-through mode, in the configured format or,
+// with auto-detection, in the format of each key's content, as lookups resolve them.  Nothing is known of a key's source or update time without fetching it, so discovery by source, detailed
+// discovery and time windows are not supported.
+func (i *ImportLocationServer) discoverThrough(c *gin.Context, source string) {
+	detailed, _ := strconv.ParseBool(c.Query(util.DetailedQueryParam))
+	if len(source) > 0 || detailed || len(c.Query(util.SinceQueryParam)) > 0 || len(c.Query(util.UntilQueryParam)) > 0 {
+		c.Status(http.StatusNotImplemented)
+		c.Error(fmt.Errorf("discovery by source, detailed discovery and time windows are not supported in read-through mode"))
+		return
+	}
+	keys, ok := i.listStorageKeys()
+	if !ok {
+		c.Status(http.StatusServiceUnavailable)
+		c.Error(fmt.Errorf("listing the keys in storage failed"))
+		return
+	}
+	d := &DicoveryResponse{}
+	for _, key := range keys {
+		segs := strings.Split(key, "_")
+		if len(segs) < 2 {
+			klog.Errorf("bad format for key from ListModelsKeys when splitting with '_': %s", key)
+			continue
+		}
+		_, uri := i.buildKeyAndURI(segs[0], segs[1], i.format)
+		if i.formatAutoDetect {
+			fetched, err := i.resolveThrough(segs[0], segs[1], key)
+			if err != nil {
+				c.Status(http.StatusServiceUnavailable)
+				c.Error(err)
+				return
+			}
+			if fetched == nil {
+				continue
+			}
+			uri = fetched.uri
+		}
+		d.Uris = append(d.Uris, uri)
+	}
+	content, err := i.shapeDiscovery(c, d.Uris)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	if content == nil {
+		content, err = json.Marshal(d)
+	}
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
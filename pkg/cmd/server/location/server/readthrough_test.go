@@ -0,0 +1,255 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func readThroughLookup(t *testing.T, ils *ImportLocationServer, model string) (int, string) {
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{}, Header: http.Header{}}
+	ctx.Params = gin.Params{{Key: "model", Value: model}, {Key: "version", Value: "v1"}, {Key: "format", Value: catalogInfoFileName}}
+	ils.handleCatalogLookupGet(ctx)
+	return ctx.Writer.Status(), testWriter.ResponseWriter.Body.String()
+}
+
+func TestReadThroughLookup(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		ttl             time.Duration
+		lookups         int
+		expectedFetches int
+	}{
+		{
+			name:            "no caching",
+			lookups:         3,
+			expectedFetches: 3,
+		},
+		{
+			name:            "short-lived caching",
+			ttl:             time.Minute,
+			lookups:         3,
+			expectedFetches: 1,
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("kind: Component\n")})
+		ils := &ImportLocationServer{
+			content:     map[string]*ImportLocation{},
+			storage:     st,
+			format:      types.CatalogInfoYamlFormat,
+			readThrough: newReadThroughCache(tc.ttl, defaultReadThroughCacheMaxEntries),
+		}
+		for range tc.lookups {
+			sc, body := readThroughLookup(t, ils, "mnist")
+			common.AssertEqual(t, http.StatusOK, sc)
+			common.AssertEqual(t, "kind: Component\n", body)
+		}
+		common.AssertEqual(t, tc.expectedFetches, st.FetchCount("mnist_v1"))
+		// nothing is held in content
+		common.AssertEqual(t, 0, len(ils.content))
+
+		// changes in storage are served once the cached copy is dropped by an upsert
+		st.SetContent("mnist_v1", []byte("kind: API\n"))
+		data, err := json.Marshal(rest.PostBody{Body: []byte("kind: API\n")})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+		common.AssertEqual(t, 0, len(ils.content))
+		_, body := readThroughLookup(t, ils, "mnist")
+		common.AssertEqual(t, "kind: API\n", body)
+
+		sc, _ := readThroughLookup(t, ils, "fraud")
+		common.AssertEqual(t, http.StatusNotFound, sc)
+
+		st.FailKey("mnist_v1", true)
+		ils.readThrough.evict("mnist_v1")
+		sc, _ = readThroughLookup(t, ils, "mnist")
+		common.AssertEqual(t, http.StatusServiceUnavailable, sc)
+	}
+}
+
+func TestReadThroughCacheBounded(t *testing.T) {
+	const maxEntries = 5
+	storageContent := map[string][]byte{}
+	for n := range 50 {
+		storageContent[fmt.Sprintf("model%d_v1", n)] = []byte(fmt.Sprintf("model%d", n))
+	}
+	st := stubstorage.NewStubStorageClient(storageContent)
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		storage:     st,
+		format:      types.CatalogInfoYamlFormat,
+		readThrough: newReadThroughCache(time.Minute, maxEntries),
+	}
+	for n := range 50 {
+		sc, body := readThroughLookup(t, ils, fmt.Sprintf("model%d", n))
+		common.AssertEqual(t, http.StatusOK, sc)
+		common.AssertEqual(t, fmt.Sprintf("model%d", n), body)
+		common.AssertEqual(t, true, len(ils.readThrough.entries) <= maxEntries)
+	}
+	common.AssertEqual(t, maxEntries, len(ils.readThrough.order))
+	common.AssertEqual(t, 0, len(ils.content))
+	// the most recently fetched are cached, the oldest evicted
+	readThroughLookup(t, ils, "model49")
+	common.AssertEqual(t, 1, st.FetchCount("model49_v1"))
+	readThroughLookup(t, ils, "model0")
+	common.AssertEqual(t, 2, st.FetchCount("model0_v1"))
+}
+
+func TestReadThroughCacheExpiry(t *testing.T) {
+	now := time.Now()
+	r := newReadThroughCache(time.Minute, defaultReadThroughCacheMaxEntries)
+	r.now = func() time.Time { return now }
+	r.put("mnist_v1", &fetchedLocation{uri: "/mnist/v1/catalog-info.yaml"})
+	_, ok := r.get("mnist_v1")
+	common.AssertEqual(t, true, ok)
+	now = now.Add(time.Minute)
+	_, ok = r.get("mnist_v1")
+	common.AssertEqual(t, false, ok)
+	common.AssertEqual(t, 0, len(r.entries))
+	common.AssertEqual(t, 0, len(r.order))
+}
+
+func TestReadThroughDiscovery(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("mnist"), "fraud_v2": []byte("fraud")})
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		storage:     st,
+		format:      types.CatalogInfoYamlFormat,
+		readThrough: newReadThroughCache(0, defaultReadThroughCacheMaxEntries),
+	}
+	for _, tc := range []struct {
+		name         string
+		query        string
+		expectedSC   int
+		expectedUris []string
+	}{
+		{
+			name:         "storage keys",
+			expectedSC:   http.StatusOK,
+			expectedUris: []string{"/fraud/v2/catalog-info.yaml", "/mnist/v1/catalog-info.yaml"},
+		},
+		{
+			name:       "detailed",
+			query:      "detailed=true",
+			expectedSC: http.StatusNotImplemented,
+		},
+		{
+			name:       "window",
+			query:      "since=2025-01-01T00:00:00Z",
+			expectedSC: http.StatusNotImplemented,
+		},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: tc.query}}
+		ils.handleCatalogDiscoveryGet(ctx)
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		if tc.expectedSC != http.StatusOK {
+			continue
+		}
+		resp := DicoveryResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		common.AssertEqual(t, len(tc.expectedUris), len(resp.Uris))
+		for idx, uri := range tc.expectedUris {
+			common.AssertEqual(t, uri, resp.Uris[idx])
+		}
+	}
+	// discovery fetches no content
+	common.AssertEqual(t, 0, st.FetchCount("mnist_v1"))
+}
+
+func TestReadThroughModelCards(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("kind: Component\n")})
+	st.SetModelCard("mnist_v1", "mnist-card", "# mnist", "1")
+	ils := &ImportLocationServer{
+		content:               map[string]*ImportLocation{},
+		modelcards:            map[string]modelCardMetadata{},
+		storage:               st,
+		format:                types.CatalogInfoYamlFormat,
+		modelCardKeyConflicts: true,
+		readThrough:           newReadThroughCache(0, defaultReadThroughCacheMaxEntries),
+	}
+	data, err := json.Marshal(rest.PostBody{Body: []byte("kind: Component\n"), ModelCardKey: "mnist-card", ModelCard: "# mnist", LastUpdateTimeSinceEpoch: "1"})
+	common.AssertError(t, err)
+	ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+	ils.handleCatalogUpsertPost(ctx)
+	common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+	// the content is not held but the model card is
+	common.AssertEqual(t, 0, len(ils.content))
+	mcm, ok := ils.modelcards["mnist-card"]
+	common.AssertEqual(t, true, ok)
+	common.AssertEqual(t, "mnist_v1", mcm.storageKey)
+	common.AssertEqual(t, "# mnist", mcm.content)
+
+	// a card key held for another key conflicts, as outside read-through mode
+	data, err = json.Marshal(rest.PostBody{Body: []byte("kind: Component\n"), ModelCardKey: "mnist-card", ModelCard: "# fraud"})
+	common.AssertError(t, err)
+	ctx, _ = gin.CreateTestContext(testgin.NewTestResponseWriter())
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=fraud_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+	ils.handleCatalogUpsertPost(ctx)
+	common.AssertEqual(t, http.StatusConflict, ctx.Writer.Status())
+
+	// the bundle pairs the location fetched from storage with the held card
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ = gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{}, Header: http.Header{}}
+	ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}}
+	ils.handleBundleGet(ctx)
+	common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+	b := BundleResponse{}
+	common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &b))
+	common.AssertEqual(t, "/mnist/v1/catalog-info.yaml", b.URI)
+	common.AssertEqual(t, "kind: Component\n", b.CatalogInfo)
+	common.AssertEqual(t, "mnist-card", b.ModelCardKey)
+	common.AssertEqual(t, "# mnist", b.ModelCard)
+}
+
+func TestReadThroughDiscoveryAutoDetect(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("kind: Component\n"), "fraud_v2": []byte(`[{"name": "fraud"}]`)})
+	ils := &ImportLocationServer{
+		content:          map[string]*ImportLocation{},
+		storage:          st,
+		format:           types.CatalogInfoYamlFormat,
+		formatAutoDetect: true,
+		readThrough:      newReadThroughCache(0, defaultReadThroughCacheMaxEntries),
+	}
+	_, jsonURI := ils.buildKeyAndURI("fraud", "v2", types.JsonArrayForamt)
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{}}
+	ils.handleCatalogDiscoveryGet(ctx)
+	common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+	resp := DicoveryResponse{}
+	common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+	common.AssertEqual(t, 2, len(resp.Uris))
+	common.AssertEqual(t, jsonURI, resp.Uris[0])
+	common.AssertEqual(t, "/mnist/v1/catalog-info.yaml", resp.Uris[1])
+
+	// every discovered URI is one lookups serve
+	for _, uri := range resp.Uris {
+		segs := strings.Split(strings.TrimPrefix(uri, "/"), "/")
+		key, _ := ils.buildKeyAndURI(segs[0], segs[1], ils.format)
+		_, ok, err := ils.fetchThrough(segs[0], segs[1], key, uri)
+		common.AssertError(t, err)
+		common.AssertEqual(t, true, ok)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// reconcileHistorySize is how many of the most recent reconcile passes are kept
+const reconcileHistorySize = 10
+
+// ReconcilePass is what a reconcile with storage did: the number of locations it added, updated with changed content
+// and removed, and of the storage keys it could not fetch, or skipped as in quarantine, whose locations were kept
+type ReconcilePass struct {
+	Started  string `json:"started"`
+	Duration string `json:"duration"`
+	Added    int    `json:"added"`
+	Updated  int    `json:"updated"`
+	Removed  int    `json:"removed"`
+	Failed   int    `json:"failed"`
+	Error    string `json:"error,omitempty"`
+}
+
+type ReconcileStatsResponse struct {
+	Passes      int64           `json:"passes"`
+	Errors      int64           `json:"errors"`
+	LastSuccess string          `json:"lastSuccess,omitempty"`
+	Last        *ReconcilePass  `json:"last,omitempty"`
+	History     []ReconcilePass `json:"history"`
+}
+
+// reconcileStats counts the reconciles with storage, and those that failed to complete, and keeps the most recent
+// passes, oldest first; the zero value is ready to record passes
+type reconcileStats struct {
+	passes      atomic.Int64
+	errors      atomic.Int64
+	lastSuccess atomic.Int64
+	lock        sync.Mutex
+	history     []ReconcilePass
+}
+
+// record counts a reconcile pass started at start, and adds it to the history, dropping the oldest pass beyond the
+// history size
+func (s *reconcileStats) record(start time.Time, pass ReconcilePass) {
+	pass.Started = start.UTC().Format(time.RFC3339)
+	pass.Duration = time.Since(start).String()
+	s.passes.Add(1)
+	if len(pass.Error) > 0 {
+		s.errors.Add(1)
+	} else {
+		s.lastSuccess.Store(start.UnixNano())
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.history = append(s.history, pass)
+	if len(s.history) > reconcileHistorySize {
+		s.history = s.history[len(s.history)-reconcileHistorySize:]
+	}
+}
+
+// sinceSuccess returns how long ago the last successful reconcile started, and false when none has succeeded
+func (s *reconcileStats) sinceSuccess() (time.Duration, bool) {
+	last := s.lastSuccess.Load()
+	if last == 0 {
+		return 0, false
+	}
+	return time.Since(time.Unix(0, last)), true
+}
+
+func (s *reconcileStats) response() *ReconcileStatsResponse {
+	resp := &ReconcileStatsResponse{Passes: s.passes.Load(), Errors: s.errors.Load()}
+	if last := s.lastSuccess.Load(); last != 0 {
+		resp.LastSuccess = time.Unix(0, last).UTC().Format(time.RFC3339)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	resp.History = append([]ReconcilePass{}, s.history...)
+	if len(resp.History) > 0 {
+		resp.Last = &resp.History[len(resp.History)-1]
+	}
+	return resp
+}
+
+// handleReconcileStatsGet returns the counts of reconcile passes and errors, and the most recent passes
+func (i *ImportLocationServer) handleReconcileStatsGet(c *gin.Context) {
+	content, err := json.Marshal(i.reconcileStats.response())
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestReconcileStats(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{
+		"mnist_v1":   []byte(mnistEntity),
+		"mnist_v2":   []byte(mnistUpdatedEntity),
+		"granite_v1": []byte(graniteEntity),
+	})
+	st.FailKey("mnist_v2", true)
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte("old")},
+			"/mnist/v2/catalog-info.yaml": {content: []byte("kept")},
+			"/mnist/v3/catalog-info.yaml": {content: []byte("removed from storage")},
+		},
+		modelcards: map[string]modelCardMetadata{},
+		storage:    st,
+	}
+	r := gin.New()
+	r.GET(util.ReconcileStatsURI, ils.handleReconcileStatsGet)
+	r.GET(util.HealthzURI, ils.handleHealthzGet)
+	stats := func() *ReconcileStatsResponse {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ReconcileStatsURI, nil))
+		common.AssertEqual(t, http.StatusOK, rec.Code)
+		resp := &ReconcileStatsResponse{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+		return resp
+	}
+
+	// no reconcile has run
+	resp := stats()
+	common.AssertEqual(t, int64(0), resp.Passes)
+	common.AssertEqual(t, true, resp.Last == nil)
+	common.AssertEqual(t, 0, len(resp.History))
+
+	// a pass adding granite, updating mnist v1, removing mnist v3 and failing to fetch mnist v2
+	ils.reloadFromStorage()
+	resp = stats()
+	common.AssertEqual(t, int64(1), resp.Passes)
+	common.AssertEqual(t, int64(0), resp.Errors)
+	common.AssertEqual(t, true, len(resp.LastSuccess) > 0)
+	common.AssertEqual(t, 1, len(resp.History))
+	last := *resp.Last
+	last.Started, last.Duration = "", ""
+	common.AssertEqual(t, ReconcilePass{Added: 1, Updated: 1, Removed: 1, Failed: 1}, last)
+
+	// an unchanged pass, then a pass that fails to list storage
+	st.FailKey("mnist_v2", false)
+	ils.reloadFromStorage()
+	st.ListErr = errors.New("storage is down")
+	ils.reloadFromStorage()
+	resp = stats()
+	common.AssertEqual(t, int64(3), resp.Passes)
+	common.AssertEqual(t, int64(1), resp.Errors)
+	common.AssertEqual(t, 3, len(resp.History))
+	common.AssertEqual(t, 1, resp.History[1].Updated)
+	common.AssertEqual(t, 0, resp.History[1].Added+resp.History[1].Removed+resp.History[1].Failed)
+	common.AssertContains(t, resp.Last.Error, []string{"listing the keys in storage failed"})
+
+	// the history keeps the most recent passes
+	for range reconcileHistorySize {
+		ils.reloadFromStorage()
+	}
+	resp = stats()
+	common.AssertEqual(t, int64(3+reconcileHistorySize), resp.Passes)
+	common.AssertEqual(t, reconcileHistorySize, len(resp.History))
+
+	// the liveness probe fails once no reconcile has succeeded for the stale after duration
+	ils.reconcileStaleAfter = time.Minute
+	healthz := func() int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.HealthzURI, nil))
+		return rec.Code
+	}
+	common.AssertEqual(t, http.StatusOK, healthz())
+	ils.reconcileStats.lastSuccess.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	common.AssertEqual(t, http.StatusServiceUnavailable, healthz())
+	ils.reconcileStaleAfter = 0
+	common.AssertEqual(t, http.StatusOK, healthz())
+}
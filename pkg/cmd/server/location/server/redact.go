@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bytes"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// defaultRedactionPlaceholder replaces the values of redacted fields unless another placeholder is configured
+const defaultRedactionPlaceholder = "REDACTED"
+
+// jsonRedactionTransformer replaces, as content is served, the values at the configured paths of JSON content with
+// a placeholder, so fields such as internal endpoints are not exposed to consumers; YAML content is left untouched.
+// A path is a dot separated list of object fields, such as spec.profile.endpoint, where a field holding an array
+// applies the rest of the path to each of its elements, as does a JSON array at the top level of the content, and a
+// dot within a field name is escaped with a backslash, as in metadata.annotations.example\.com/endpoint.
+type jsonRedactionTransformer struct {
+	paths       [][]string
+	placeholder string
+}
+
+func newJSONRedactionTransformer(paths []string, placeholder string) *jsonRedactionTransformer {
+	r := &jsonRedactionTransformer{placeholder: placeholder}
+	for _, p := range paths {
+		if fields := splitRedactionPath(p); len(fields) > 0 {
+			r.paths = append(r.paths, fields)
+		}
+	}
+	return r
+}
+
+// splitRedactionPath splits a path on the dots not escaped with a backslash
+func splitRedactionPath(path string) []string {
+	fields := []string{}
+	field := strings.Builder{}
+	for idx := 0; idx < len(path); idx++ {
+		switch {
+		case path[idx] == '\\' && idx+1 < len(path) && path[idx+1] == '.':
+			field.WriteByte('.')
+			idx++
+		case path[idx] == '.':
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+			}
+			field.Reset()
+		default:
+			field.WriteByte(path[idx])
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+func (r *jsonRedactionTransformer) Transform(uri string, content []byte) ([]byte, error) {
+	if len(r.paths) == 0 || !isJSON(content) {
+		return content, nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(content), &doc); err != nil {
+		// not content we can redact
+		return content, nil
+	}
+	changed := false
+	for _, fields := range r.paths {
+		changed = r.redact(doc, fields) || changed
+	}
+	if !changed {
+		return content, nil
+	}
+	return json.Marshal(doc)
+}
+
+// redact replaces the value at fields within v, returning whether anything was replaced
+func (r *jsonRedactionTransformer) redact(v interface{}, fields []string) bool {
+	switch node := v.(type) {
+	case []interface{}:
+		changed := false
+		for _, elem := range node {
+			changed = r.redact(elem, fields) || changed
+		}
+		return changed
+	case map[string]interface{}:
+		child, ok := node[fields[0]]
+		if !ok {
+			return false
+		}
+		if len(fields) == 1 {
+			node[fields[0]] = r.placeholder
+			return true
+		}
+		return r.redact(child, fields[1:])
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+)
+
+func TestJSONRedactionTransformer(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		paths    []string
+		content  string
+		expected string
+	}{
+		{
+			name:     "nested field redacted, others preserved",
+			paths:    []string{"spec.profile.endpoint"},
+			content:  `{"kind":"Resource","spec":{"owner":"ai-team","profile":{"endpoint":"http://internal:8080","name":"mnist"}}}`,
+			expected: `{"kind":"Resource","spec":{"owner":"ai-team","profile":{"endpoint":"REDACTED","name":"mnist"}}}`,
+		},
+		{
+			name:     "several paths",
+			paths:    []string{"spec.owner", "metadata.annotations.example\\.com/endpoint"},
+			content:  `{"metadata":{"annotations":{"example.com/endpoint":"http://internal","example.com/team":"ai"}},"spec":{"owner":"ai-team"}}`,
+			expected: `{"metadata":{"annotations":{"example.com/endpoint":"REDACTED","example.com/team":"ai"}},"spec":{"owner":"REDACTED"}}`,
+		},
+		{
+			name:     "arrays apply the rest of the path to each element",
+			paths:    []string{"links.url"},
+			content:  `[{"links":[{"title":"api","url":"http://internal/a"},{"title":"docs"}]},{"links":[{"url":"http://internal/b"}]}]`,
+			expected: `[{"links":[{"title":"api","url":"REDACTED"},{"title":"docs"}]},{"links":[{"url":"REDACTED"}]}]`,
+		},
+		{
+			name:     "whole objects redacted",
+			paths:    []string{"spec.profile"},
+			content:  `{"spec":{"profile":{"endpoint":"http://internal"}}}`,
+			expected: `{"spec":{"profile":"REDACTED"}}`,
+		},
+		{
+			name:     "absent paths leave the content as is",
+			paths:    []string{"spec.endpoint", "metadata.name.first"},
+			content:  `{"metadata": {"name": "mnist"}}`,
+			expected: `{"metadata": {"name": "mnist"}}`,
+		},
+		{
+			name:     "yaml untouched",
+			paths:    []string{"spec.owner"},
+			content:  "kind: Component\nspec:\n  owner: ai-team\n",
+			expected: "kind: Component\nspec:\n  owner: ai-team\n",
+		},
+		{
+			name:     "invalid json untouched",
+			paths:    []string{"spec.owner"},
+			content:  `{"spec": `,
+			expected: `{"spec": `,
+		},
+	} {
+		r := newJSONRedactionTransformer(tc.paths, defaultRedactionPlaceholder)
+		content, err := r.Transform("/mnist/v1/catalog-info.yaml", []byte(tc.content))
+		common.AssertError(t, err)
+		if string(content) != tc.expected {
+			t.Errorf("%s: expected %s but got %s", tc.name, tc.expected, string(content))
+		}
+	}
+}
+
+func TestServeRedaction(t *testing.T) {
+	stored := `{"kind":"Component","metadata":{"name":"mnist"},"spec":{"endpoint":"http://internal:8080"}}`
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte(stored)},
+		},
+	}
+	ils.AddServeTransformer(newJSONRedactionTransformer([]string{"spec.endpoint"}, "***"))
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{}}
+	ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: "catalog-info.yaml"}}
+
+	ils.handleCatalogLookupGet(ctx)
+
+	common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+	common.AssertEqual(t, `{"kind":"Component","metadata":{"name":"mnist"},"spec":{"endpoint":"***"}}`, testWriter.ResponseWriter.Body.String())
+	// the stored content is left intact
+	common.AssertEqual(t, stored, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// substitutionKeys are the placeholders Backstage substitutes with the content of the file they reference, as in a
+// spec.definition of '$text: ./openapi.yaml'
+var substitutionKeys = map[string]bool{"$text": true, "$json": true, "$yaml": true}
+
+// referenceTransformer resolves, as content is served, the relative references of Backstage entities against the
+// URL the content is served at, i.e. the base URL joined with the location's URI, so they still resolve when the
+// URIs content is served at change; stored content keeps its relative references.  The references resolved are the
+// spec.target and spec.targets of Location entities and the values of the $text, $json and $yaml substitutions
+// anywhere in an entity; absolute references, and content without Backstage entities, are left untouched.
+type referenceTransformer struct {
+	base *url.URL
+}
+
+// newReferenceTransformer creates a transformer resolving references against the base URL the location service is
+// reached at, such as https://bridge.example.com, which must be absolute
+func newReferenceTransformer(base string) (*referenceTransformer, error) {
+	u, err := url.Parse(strings.TrimSpace(base))
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference base URL %s: %s", base, err.Error())
+	}
+	if !u.IsAbs() || len(u.Host) == 0 {
+		return nil, fmt.Errorf("the reference base URL %s is not an absolute URL", base)
+	}
+	return &referenceTransformer{base: u}, nil
+}
+
+func (r *referenceTransformer) Transform(uri string, content []byte) ([]byte, error) {
+	entities, err := parseEntities(content)
+	if err != nil {
+		// not content with references to resolve
+		return content, nil
+	}
+	location := r.base.JoinPath(uri)
+	changed := false
+	for _, entity := range entities {
+		if _, ok := entityRef(entity); !ok {
+			continue
+		}
+		if kind, _ := entity["kind"].(string); strings.EqualFold(kind, "location") {
+			if spec, ok := entity["spec"].(map[string]interface{}); ok {
+				if target, ok := spec["target"].(string); ok {
+					spec["target"] = resolveReference(location, target, &changed)
+				}
+				if targets, ok := spec["targets"].([]interface{}); ok {
+					for idx, t := range targets {
+						if target, ok := t.(string); ok {
+							targets[idx] = resolveReference(location, target, &changed)
+						}
+					}
+				}
+			}
+		}
+		resolveSubstitutions(location, entity, &changed)
+	}
+	if !changed {
+		return content, nil
+	}
+	return marshalEntities(entities, isJSON(content))
+}
+
+// resolveSubstitutions resolves the references of the substitutions within a value of an entity
+func resolveSubstitutions(location *url.URL, value interface{}, changed *bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 1 {
+			for k, ref := range v {
+				if str, ok := ref.(string); ok && substitutionKeys[k] {
+					v[k] = resolveReference(location, str, changed)
+					return
+				}
+			}
+		}
+		for _, field := range v {
+			resolveSubstitutions(location, field, changed)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			resolveSubstitutions(location, elem, changed)
+		}
+	}
+}
+
+// resolveReference returns the reference resolved against the location's URL if it is relative, noting the change
+func resolveReference(location *url.URL, ref string, changed *bool) string {
+	u, err := url.Parse(ref)
+	if len(strings.TrimSpace(ref)) == 0 || err != nil || u.IsAbs() {
+		return ref
+	}
+	*changed = true
+	return location.ResolveReference(u).String()
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+)
+
+func TestReferenceTransformer(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		base     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "location targets",
+			base:     "https://bridge.example.com",
+			content:  `{"apiVersion":"backstage.io/v1alpha1","kind":"Location","metadata":{"name":"mnist"},"spec":{"target":"./mnist-api.yaml","targets":["../v2/catalog-info.yaml","https://github.com/org/repo/catalog-info.yaml"]}}`,
+			expected: `{"apiVersion":"backstage.io/v1alpha1","kind":"Location","metadata":{"name":"mnist"},"spec":{"target":"https://bridge.example.com/mnist/v1/mnist-api.yaml","targets":["https://bridge.example.com/mnist/v2/catalog-info.yaml","https://github.com/org/repo/catalog-info.yaml"]}}`,
+		},
+		{
+			name:     "substitutions under a base path",
+			base:     "https://example.com/bridge/",
+			content:  `{"apiVersion":"backstage.io/v1alpha1","kind":"API","metadata":{"name":"mnist"},"spec":{"definition":{"$text":"openapi.yaml"},"docs":[{"$json":"./schema.json"}]}}`,
+			expected: `{"apiVersion":"backstage.io/v1alpha1","kind":"API","metadata":{"name":"mnist"},"spec":{"definition":{"$text":"https://example.com/bridge/mnist/v1/openapi.yaml"},"docs":[{"$json":"https://example.com/bridge/mnist/v1/schema.json"}]}}`,
+		},
+		{
+			name: "yaml stream",
+			base: "https://bridge.example.com",
+			content: `apiVersion: backstage.io/v1alpha1
+kind: Location
+metadata:
+  name: mnist
+spec:
+  target: ./mnist-api.yaml
+`,
+			expected: `apiVersion: backstage.io/v1alpha1
+kind: Location
+metadata:
+  name: mnist
+spec:
+  target: https://bridge.example.com/mnist/v1/mnist-api.yaml
+`,
+		},
+		{
+			name:     "unrecognized fields and absolute references are untouched",
+			base:     "https://bridge.example.com",
+			content:  `{"apiVersion":"backstage.io/v1alpha1","kind":"Component","metadata":{"name":"mnist"},"spec":{"target":"./not-a-location.yaml","definition":{"$text":"https://example.com/openapi.yaml"}}}`,
+			expected: `{"apiVersion":"backstage.io/v1alpha1","kind":"Component","metadata":{"name":"mnist"},"spec":{"target":"./not-a-location.yaml","definition":{"$text":"https://example.com/openapi.yaml"}}}`,
+		},
+		{
+			name:     "content without entities is untouched",
+			base:     "https://bridge.example.com",
+			content:  `[{"target":"./mnist-api.yaml"}]`,
+			expected: `[{"target":"./mnist-api.yaml"}]`,
+		},
+	} {
+		r, err := newReferenceTransformer(tc.base)
+		common.AssertError(t, err)
+		content, err := r.Transform("/mnist/v1/catalog-info.yaml", []byte(tc.content))
+		common.AssertError(t, err)
+		if string(content) != tc.expected {
+			t.Errorf("%s: expected %s but got %s", tc.name, tc.expected, string(content))
+		}
+	}
+
+	for _, base := range []string{"bridge.example.com", "/bridge", "https://"} {
+		if _, err := newReferenceTransformer(base); err == nil {
+			t.Errorf("expected the base URL %s to be rejected", base)
+		}
+	}
+}
+
+func TestServeResolvedReferences(t *testing.T) {
+	stored := `{"kind":"Location","metadata":{"name":"mnist"},"spec":{"target":"./mnist-api.yaml"}}`
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte(stored)},
+		},
+	}
+	refs, err := newReferenceTransformer("https://bridge.example.com")
+	common.AssertError(t, err)
+	ils.AddServeTransformer(refs)
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{}}
+	ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: "catalog-info.yaml"}}
+
+	ils.handleCatalogLookupGet(ctx)
+
+	common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+	common.AssertEqual(t, `{"kind":"Location","metadata":{"name":"mnist"},"spec":{"target":"https://bridge.example.com/mnist/v1/mnist-api.yaml"}}`, testWriter.ResponseWriter.Body.String())
+	// the stored content keeps its relative reference
+	common.AssertEqual(t, stored, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+// relationField is a spec field Backstage derives relations from, with the type of the relation and the kind of
+// the entities it refers to when their references omit it
+type relationField struct {
+	field       string
+	relation    string
+	defaultKind string
+}
+
+// relationFields are the spec fields that declare relations, in the order they are listed
+var relationFields = []relationField{
+	{field: "owner", relation: "ownedBy", defaultKind: "group"},
+	{field: "system", relation: "partOf", defaultKind: "system"},
+	{field: "domain", relation: "partOf", defaultKind: "domain"},
+	{field: "subcomponentOf", relation: "partOf", defaultKind: "component"},
+	{field: "providesApis", relation: "providesApi", defaultKind: "api"},
+	{field: "consumesApis", relation: "consumesApi", defaultKind: "api"},
+	{field: "dependsOn", relation: "dependsOn"},
+	{field: "dependencyOf", relation: "dependencyOf"},
+}
+
+// Relation is a relation declared by an entity to another
+type Relation struct {
+	Source string `json:"source"`
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// RelationsResponse lists the relations declared by the entities of a location
+type RelationsResponse struct {
+	URI       string     `json:"uri"`
+	Relations []Relation `json:"relations"`
+}
+
+// relationTarget completes a reference declared in a spec field into an entity reference, with the field's default
+// kind and the declaring entity's namespace when they are omitted; references whose kind is neither given nor
+// implied by the field are returned as declared
+func relationTarget(ref, defaultKind, namespace string) string {
+	kind, rest, hasKind := strings.Cut(ref, ":")
+	if !hasKind {
+		kind, rest = defaultKind, ref
+	}
+	if len(kind) == 0 {
+		return ref
+	}
+	if !strings.Contains(rest, "/") {
+		rest = namespace + "/" + rest
+	}
+	return strings.ToLower(kind + ":" + rest)
+}
+
+// entityRelations returns the relations declared by the spec fields of the Backstage entities in catalog-info
+// content; content without Backstage entities declares none
+func entityRelations(content []byte) ([]Relation, error) {
+	entities, err := parseEntities(content)
+	if err != nil {
+		return nil, err
+	}
+	relations := []Relation{}
+	for _, entity := range entities {
+		source, ok := entityRef(entity)
+		if !ok {
+			continue
+		}
+		metadata, _ := entity["metadata"].(map[string]interface{})
+		namespace, _ := metadata["namespace"].(string)
+		if len(namespace) == 0 {
+			namespace = "default"
+		}
+		spec, _ := entity["spec"].(map[string]interface{})
+		for _, rf := range relationFields {
+			var refs []interface{}
+			switch v := spec[rf.field].(type) {
+			case string:
+				refs = []interface{}{v}
+			case []interface{}:
+				refs = v
+			}
+			for _, ref := range refs {
+				s, ok := ref.(string)
+				if !ok || len(s) == 0 {
+					continue
+				}
+				relations = append(relations, Relation{Source: source, Type: rf.relation, Target: relationTarget(s, rf.defaultKind, namespace)})
+			}
+		}
+	}
+	return relations, nil
+}
+
+// handleRelationsGet returns the relations declared by the entities of a location, such as the APIs it provides and
+// the components it depends on
+func (i *ImportLocationServer) handleRelationsGet(c *gin.Context) {
+	var model ModelURI
+	if err := c.ShouldBindUri(&model); err != nil {
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	format := i.format
+	if i.formatAutoDetect {
+		format = formatForFileName(model.Format, i.format)
+	}
+	_, uriString := i.buildKeyAndURI(model.Model, model.Version, format)
+	if !i.lockForRead(c) {
+		return
+	}
+	il, ok := i.content[uriString]
+	var content []byte
+	if ok {
+		content = i.plaintext(il.content)
+	}
+	i.lock.RUnlock()
+	if content == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	relations, err := entityRelations(content)
+	if err != nil {
+		// content was checked well-formed when upserted, so it is in a format, such as a JSON list, whose documents
+		// are not entities, and so declares no relations
+		klog.V(4).Infof("%s holds no entities to derive relations from: %s", uriString, err.Error())
+		relations = []Relation{}
+	}
+	content, err = json.Marshal(&RelationsResponse{URI: uriString, Relations: relations})
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", content)
+}
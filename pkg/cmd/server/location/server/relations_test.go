@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+const relatedEntity = `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: fraud
+  namespace: risk
+spec:
+  owner: user:default/jdoe
+  system: payments
+  providesApis:
+    - fraud-v1-api
+  dependsOn:
+    - resource:fraud-v1
+    - component:default/ledger
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  name: fraud-v1-api
+  namespace: risk
+spec:
+  owner: risk-team
+`
+
+func TestHandleRelationsGet(t *testing.T) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/fraud/v1/catalog-info.yaml":   {content: []byte(relatedEntity)},
+			"/granite/v1/catalog-info.yaml": {content: []byte(graniteEntity)},
+			"/iris/v1/catalog-info.yaml":    {content: []byte(`[{"modelName":"iris"}]`)},
+		},
+	}
+	r := gin.New()
+	r.GET(util.RelationsURI, ils.handleRelationsGet)
+
+	for _, tc := range []struct {
+		name              string
+		path              string
+		expectedSC        int
+		expectedRelations []Relation
+	}{
+		{
+			name:       "declared relations",
+			path:       "/fraud/v1/catalog-info.yaml/relations",
+			expectedSC: http.StatusOK,
+			expectedRelations: []Relation{
+				{Source: "component:risk/fraud", Type: "ownedBy", Target: "user:default/jdoe"},
+				{Source: "component:risk/fraud", Type: "partOf", Target: "system:risk/payments"},
+				{Source: "component:risk/fraud", Type: "providesApi", Target: "api:risk/fraud-v1-api"},
+				{Source: "component:risk/fraud", Type: "dependsOn", Target: "resource:risk/fraud-v1"},
+				{Source: "component:risk/fraud", Type: "dependsOn", Target: "component:default/ledger"},
+				{Source: "api:risk/fraud-v1-api", Type: "ownedBy", Target: "group:risk/risk-team"},
+			},
+		},
+		{
+			name:              "no relations declared",
+			path:              "/granite/v1/catalog-info.yaml/relations",
+			expectedSC:        http.StatusOK,
+			expectedRelations: []Relation{},
+		},
+		{
+			name:              "content without entities",
+			path:              "/iris/v1/catalog-info.yaml/relations",
+			expectedSC:        http.StatusOK,
+			expectedRelations: []Relation{},
+		},
+		{
+			name:       "missing location",
+			path:       "/mnist/v1/catalog-info.yaml/relations",
+			expectedSC: http.StatusNotFound,
+		},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.expectedSC {
+			t.Errorf("%s: expected status %d but got %d", tc.name, tc.expectedSC, rec.Code)
+			continue
+		}
+		if tc.expectedSC != http.StatusOK {
+			continue
+		}
+		resp := &RelationsResponse{}
+		common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), resp))
+		common.AssertEqual(t, tc.expectedRelations, resp.Relations)
+	}
+}
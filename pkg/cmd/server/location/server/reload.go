@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// errReloadInProgress is returned by a reload from storage started while another is in progress
+var errReloadInProgress = errors.New("a reload from storage is already in progress")
+
+// reloadStrategy determines how upserts and removals made while a reload from storage is in progress are kept
+// from being lost when the reloaded content is swapped in
+type reloadStrategy string
+
+const (
+	// reloadStrategyBlock has mutations wait for the swap, failing them if it does not happen within the reload wait
+	reloadStrategyBlock reloadStrategy = "block"
+	// reloadStrategyDual applies mutations to both the current content and the content being reloaded
+	reloadStrategyDual reloadStrategy = "dual"
+
+	defaultReloadWait = 5 * time.Second
+)
+
+func parseReloadStrategy(str string) reloadStrategy {
+	switch s := reloadStrategy(strings.ToLower(strings.TrimSpace(str))); s {
+	case reloadStrategyBlock, reloadStrategyDual:
+		return s
+	case "":
+	default:
+		klog.Errorf("invalid reload upsert strategy %s, using %s", str, reloadStrategyBlock)
+	}
+	return reloadStrategyBlock
+}
+
+// reloadState is the content being rebuilt by a reload, which is closed once the reload completes
+type reloadState struct {
+	content map[string]*ImportLocation
+	done    chan struct{}
+}
+
+// apply records a mutation of the location at uri made during the reload, so that it takes precedence over the
+// reloaded content; a nil reload state ignores the mutation, and callers must hold the server lock
+func (r *reloadState) apply(uri string, il *ImportLocation) {
+	if r == nil {
+		return
+	}
+	r.content[uri] = il
+}
+
+// lockForMutation acquires the server lock for an upsert or removal.  While a reload is in progress with the block
+// strategy it releases the lock and waits up to the reload wait for the reload to complete, returning false without
+// the lock if it does not.
+func (i *ImportLocationServer) lockForMutation() bool {
+	deadline := time.Now().Add(i.reloadWait)
+	i.lock.Lock()
+	for i.reloading != nil && i.reloadStrategy != reloadStrategyDual {
+		done := i.reloading.done
+		i.lock.Unlock()
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		timer := time.NewTimer(remaining)
+		select {
+		case <-done:
+		case <-timer.C:
+		}
+		timer.Stop()
+		i.lock.Lock()
+	}
+	return true
+}
+
+// reloadFromStorage rebuilds content from storage in a new map and swaps it in once complete, so that content
+// removed from storage is dropped; locations whose keys are in quarantine or could not be fetched are carried over
+// from the current content.  It returns false if any of the keys could not be fetched, or, along with an error,
+// if the keys could not be listed, wrapping errStorageListFailed, or another reload is in progress; either leaves
+// the content as is, so a reconcile never wipes the catalog already loaded.  Each reload is recorded in the
+// reconcile stats.
+func (i *ImportLocationServer) reloadFromStorage() (bool, error) {
+	start := time.Now()
+	pass := ReconcilePass{}
+	keys, ok := i.listStorageKeys()
+	if !ok {
+		i.lock.RLock()
+		n := len(i.content)
+		i.lock.RUnlock()
+		err := fmt.Errorf("%w, keeping the %d URIs already loaded", errStorageListFailed, n)
+		pass.Error = err.Error()
+		i.reconcileStats.record(start, pass)
+		return false, err
+	}
+	i.lock.Lock()
+	if i.reloading != nil {
+		i.lock.Unlock()
+		pass.Error = errReloadInProgress.Error()
+		i.reconcileStats.record(start, pass)
+		return false, errReloadInProgress
+	}
+	r := &reloadState{content: map[string]*ImportLocation{}, done: make(chan struct{})}
+	i.reloading = r
+	i.lock.Unlock()
+
+	failed := atomic.Int64{}
+	// mutations applied during the reload are newer than what is fetched, so reloaded content never replaces them
+	store := func(uri string, il *ImportLocation) {
+		i.lock.Lock()
+		defer i.lock.Unlock()
+		if _, ok := r.content[uri]; !ok && il != nil {
+			r.content[uri] = il
+		}
+	}
+	loaded := i.fetchKeys(keys, func(uri string, sb *types.StorageBody) {
+		store(uri, i.newFetchedLocation(sb))
+	}, func(uris []string) {
+		failed.Add(1)
+		for _, uri := range uris {
+			i.lock.RLock()
+			il := i.content[uri]
+			i.lock.RUnlock()
+			store(uri, il)
+		}
+	})
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	// reloaded locations whose content is unchanged keep their update times
+	updated := map[string]time.Time{}
+	for uri, il := range r.content {
+		old, ok := i.content[uri]
+		changed := !ok || !bytes.Equal(i.plaintext(old.content), i.plaintext(il.content))
+		switch {
+		case (!ok || old.content == nil) && il.content != nil:
+			pass.Added++
+		case ok && old.content != nil && il.content == nil:
+			pass.Removed++
+		case changed && il.content != nil:
+			pass.Updated++
+		}
+		t, touched := i.updated[uri]
+		if !touched || changed {
+			t = time.Now()
+		}
+		updated[uri] = t
+	}
+	for uri, old := range i.content {
+		if _, ok := r.content[uri]; !ok && old.content != nil {
+			pass.Removed++
+		}
+	}
+	i.updated = updated
+	i.content = r.content
+	if i.entityUniqueness {
+		i.entityRefs = map[string]string{}
+		for uri, il := range i.content {
+			i.indexEntityRefs(uri, nil, il)
+		}
+	}
+	i.reindexVersions()
+	i.reloading = nil
+	close(r.done)
+	pass.Failed = int(failed.Load())
+	i.reconcileStats.record(start, pass)
+	klog.Infof("reloaded %d URIs from storage", len(i.content))
+	return loaded, nil
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestReloadFromStorage(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{
+		"mnist_v1": []byte(mnistEntity),
+		"mnist_v2": []byte(mnistUpdatedEntity),
+	})
+	st.FailKey("mnist_v2", true)
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte("old")},
+			"/mnist/v2/catalog-info.yaml": {content: []byte("kept")},
+			"/mnist/v3/catalog-info.yaml": {content: []byte("removed from storage")},
+		},
+		modelcards: map[string]modelCardMetadata{},
+		storage:    st,
+	}
+
+	loaded, err := ils.reloadFromStorage()
+
+	common.AssertError(t, err)
+	common.AssertEqual(t, false, loaded)
+	common.AssertEqual(t, 2, len(ils.content))
+	common.AssertEqual(t, mnistEntity, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+	common.AssertEqual(t, "kept", string(ils.content["/mnist/v2/catalog-info.yaml"].content))
+}
+
+func TestReloadFromStorageListFailure(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte(mnistEntity)})
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{},
+		storage:    st,
+	}
+	loaded, err := ils.loadFromStorage()
+	common.AssertError(t, err)
+	common.AssertEqual(t, true, loaded)
+
+	// a failed list on reconcile keeps the catalog already loaded
+	st.ListErr = errors.New("storage is down")
+	loaded, err = ils.reloadFromStorage()
+	common.AssertEqual(t, true, errors.Is(err, errStorageListFailed))
+	common.AssertEqual(t, false, loaded)
+	common.AssertEqual(t, 1, len(ils.content))
+	common.AssertEqual(t, mnistEntity, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+	common.AssertEqual(t, true, ils.reloading == nil)
+
+	st.ListErr = nil
+	loaded, err = ils.reloadFromStorage()
+	common.AssertError(t, err)
+	common.AssertEqual(t, true, loaded)
+}
+
+func TestUpsertDuringReload(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		strategy   reloadStrategy
+		wait       time.Duration
+		expectedSC int
+		survives   bool
+	}{
+		{
+			name:       "block until the swap",
+			strategy:   reloadStrategyBlock,
+			wait:       10 * time.Second,
+			expectedSC: http.StatusCreated,
+			survives:   true,
+		},
+		{
+			name:       "block times out",
+			strategy:   reloadStrategyBlock,
+			expectedSC: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "dual apply",
+			strategy:   reloadStrategyDual,
+			expectedSC: http.StatusCreated,
+			survives:   true,
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{
+			"mnist_v1": []byte(mnistEntity),
+			"mnist_v2": []byte(mnistUpdatedEntity),
+		})
+		st.FetchDelay = 200 * time.Millisecond
+		ils := &ImportLocationServer{
+			content:        map[string]*ImportLocation{},
+			modelcards:     map[string]modelCardMetadata{},
+			storage:        st,
+			reloadStrategy: tc.strategy,
+			reloadWait:     tc.wait,
+		}
+
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ils.reloadFromStorage()
+		}()
+		for reloading := false; !reloading; {
+			time.Sleep(time.Millisecond)
+			ils.lock.RLock()
+			reloading = ils.reloading != nil
+			ils.lock.RUnlock()
+		}
+
+		// upsert a newer version of a location being reloaded and a location storage does not yet have
+		for _, upsert := range []struct {
+			key  string
+			body string
+		}{{"mnist_v2", "upserted v2"}, {"mnist_v3", "upserted v3"}} {
+			testWriter := testgin.NewTestResponseWriter()
+			data, err := json.Marshal(rest.PostBody{Body: []byte(upsert.body)})
+			common.AssertError(t, err)
+			ctx, _ := gin.CreateTestContext(testWriter)
+			ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + upsert.key}, Body: io.NopCloser(bytes.NewReader(data))}
+
+			ils.handleCatalogUpsertPost(ctx)
+
+			common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		}
+		wg.Wait()
+
+		common.AssertEqual(t, mnistEntity, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+		il, ok := ils.content["/mnist/v3/catalog-info.yaml"]
+		common.AssertEqual(t, tc.survives, ok)
+		if tc.survives {
+			common.AssertEqual(t, "upserted v3", string(il.content))
+			common.AssertEqual(t, "upserted v2", string(ils.content["/mnist/v2/catalog-info.yaml"].content))
+		} else {
+			common.AssertEqual(t, mnistUpdatedEntity, string(ils.content["/mnist/v2/catalog-info.yaml"].content))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// schemaVersionHeader is the request header selecting the catalog entity apiVersion a lookup is served in and the
+// response header reporting the apiVersion served
+const schemaVersionHeader = "X-Catalog-Schema-Version"
+
+// schemaVersions are the Backstage catalog entity apiVersions content is converted between.  Their entities share
+// a shape, so converting rewrites the apiVersion of each entity.
+var schemaVersions = []string{"backstage.io/v1alpha1", "backstage.io/v1beta1"}
+
+func knownSchemaVersion(version string) bool {
+	for _, v := range schemaVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSchemaEntities decodes the entities of content, which can be a JSON array of entities in addition to
+// what parseEntities decodes, reporting whether it was an array
+func decodeSchemaEntities(content []byte) ([]map[string]interface{}, bool, error) {
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		entities := []map[string]interface{}{}
+		err := json.Unmarshal(trimmed, &entities)
+		return entities, true, err
+	}
+	entities, err := parseEntities(content)
+	return entities, false, err
+}
+
+// detectSchemaVersion returns the apiVersion of the entities of content, or the empty string if it cannot be parsed
+// or its entities differ in apiVersion
+func detectSchemaVersion(content []byte) string {
+	entities, _, err := decodeSchemaEntities(content)
+	if err != nil || len(entities) == 0 {
+		return ""
+	}
+	version, _ := entities[0]["apiVersion"].(string)
+	for _, entity := range entities[1:] {
+		if v, _ := entity["apiVersion"].(string); v != version {
+			return ""
+		}
+	}
+	return version
+}
+
+// convertSchema converts content whose entities are of the apiVersion from to the apiVersion to, in the same
+// representation
+func convertSchema(content []byte, from, to string) ([]byte, error) {
+	if from == to {
+		return content, nil
+	}
+	if !knownSchemaVersion(to) {
+		return nil, fmt.Errorf("unsupported schema version %q, the supported versions are %s", to, strings.Join(schemaVersions, ","))
+	}
+	if !knownSchemaVersion(from) {
+		return nil, fmt.Errorf("content of schema version %q cannot be converted to %s", from, to)
+	}
+	entities, array, err := decodeSchemaEntities(content)
+	if err != nil {
+		return nil, fmt.Errorf("converting content to schema version %s failed: %s", to, err.Error())
+	}
+	for _, entity := range entities {
+		entity["apiVersion"] = to
+	}
+	if array {
+		return json.Marshal(entities)
+	}
+	return marshalEntities(entities, isJSON(content))
+}
+
+// negotiateSchema converts the content served for a location to the schema version requested by the lookup's
+// schema version header, if any, setting the header of the response to the version served.  Content is served
+// unchanged without the request header or schema negotiation.
+func (i *ImportLocationServer) negotiateSchema(c *gin.Context, il *ImportLocation, served []byte) ([]byte, error) {
+	if !i.schemaNegotiation {
+		return served, nil
+	}
+	c.Writer.Header().Add("Vary", schemaVersionHeader)
+	target := strings.TrimSpace(c.GetHeader(schemaVersionHeader))
+	if len(target) == 0 {
+		if len(il.schemaVersion) > 0 {
+			c.Header(schemaVersionHeader, il.schemaVersion)
+		}
+		return served, nil
+	}
+	converted, err := convertSchema(served, il.schemaVersion, target)
+	if err != nil {
+		return nil, err
+	}
+	c.Header(schemaVersionHeader, target)
+	return converted, nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestDetectSchemaVersion(t *testing.T) {
+	common.AssertEqual(t, "backstage.io/v1alpha1", detectSchemaVersion([]byte("apiVersion: backstage.io/v1alpha1\nkind: Component\n---\napiVersion: backstage.io/v1alpha1\nkind: API\n")))
+	common.AssertEqual(t, "backstage.io/v1beta1", detectSchemaVersion([]byte(`[{"apiVersion":"backstage.io/v1beta1","kind":"Component"}]`)))
+	// entities of different versions share none
+	common.AssertEqual(t, "", detectSchemaVersion([]byte("apiVersion: backstage.io/v1alpha1\nkind: Component\n---\napiVersion: backstage.io/v1beta1\nkind: API\n")))
+	common.AssertEqual(t, "", detectSchemaVersion([]byte("kind: Component\n")))
+	common.AssertEqual(t, "", detectSchemaVersion([]byte("{not json")))
+}
+
+func TestHandleCatalogLookupGetSchemaVersion(t *testing.T) {
+	const stored = "apiVersion: backstage.io/v1alpha1\nkind: Component\nmetadata:\n  name: mnist\n"
+	for _, tc := range []struct {
+		name                  string
+		negotiation           bool
+		target                string
+		expectedSC            int
+		expectedContent       string
+		expectedSchemaVersion string
+	}{
+		{
+			name:            "negotiation disabled",
+			target:          "backstage.io/v1beta1",
+			expectedSC:      http.StatusOK,
+			expectedContent: stored,
+		},
+		{
+			name:                  "no conversion requested",
+			negotiation:           true,
+			expectedSC:            http.StatusOK,
+			expectedContent:       stored,
+			expectedSchemaVersion: "backstage.io/v1alpha1",
+		},
+		{
+			name:                  "stored version requested",
+			negotiation:           true,
+			target:                "backstage.io/v1alpha1",
+			expectedSC:            http.StatusOK,
+			expectedContent:       stored,
+			expectedSchemaVersion: "backstage.io/v1alpha1",
+		},
+		{
+			name:                  "supported conversion",
+			negotiation:           true,
+			target:                "backstage.io/v1beta1",
+			expectedSC:            http.StatusOK,
+			expectedContent:       "apiVersion: backstage.io/v1beta1\nkind: Component\nmetadata:\n  name: mnist\n",
+			expectedSchemaVersion: "backstage.io/v1beta1",
+		},
+		{
+			name:        "unsupported target",
+			negotiation: true,
+			target:      "scaffolder.backstage.io/v1beta3",
+			expectedSC:  http.StatusNotAcceptable,
+		},
+	} {
+		ils := &ImportLocationServer{
+			content:           map[string]*ImportLocation{},
+			modelcards:        map[string]modelCardMetadata{},
+			format:            types.CatalogInfoYamlFormat,
+			schemaNegotiation: tc.negotiation,
+		}
+		data, err := json.Marshal(rest.PostBody{Body: []byte(stored)})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ = gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{}, Header: http.Header{}}
+		if len(tc.target) > 0 {
+			ctx.Request.Header.Set(schemaVersionHeader, tc.target)
+		}
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: catalogInfoFileName}}
+		ils.handleCatalogLookupGet(ctx)
+
+		if ctx.Writer.Status() != tc.expectedSC {
+			t.Errorf("%s: expected status %d but got %d", tc.name, tc.expectedSC, ctx.Writer.Status())
+		}
+		if tc.expectedSC == http.StatusOK {
+			common.AssertEqual(t, tc.expectedContent, testWriter.ResponseWriter.Body.String())
+		}
+		common.AssertEqual(t, tc.expectedSchemaVersion, testWriter.Header().Get(schemaVersionHeader))
+	}
+}
+
+func TestConvertSchemaJSON(t *testing.T) {
+	converted, err := convertSchema([]byte(`[{"apiVersion":"backstage.io/v1beta1","kind":"Component"},{"apiVersion":"backstage.io/v1beta1","kind":"API"}]`),
+		"backstage.io/v1beta1", "backstage.io/v1alpha1")
+	common.AssertError(t, err)
+	common.AssertEqual(t, `[{"apiVersion":"backstage.io/v1alpha1","kind":"Component"},{"apiVersion":"backstage.io/v1alpha1","kind":"API"}]`, string(converted))
+
+	// content of a version not converted between cannot be converted
+	_, err = convertSchema([]byte(`{"apiVersion":"example.com/v1","kind":"Component"}`), "example.com/v1", "backstage.io/v1alpha1")
+	common.AssertEqual(t, true, err != nil)
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+// authScope is what a bearer token is authorized to do; each scope implies those below it, i.e. admin implies write,
+// which implies read
+type authScope string
+
+const (
+	scopeRead  authScope = "read"
+	scopeWrite authScope = "write"
+	scopeAdmin authScope = "admin"
+
+	// grantedScopesKey is the context key the scopes granted to a request are set under
+	grantedScopesKey = "grantedScopes"
+)
+
+var scopeRanks = map[authScope]int{scopeRead: 1, scopeWrite: 2, scopeAdmin: 3}
+
+// scopeExemptRoutes are the probes, which the kubelet cannot present a token to
+var scopeExemptRoutes = map[string]bool{util.HealthzURI: true, util.ReadyzURI: true}
+
+// signedRoutes verify the signed URLs they are requested with themselves, so such requests need no bearer token
+var signedRoutes = map[string]bool{util.ModelCardURI: true, util.ModelCardTOCURI: true, util.ModelCardAssetURI: true}
+
+// scopeAuthorizer grants scopes to bearer tokens, either statically configured for a scope or JWTs signed with
+// HS256 by the configured key, whose scopes are the space separated 'scope' claim or the 'scp' claim list
+type scopeAuthorizer struct {
+	tokens map[string]authScope
+	jwtKey []byte
+	now    func() time.Time
+}
+
+// newScopeAuthorizer creates the authorizer for the scope=token mapping and JWT key, or nil, which leaves requests
+// unauthorized by scope, when neither is configured; the admin token, if set, is granted the admin scope
+func newScopeAuthorizer(scopeTokens map[string]string, jwtKey, adminToken string) *scopeAuthorizer {
+	if len(scopeTokens) == 0 && len(jwtKey) == 0 {
+		return nil
+	}
+	a := &scopeAuthorizer{tokens: map[string]authScope{}, now: time.Now}
+	for scope, token := range scopeTokens {
+		s := authScope(strings.ToLower(scope))
+		if _, ok := scopeRanks[s]; !ok || len(token) == 0 {
+			klog.Errorf("ignoring the token for the invalid scope %s", scope)
+			continue
+		}
+		a.tokens[token] = s
+	}
+	if len(adminToken) > 0 {
+		a.tokens[adminToken] = scopeAdmin
+	}
+	if len(jwtKey) > 0 {
+		a.jwtKey = []byte(jwtKey)
+	}
+	return a
+}
+
+// scopes returns the scopes granted to a bearer token, or an error when the token is not valid
+func (a *scopeAuthorizer) scopes(token string) ([]authScope, error) {
+	for t, scope := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			return []authScope{scope}, nil
+		}
+	}
+	if a.jwtKey == nil || strings.Count(token, ".") != 2 {
+		return nil, fmt.Errorf("the bearer token is not valid")
+	}
+	return a.jwtScopes(token)
+}
+
+// jwtScopes verifies an HS256 JWT and returns the scopes of its claims
+func (a *scopeAuthorizer) jwtScopes(token string) ([]authScope, error) {
+	parts := strings.Split(token, ".")
+	header := struct {
+		Alg string `json:"alg"`
+	}{}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(raw, &header) != nil || header.Alg != "HS256" {
+		return nil, fmt.Errorf("the bearer token is not an HS256 JWT")
+	}
+	mac := hmac.New(sha256.New, a.jwtKey)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, fmt.Errorf("the bearer token has a bad signature")
+	}
+	claims := struct {
+		Exp   int64    `json:"exp"`
+		Nbf   int64    `json:"nbf"`
+		Scope string   `json:"scope"`
+		Scp   []string `json:"scp"`
+	}{}
+	raw, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(raw, &claims) != nil {
+		return nil, fmt.Errorf("the bearer token has malformed claims")
+	}
+	now := a.now().Unix()
+	if (claims.Exp > 0 && now >= claims.Exp) || (claims.Nbf > 0 && now < claims.Nbf) {
+		return nil, fmt.Errorf("the bearer token is expired or not yet valid")
+	}
+	scopes := []authScope{}
+	for _, s := range append(strings.Fields(claims.Scope), claims.Scp...) {
+		scopes = append(scopes, authScope(strings.ToLower(s)))
+	}
+	return scopes, nil
+}
+
+// grants reports whether the granted scopes include, or imply, the required scope
+func grants(granted []authScope, required authScope) bool {
+	for _, s := range granted {
+		if scopeRanks[s] >= scopeRanks[required] {
+			return true
+		}
+	}
+	return false
+}
+
+// adminScope reports whether scope authorization authenticated a request, and if so whether it granted the admin
+// scope, which the admin endpoints require of authenticated requests in place of the admin token
+func adminScope(c *gin.Context) (bool, bool) {
+	granted, ok := c.Get(grantedScopesKey)
+	if !ok {
+		return false, false
+	}
+	scopes, _ := granted.([]authScope)
+	return true, grants(scopes, scopeAdmin)
+}
+
+// requireAdminScope lets a request authenticated by scope through if it was granted the admin scope, and aborts it
+// otherwise, returning false when the request was not authenticated by scope
+func requireAdminScope(c *gin.Context) bool {
+	authenticated, admin := adminScope(c)
+	switch {
+	case !authenticated:
+		return false
+	case admin:
+		c.Next()
+	default:
+		c.AbortWithError(http.StatusForbidden, fmt.Errorf("the bearer token lacks the %s scope", scopeAdmin))
+	}
+	return true
+}
+
+// scopeAuthorization requires requests to present a bearer token granted the scope of their route: reads require
+// read and other methods write, while the admin endpoints, guarded by adminAuth, require admin; the probes, and
+// model card requests with a signed URL, are exempt.  A nil authorizer lets every request through.
+func scopeAuthorization(a *scopeAuthorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if a == nil || scopeExemptRoutes[route] || (signedRoutes[route] && len(c.Query(util.SignatureQueryParam)) > 0) {
+			c.Next()
+			return
+		}
+		required := scopeWrite
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			required = scopeRead
+		}
+		presented, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			c.AbortWithError(http.StatusUnauthorized, fmt.Errorf("a bearer token with the %s scope is required", required))
+			return
+		}
+		scopes, err := a.scopes(presented)
+		if err != nil {
+			c.AbortWithError(http.StatusUnauthorized, err)
+			return
+		}
+		if !grants(scopes, required) {
+			c.AbortWithError(http.StatusForbidden, fmt.Errorf("the bearer token lacks the %s scope", required))
+			return
+		}
+		c.Set(grantedScopesKey, scopes)
+		c.Next()
+	}
+}
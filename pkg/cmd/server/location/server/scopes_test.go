@@ -0,0 +1,128 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+// signedJWT returns an HS256 JWT of the claims signed with key
+func signedJWT(key, claims string) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestScopeAuthorization(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	newScopedRouter := func(a *scopeAuthorizer) *gin.Engine {
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		r.Use(scopeAuthorization(a))
+		ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+		r.GET("/:model/:version/:format", ok)
+		r.POST(util.UpsertURI, ok)
+		r.DELETE(util.RemoveURI, ok)
+		r.POST(util.ConfigReindexURI, adminAuth(""), ok)
+		r.GET(util.ModelCardURI, modelCardAccess(newURLSigner("signing-key"), ""), ok)
+		r.GET(util.HealthzURI, ok)
+		return r
+	}
+	a := newScopeAuthorizer(map[string]string{"read": "read-token", "write": "write-token", "bogus": "bogus-token"}, "jwt-key", "admin-token")
+	a.now = func() time.Time { return now }
+	r := newScopedRouter(a)
+	signedCard, _ := newURLSigner("signing-key").sign("mnist-card", time.Hour)
+
+	lookup, upsert, remove, reindex, healthz := "GET /mnist/v1/catalog-info.yaml", "POST "+util.UpsertURI, "DELETE "+util.RemoveURI, "POST "+util.ConfigReindexURI, "GET "+util.HealthzURI
+	for _, tc := range []struct {
+		name     string
+		token    string
+		expected map[string]int
+	}{
+		{
+			name:     "no token",
+			expected: map[string]int{lookup: http.StatusUnauthorized, upsert: http.StatusUnauthorized, reindex: http.StatusUnauthorized, healthz: http.StatusOK},
+		},
+		{
+			name:     "unknown token",
+			token:    "bogus-token",
+			expected: map[string]int{lookup: http.StatusUnauthorized, upsert: http.StatusUnauthorized, reindex: http.StatusUnauthorized},
+		},
+		{
+			name:     "read scope",
+			token:    "read-token",
+			expected: map[string]int{lookup: http.StatusOK, upsert: http.StatusForbidden, remove: http.StatusForbidden, reindex: http.StatusForbidden},
+		},
+		{
+			name:     "write scope",
+			token:    "write-token",
+			expected: map[string]int{lookup: http.StatusOK, upsert: http.StatusOK, remove: http.StatusOK, reindex: http.StatusForbidden},
+		},
+		{
+			name:     "admin scope of the admin token",
+			token:    "admin-token",
+			expected: map[string]int{lookup: http.StatusOK, upsert: http.StatusOK, remove: http.StatusOK, reindex: http.StatusOK},
+		},
+		{
+			name:     "jwt read and write scopes",
+			token:    signedJWT("jwt-key", `{"sub":"normalizer","scope":"read write","exp":1700000060}`),
+			expected: map[string]int{lookup: http.StatusOK, upsert: http.StatusOK, reindex: http.StatusForbidden},
+		},
+		{
+			name:     "jwt admin scope",
+			token:    signedJWT("jwt-key", `{"sub":"operator","scp":["admin"]}`),
+			expected: map[string]int{lookup: http.StatusOK, upsert: http.StatusOK, reindex: http.StatusOK},
+		},
+		{
+			name:     "expired jwt",
+			token:    signedJWT("jwt-key", `{"scope":"admin","exp":1700000000}`),
+			expected: map[string]int{lookup: http.StatusUnauthorized, reindex: http.StatusUnauthorized},
+		},
+		{
+			name:     "jwt signed with another key",
+			token:    signedJWT("other-key", `{"scope":"admin"}`),
+			expected: map[string]int{lookup: http.StatusUnauthorized, reindex: http.StatusUnauthorized},
+		},
+	} {
+		for route, expectedSC := range tc.expected {
+			method, path, _ := strings.Cut(route, " ")
+			req := httptest.NewRequest(method, path, nil)
+			if len(tc.token) > 0 {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != expectedSC {
+				t.Errorf("%s: expected %s to respond %d but got %d", tc.name, route, expectedSC, rec.Code)
+			}
+		}
+	}
+
+	// signed model card URLs need no token, while unsigned card requests need the read scope
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, signedCard, nil))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ModelCardURI+"?key=mnist-card", nil))
+	common.AssertEqual(t, http.StatusUnauthorized, rec.Code)
+
+	// without scopes configured, requests are let through as before
+	common.AssertEqual(t, true, newScopeAuthorizer(nil, "", "admin-token") == nil)
+	open := newScopedRouter(nil)
+	for _, route := range []string{lookup, upsert, remove} {
+		method, path, _ := strings.Cut(route, " ")
+		rec := httptest.NewRecorder()
+		open.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		common.AssertEqual(t, http.StatusOK, rec.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultServeRetryBackoff = time.Second
+	maxServeRetryBackoff     = 30 * time.Second
+)
+
+// isFatalServeError reports whether serving failed in a way retrying cannot fix, i.e. the port is in use by another
+// process, may not be bound by this one, or is not a valid address
+func isFatalServeError(err error) bool {
+	var addrErr *net.AddrError
+	return errors.Is(err, syscall.EADDRINUSE) || errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EADDRNOTAVAIL) ||
+		errors.As(err, &addrErr)
+}
+
+// serveWithRetry serves until stopCh is closed, retrying a serve that fails after a backoff which starts at backoff
+// and doubles up to maxServeRetryBackoff, and is reset by a serve that ran for longer than that.  It returns a fatal
+// error immediately, and the last error once limit consecutive retries, when positive, have failed.
+func serveWithRetry(stopCh <-chan struct{}, serve func() error, backoff time.Duration, limit int) error {
+	if backoff <= 0 {
+		backoff = defaultServeRetryBackoff
+	}
+	wait := backoff
+	retries := 0
+	for {
+		started := time.Now()
+		err := serve()
+		select {
+		case <-stopCh:
+			return nil
+		default:
+		}
+		if err == nil {
+			err = fmt.Errorf("the server stopped serving")
+		}
+		if isFatalServeError(err) {
+			return fmt.Errorf("unable to serve, not retrying: %w", err)
+		}
+		if time.Since(started) > maxServeRetryBackoff {
+			wait, retries = backoff, 0
+		}
+		if limit > 0 && retries >= limit {
+			return fmt.Errorf("unable to serve after %d retries: %w", retries, err)
+		}
+		retries++
+		klog.Errorf("ERROR: gin-gonic run error %s, retry %d in %s", err.Error(), retries, wait.String())
+		timer := time.NewTimer(wait)
+		select {
+		case <-stopCh:
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		wait = min(2*wait, maxServeRetryBackoff)
+	}
+}
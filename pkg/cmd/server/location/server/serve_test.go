@@ -0,0 +1,79 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestServeWithRetry(t *testing.T) {
+	// a port another listener has bound
+	l, err := net.Listen("tcp", ":0")
+	common.AssertError(t, err)
+	defer l.Close()
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	bindErr := r.Run(fmt.Sprintf(":%d", l.Addr().(*net.TCPAddr).Port))
+	common.AssertEqual(t, true, isFatalServeError(bindErr))
+
+	transient, last := errors.New("transient"), errors.New("last")
+	for _, tc := range []struct {
+		name          string
+		errs          []error
+		limit         int
+		expectErr     error
+		expectedCalls int
+	}{
+		{
+			name:          "fatal bind error stops immediately",
+			errs:          []error{bindErr},
+			expectErr:     bindErr,
+			expectedCalls: 1,
+		},
+		{
+			name:          "transient errors are retried",
+			errs:          []error{transient, transient, nil},
+			expectedCalls: 3,
+		},
+		{
+			name:          "transient errors past the limit stop",
+			errs:          []error{transient, transient, last},
+			limit:         2,
+			expectErr:     last,
+			expectedCalls: 3,
+		},
+	} {
+		stopCh := make(chan struct{})
+		calls := 0
+		serve := func() error {
+			calls++
+			if calls == len(tc.errs) && tc.expectErr == nil {
+				// serving until stopped
+				close(stopCh)
+				return nil
+			}
+			return tc.errs[calls-1]
+		}
+		start := time.Now()
+		err := serveWithRetry(stopCh, serve, time.Millisecond, tc.limit)
+		common.AssertEqual(t, tc.expectedCalls, calls)
+		if tc.expectErr == nil {
+			common.AssertError(t, err)
+		} else if !errors.Is(err, tc.expectErr) {
+			t.Errorf("%s: expected %v but got %v", tc.name, tc.expectErr, err)
+		}
+		if tc.expectedCalls == 1 {
+			// no backoff before giving up
+			common.AssertEqual(t, true, time.Since(start) < 500*time.Millisecond)
+		}
+	}
+}
+
+func TestIsFatalServeError(t *testing.T) {
+	common.AssertEqual(t, true, isFatalServeError(&net.AddrError{Err: "unknown port", Addr: ":http-alt-x"}))
+	common.AssertEqual(t, false, isFatalServeError(errors.New("connection reset")))
+}
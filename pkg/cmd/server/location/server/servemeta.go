@@ -0,0 +1,52 @@
+package server
+
+import (
+	"os"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	servedByAnnotation = "catalog-bridge/served-by"
+	servedAtAnnotation = "catalog-bridge/served-at"
+)
+
+// defaultInstanceID identifies the instance by its host name, which is the pod name when running in Kubernetes
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		klog.Errorf("reading the host name to identify the instance failed: %s", err.Error())
+		return ""
+	}
+	return hostname
+}
+
+// annotateServed annotates the Backstage entities in served content with the instance serving it and when, replacing
+// any annotations of the same name; content without Backstage entities is returned as is.  The annotations are
+// added as the content is served, so the content held and cached for serving is not changed.
+func annotateServed(content []byte, instanceID string, servedAt time.Time) []byte {
+	entities, err := parseEntities(content)
+	if err != nil {
+		return content
+	}
+	annotated := false
+	for _, entity := range entities {
+		if _, ok := entityRef(entity); !ok {
+			continue
+		}
+		annotations := entityAnnotations(entity)
+		annotations[servedByAnnotation] = instanceID
+		annotations[servedAtAnnotation] = servedAt.UTC().Format(time.RFC3339Nano)
+		annotated = true
+	}
+	if !annotated {
+		return content
+	}
+	buf, err := marshalEntities(entities, isJSON(content))
+	if err != nil {
+		klog.Errorf("serving content without the serving annotations as encoding it failed: %s", err.Error())
+		return content
+	}
+	return buf
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestServeAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		enabled bool
+		path    string
+		stored  string
+		// expectedParts are in the served content, absentParts are not
+		expectedParts []string
+		absentParts   []string
+	}{
+		{
+			name:          "yaml entities annotated",
+			enabled:       true,
+			path:          "/mnist/v1/catalog-info.yaml",
+			stored:        mnistEntity,
+			expectedParts: []string{"catalog-bridge/served-by: bridge-0", "catalog-bridge/served-at:", "name: mnist-v1"},
+		},
+		{
+			name:          "json entity annotated",
+			enabled:       true,
+			path:          "/granite/v1/catalog-info.yaml",
+			stored:        graniteEntity,
+			expectedParts: []string{`"catalog-bridge/served-by":"bridge-0"`, `"catalog-bridge/served-at":`, `"namespace":"ai"`},
+		},
+		{
+			name:          "content without entities served as is",
+			enabled:       true,
+			path:          "/iris/v1/catalog-info.yaml",
+			stored:        `[{"modelName":"iris"}]`,
+			expectedParts: []string{`[{"modelName":"iris"}]`},
+			absentParts:   []string{servedByAnnotation},
+		},
+		{
+			name:          "disabled",
+			path:          "/mnist/v1/catalog-info.yaml",
+			stored:        mnistEntity,
+			expectedParts: []string{"name: mnist-v1"},
+			absentParts:   []string{servedByAnnotation, servedAtAnnotation},
+		},
+	} {
+		ils, r := newBenchmarkServer(true)
+		ils.content = map[string]*ImportLocation{tc.path: {content: []byte(tc.stored)}}
+		ils.serveAnnotations, ils.instanceID = tc.enabled, "bridge-0"
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		common.AssertEqual(t, http.StatusOK, rec.Code)
+		common.AssertContains(t, rec.Body.String(), tc.expectedParts)
+		for _, part := range tc.absentParts {
+			common.AssertEqual(t, false, strings.Contains(rec.Body.String(), part))
+		}
+		// the content held is not changed
+		common.AssertEqual(t, tc.stored, string(ils.content[tc.path].content))
+	}
+}
@@ -1,158 +1,944 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/server/storage"
 	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/config"
 	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
 	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
 	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/sync/singleflight"
 	"k8s.io/klog/v2"
 )
 
+const (
+	defaultStorageFetchConcurrency  = 10
+	defaultModelCardRefetchInterval = 30 * time.Second
+)
+
+// storageClient is the subset of the bridge storage REST client the location service uses to read from storage
+type storageClient interface {
+	ListModelsKeys() (int, string, error, []string)
+	FetchModel(key string) (int, string, error, []byte)
+}
+
 type ImportLocationServer struct {
 	router     *gin.Engine
 	content    map[string]*ImportLocation
 	modelcards map[string]modelCardMetadata
-	storage    *storage.BridgeStorageRESTClient
+	storage    storageClient
 	format     types.NormalizerFormat
 	port       string
-	lock       sync.Mutex
+	lock       sync.RWMutex
+	// cacheLock guards the served content and fallback model card caches of locations, which reads fill while
+	// holding only the read lock
+	cacheLock sync.Mutex
+
+	// entityRefs maps the Backstage entity references of the served catalog-info to the URI of the location
+	// providing them, so that entityUniqueness can reject upserts whose entities collide with another location
+	entityRefs       map[string]string
+	entityUniqueness bool
+
+	// ingestTransformers rewrite upserted content before it is stored, serveTransformers rewrite content as it is served
+	ingestTransformers ingestTransformerChain
+	serveTransformers  serveTransformerChain
+	// redactJSONPaths are the paths of JSON content whose values are redacted as it is served
+	redactJSONPaths []string
+	// stripPaths are the paths of entity fields removed as content is served
+	stripPaths []string
+	// referenceBaseURL, when set, is the URL relative references are resolved against, with the location's URI
+	referenceBaseURL string
+
+	// versionPattern, when set, is matched in full by the version segment of upserted keys, as rawVersionPattern
+	// was configured
+	versionPattern    *regexp.Regexp
+	rawVersionPattern string
+	// latestOrder, when set, reserves the latest version segment of lookups for the highest version of the model
+	// served, by the order
+	latestOrder latestOrder
+	// versions indexes the versions of the locations with content, by URI, by model, when latestOrder is set
+	versions map[string]map[string]string
+	// contentHash is the algorithm content checksums are computed with
+	contentHash contentHash
+	// loadLimits bounds the keys and content of the entries loaded from storage
+	loadLimits loadLimits
+
+	// bulkJobs tracks the bulk upserts processed in the background; when nil, bulk upserts are only processed
+	// synchronously
+	bulkJobs *bulkJobs
+
+	// modelsIncludeEmpty has models whose versions have all been removed listed, with no versions, by default
+	modelsIncludeEmpty bool
+
+	// fetchOnMiss has lookups of URIs not in content fetched from storage, with fetches of the same key shared
+	// among concurrent lookups
+	fetchOnMiss bool
+	// modelCardMaxAge is how old cached model card content may be before it is refetched from storage when served,
+	// at most once per modelCardRefetchInterval; zero disables refetching
+	modelCardMaxAge          time.Duration
+	modelCardRefetchInterval time.Duration
+	// modelCardMaxResident caps the number of cards whose content is held in memory, evicting the content of the
+	// least recently used beyond it; zero leaves it uncapped
+	modelCardMaxResident int
+	modelCardClock       atomic.Uint64
+	// modelCardFallback serves cards synthesized from the content of locations for which no card was posted
+	modelCardFallback bool
+	// attachmentMaxBytes bounds the total size of the attachments upserted with a model card; zero disables them
+	attachmentMaxBytes int
+	// events publishes upserts and removals to the subscribers of the SSE endpoint, which sends a keepalive comment
+	// every sseHeartbeatInterval; zero disables heartbeats
+	events               *eventBroker
+	sseHeartbeatInterval time.Duration
+	// discoveryShape is the default JSON shape discovery lists URIs in
+	discoveryShape discoveryShape
+	// locationMaxAge is how long after being validated with storage cached content is served before it is
+	// revalidated, per validated; zero disables revalidation.  staleIfError serves cached content that cannot be
+	// revalidated, or fetched on a miss, as stale rather than failing.
+	locationMaxAge time.Duration
+	validated      map[string]time.Time
+	staleIfError   bool
+	// updated is when the content at each URI last changed, for discovery by time window
+	updated map[string]time.Time
+	// writeBehind, when set, buffers upserts bound for storage
+	writeBehind *writeBehind
+	// maxURILength, when positive, is the longest URI a location may have; longer ones are rejected on upsert and
+	// lookup
+	maxURILength int
+	// notReadyServes503 fails requests to the data endpoints, and readiness, until initialLoadDone is set once the
+	// initial load from storage fully succeeds
+	notReadyServes503 bool
+	initialLoadDone   atomic.Bool
+	// skipStartupLoad leaves the catalog to be filled by upserts, and any reconciles, rather than loading it from
+	// storage when the server starts
+	skipStartupLoad bool
+	// cipher, when set, encrypts the content of locations held in memory
+	cipher *contentCipher
+	// tracing starts a span for each request, whose trace ID is attached to request latencies as an exemplar, and
+	// which tracerProvider exports
+	tracing        bool
+	tracerProvider *sdktrace.TracerProvider
+	// sourceMetrics counts requests, and the served locations, by source as well
+	sourceMetrics bool
+	// notifier, when set, notifies of the keys changed in storage so they are reconciled in place of polling
+	notifier ChangeNotifier
+	// deadLetters, when set, records rejected upserts
+	deadLetters *deadLetterLog
+	// deleter deletes removed keys from storage according to storageDeleteMode, retrying up to storageDeleteRetries
+	// times with a backoff doubling from storageDeleteBackoff
+	deleter              storageDeleter
+	storageDeleteMode    storageDeleteMode
+	storageDeleteRetries int
+	storageDeleteBackoff time.Duration
+	// modelCardKeyConflicts rejects upserts reusing the model card key of another location, unless overridden
+	modelCardKeyConflicts bool
+	// emptyModelCardMode handles upserts of a model card key with empty content, which in placeholder mode hold the
+	// card with modelCardPlaceholder
+	emptyModelCardMode   emptyModelCardMode
+	modelCardPlaceholder string
+	// keyIdentityCheck compares the entities of upserted content with the model and version of the key
+	keyIdentityCheck keyIdentityCheck
+	// legacyKeyWarning warns clients upserting or deleting with the legacy <model>_<version> key format
+	legacyKeyWarning bool
+	// normalizeWhitespace normalizes the line endings and trailing whitespace of upserted content
+	normalizeWhitespace bool
+	// lookupNotFoundGuidance has the 404s of lookups point clients to discovery and suggest other versions
+	lookupNotFoundGuidance bool
+	// serveAnnotations annotates the entities served by lookups with the instanceID and the time they are served
+	serveAnnotations bool
+	instanceID       string
+	// storageKeyPrefixes maps the source prefixes of storage keys to the sources they name
+	storageKeyPrefixes map[string]string
+	// sweeper, when set, removes the locations not refreshed by an upsert within its TTL
+	sweeper *locationSweeper
+	// ownership, when set, rejects upserts of entities first created by a different source
+	ownership *entityOwnership
+	// serveValidation checks content is well-formed before serving it
+	serveValidation bool
+	// formatAutoDetect has the format of content, and so its URI, detected from the content rather than configured
+	formatAutoDetect bool
+	// contentTypes maps the format of content to the content type it is served with; when nil, the defaults are used
+	contentTypes map[types.NormalizerFormat]string
+	// contentNegotiation serves lookups as YAML or JSON as negotiated by their Accept header
+	contentNegotiation bool
+	// schemaNegotiation serves lookups in the catalog entity apiVersion requested by their schema version header
+	schemaNegotiation bool
+	fetches           singleflight.Group
+	// namespace, when set, prefixes the keys of storage shared with other instances; it is also storage
+	namespace *namespacedStorage
+	// deletes coalesces concurrent removals of the same key
+	deletes singleflight.Group
+	// fetchSem bounds the number of concurrent fetches from storage, to fetchConcurrency; when nil, fetches are
+	// unbounded
+	fetchSem         *semaphore.Weighted
+	fetchConcurrency int
+	// quarantine backs off reconciling keys whose fetches keep failing; nil disables it
+	quarantine *quarantine
+	// reconcileInterval is how often content is reconciled with storage after the initial load; zero disables it
+	reconcileInterval time.Duration
+	// reconcileStats records the reconciles with storage, and reconcileStaleAfter fails the liveness probe once none
+	// has succeeded for that long; zero disables the check
+	reconcileStats      reconcileStats
+	reconcileStaleAfter time.Duration
+	// readLockTimeout, when positive, is how long lookups, discovery and model card requests wait for the server lock
+	// before failing with a 503; zero waits indefinitely
+	readLockTimeout time.Duration
+	// responseWriteTimeout, when positive, is how long large responses may take to write before being aborted
+	responseWriteTimeout time.Duration
+	// serveRetryBackoff is the initial backoff before retrying a failed serve, and serveRetryLimit how many
+	// consecutive retries are made before Run gives up; zero retries indefinitely
+	serveRetryBackoff time.Duration
+	serveRetryLimit   int
+	// reloading is set while a reload from storage rebuilds content; upserts and removals made meanwhile are handled
+	// according to the reload strategy, waiting up to reloadWait for the reload when blocking
+	reloading      *reloadState
+	reloadStrategy reloadStrategy
+	reloadWait     time.Duration
+	// uriTemplate, when set, builds the URIs locations are served from in place of /:model/:version/:format; it is
+	// switched along with content by a reindex, and adminToken authenticates such configuration changes
+	uriTemplate atomic.Pointer[uriTemplate]
+	adminToken  string
+	// maintenance is the open maintenance window, during which writes are rejected, or nil
+	maintenance atomic.Pointer[maintenanceWindow]
+	// signer, when set, restricts model cards to signed URLs it mints and requests with the admin token
+	signer *urlSigner
+	// scopes, when set, authorizes requests by the scopes granted to their bearer token
+	scopes *scopeAuthorizer
+	// bare is set when the router has none of the request logging, metrics and ID middleware, as for benchmarking
+	bare bool
+	// certs, when set, serves over TLS with a certificate reloaded on SIGHUP
+	certs *certReloader
+	// shards, when set, restricts the keys loaded and served to those owned by this replica's shard
+	shards *shardRing
+	// memoryGuard, when set, rejects upserts while heap usage is high
+	memoryGuard *memoryGuard
+	// readThrough, when set, serves lookups and discovery from storage per request rather than from content, with
+	// fetched locations only held in its short-lived cache
+	readThrough *readThroughCache
+	// sourceBudgets limits the requests of each source in progress at once
+	sourceBudgets sourceBudgets
+	// requestIdHeader is the header request IDs are read from and echoed back in
+	requestIdHeader string
 }
 
 type modelCardMetadata struct {
 	content                  string
 	lastUpdateTimeSinceEpoch string
-	updateCount              int
-	needToUpdate             bool
+	// serving is the state serving the card updates, shared by the copies of its metadata
+	serving *modelCardServing
+	// frontMatter holds the fields of the card's YAML front-matter, if it has any
+	frontMatter map[string]interface{}
+	// storageKey is the key the card was upserted with, and so is stored under; cachedAt is when the content was
+	// upserted or last refetched from storage, and lastRefetch when a refetch was last attempted
+	storageKey  string
+	cachedAt    time.Time
+	lastRefetch time.Time
+	// an evicted card keeps its metadata but not its content
+	evicted bool
+	// attachments are the card's binary assets by name; they are only held in memory, so are kept when the card is
+	// evicted
+	attachments map[string]modelCardAttachment
 }
 
 func NewImportLocationServer(stURL, port string, nf types.NormalizerFormat) *ImportLocationServer {
 	//var content map[string]*ImportLocation
 	gin.SetMode(gin.ReleaseMode)
 	cfg, _ := util.GetK8sConfig(&config.Config{})
-	r := gin.Default()
+	requestIdHeader := envString(types.RequestIdHeaderEnvVar, defaultRequestIdHeader)
+	bare := envBool(types.BareRouterEnvVar, false)
+	r := newBareRouter()
+	if !bare {
+		r = newRouter(os.Stdout, envList(types.RequestLogSkipPathsEnvVar, defaultRequestLogSkipPaths), requestIdHeader)
+	}
+	var storageClient namespacedStorageClient = storage.SetupBridgeStorageRESTClient(stURL, util.GetCurrentToken(cfg))
+	var namespace *namespacedStorage
+	if prefix := envString(types.StorageKeyPrefixEnvVar, ""); len(prefix) > 0 {
+		namespace = newNamespacedStorage(prefix, storageClient)
+		storageClient = namespace
+	}
 	i := &ImportLocationServer{
 		router:     r,
 		content:    map[string]*ImportLocation{},
 		modelcards: map[string]modelCardMetadata{},
-		storage:    storage.SetupBridgeStorageRESTClient(stURL, util.GetCurrentToken(cfg)),
+		storage:    storageClient,
+		namespace:  namespace,
 		format:     nf,
 		port:       port,
-		lock:       sync.Mutex{},
-	}
-	r.SetTrustedProxies(nil)
-	r.TrustedPlatform = "X-Forwarded-For"
-	r.Use(addRequestId())
+		lock:       sync.RWMutex{},
 
-	klog.Infof("NewImportLocationServer content len %d", len(i.content))
-	r.GET(util.ListURI, i.handleCatalogDiscoveryGet)
-	r.POST(util.UpsertURI, i.handleCatalogUpsertPost)
-	r.DELETE(util.RemoveURI, i.handleCatalogDelete)
-	r.GET("/:model/:version/:format", func(c *gin.Context) {
-		var model ModelURI
-		if err := c.ShouldBindUri(&model); err != nil {
-			c.Status(http.StatusBadRequest)
-			return
+		entityRefs:      map[string]string{},
+		requestIdHeader: requestIdHeader,
+		bare:            bare,
+	}
+	flags := featureFlagsFromEnv()
+	if err := flags.validate(); err != nil {
+		klog.Fatalf("%s", err.Error())
+	}
+	i.setFeatures(flags)
+	if bare && (i.tracing || i.sourceMetrics) {
+		klog.Warningf("tracing and source metrics are disabled as the router is bare")
+		i.tracing, i.sourceMetrics = false, false
+	}
+	if flags.ReadThrough {
+		i.readThrough = newReadThroughCache(envDuration(types.ReadThroughCacheTTLEnvVar, 0),
+			envInt(types.ReadThroughCacheEntriesEnvVar, defaultReadThroughCacheMaxEntries))
+	}
+	fetchConcurrency := envInt(types.StorageFetchConcurrencyEnvVar, defaultStorageFetchConcurrency)
+	if fetchConcurrency < 1 {
+		klog.Errorf("storage fetch concurrency must be at least 1, using %d", defaultStorageFetchConcurrency)
+		fetchConcurrency = defaultStorageFetchConcurrency
+	}
+	i.fetchSem = semaphore.NewWeighted(int64(fetchConcurrency))
+	i.fetchConcurrency = fetchConcurrency
+	i.contentTypes = parseContentTypes(envMap(types.FormatContentTypesEnvVar))
+	i.maxURILength = envInt(types.MaxURILengthEnvVar, 0)
+	if key := strings.TrimSpace(os.Getenv(types.ContentEncryptionKeyEnvVar)); len(key) > 0 {
+		contentCipher, err := newContentCipher(key)
+		if err != nil {
+			klog.Fatalf("%s", err.Error())
 		}
-		_, uriString := util.BuildImportKeyAndURI(model.Model, model.Version, i.format)
-		i.lock.Lock()
-		defer i.lock.Unlock()
-		il, ok := i.content[uriString]
-		if !ok {
-			c.Status(http.StatusNotFound)
-			return
+		i.cipher = contentCipher
+	}
+	if path := strings.TrimSpace(os.Getenv(types.DeadLetterFileEnvVar)); len(path) > 0 {
+		deadLetters, err := newDeadLetterFile(path, int64(envInt(types.DeadLetterMaxBytesEnvVar, defaultDeadLetterMaxBytes)))
+		i.deadLetters = deadLetters
+		if err != nil {
+			klog.Errorf("%s, rejected upserts will not be recorded", err.Error())
 		}
-		klog.Infof("returning content: uriString %s with data of len %d", uriString, len(il.content))
-		il.handleCatalogInfoGet(c)
-	})
-	r.GET(util.ModelCardURI, i.handleModelCardGet)
+	}
+	shape, err := parseDiscoveryShape(os.Getenv(types.DiscoveryShapeEnvVar))
+	if err != nil {
+		klog.Errorf("%s, using %s", err.Error(), shape)
+	}
+	i.discoveryShape = shape
+	i.events = newEventBroker(envInt(types.EventsMaxSubscribersEnvVar, 0))
+	if size := envInt(types.ChangeLogSizeEnvVar, 0); size > 0 {
+		i.events.changes = newChangeLog(size)
+	}
+	i.sseHeartbeatInterval = envDuration(types.SSEHeartbeatIntervalEnvVar, defaultSSEHeartbeatInterval)
+	i.emptyModelCardMode = parseEmptyModelCardMode(os.Getenv(types.EmptyModelCardModeEnvVar))
+	i.keyIdentityCheck = parseKeyIdentityCheck(os.Getenv(types.KeyIdentityCheckEnvVar))
+	i.legacyKeyWarning = envBool(types.LegacyKeyWarningEnvVar, false)
+	i.normalizeWhitespace = envBool(types.NormalizeWhitespaceEnvVar, false)
+	i.lookupNotFoundGuidance = envBool(types.LookupNotFoundGuidanceEnvVar, false)
+	i.storageKeyPrefixes = envMap(types.StorageKeyPrefixesEnvVar)
+	if ttl := envDuration(types.LocationRefreshTTLEnvVar, 0); ttl > 0 {
+		i.sweeper = newLocationSweeper(ttl)
+	}
+	i.serveAnnotations = envBool(types.ServeMetadataAnnotationsEnvVar, false)
+	i.instanceID = envString(types.InstanceIdEnvVar, defaultInstanceID())
+	if envBool(types.EntityOwnershipLockEnvVar, false) {
+		ownership, err := newEntityOwnership(strings.TrimSpace(os.Getenv(types.EntityOwnershipFileEnvVar)))
+		if err != nil {
+			klog.Errorf("%s, starting with no entity owners", err.Error())
+		}
+		i.ownership = ownership
+	}
+	i.modelCardPlaceholder = envString(types.ModelCardPlaceholderEnvVar, defaultModelCardPlaceholder)
+	if window := envDuration(types.StorageWriteBehindWindowEnvVar, 0); window > 0 {
+		i.writeBehind = newWriteBehind(storageClient, window)
+	}
+	i.deleter = storageClient
+	i.storageDeleteMode = parseStorageDeleteMode(os.Getenv(types.StorageDeleteModeEnvVar))
+	i.storageDeleteRetries = envInt(types.StorageDeleteRetriesEnvVar, defaultStorageDeleteRetries)
+	i.storageDeleteBackoff = defaultStorageDeleteBackoff
+	i.modelCardMaxAge = envDuration(types.ModelCardMaxAgeEnvVar, 0)
+	i.modelCardRefetchInterval = envDuration(types.ModelCardRefetchIntervalEnvVar, defaultModelCardRefetchInterval)
+	i.modelCardMaxResident = envInt(types.ModelCardMaxResidentEnvVar, 0)
+	i.attachmentMaxBytes = envInt(types.ModelCardAttachmentMaxEnvVar, defaultAttachmentMaxBytes)
+	i.modelCardFallback = envBool(types.ModelCardFallbackEnvVar, false)
+	i.locationMaxAge = envDuration(types.LocationMaxAgeEnvVar, 0)
+	if threshold := envInt(types.QuarantineThresholdEnvVar, 0); threshold > 0 {
+		i.quarantine = newQuarantine(threshold,
+			envDuration(types.QuarantineBaseBackoffEnvVar, defaultQuarantineBaseBackoff),
+			envDuration(types.QuarantineMaxBackoffEnvVar, defaultQuarantineMaxBackoff))
+	}
+	i.reconcileInterval = envDuration(types.ReconcileIntervalEnvVar, 0)
+	i.reconcileStaleAfter = envDuration(types.ReconcileStaleAfterEnvVar, 0)
+	i.readLockTimeout = envDuration(types.ReadLockTimeoutEnvVar, 0)
+	i.serveRetryBackoff = envDuration(types.ServeRetryBackoffEnvVar, defaultServeRetryBackoff)
+	i.serveRetryLimit = envInt(types.ServeRetryLimitEnvVar, 0)
+	i.responseWriteTimeout = envDuration(types.ResponseWriteTimeoutEnvVar, 0)
+	i.reloadStrategy = parseReloadStrategy(os.Getenv(types.ReloadUpsertStrategyEnvVar))
+	i.reloadWait = envDuration(types.ReloadUpsertWaitEnvVar, defaultReloadWait)
+	if raw := strings.TrimSpace(os.Getenv(types.URITemplateEnvVar)); len(raw) > 0 && raw != defaultURITemplate {
+		t, err := parseURITemplate(raw)
+		if err != nil {
+			klog.Errorf("invalid URI template, using %s: %s", defaultURITemplate, err.Error())
+		} else {
+			i.uriTemplate.Store(t)
+		}
+	}
+	i.adminToken = os.Getenv(types.AdminTokenEnvVar)
+	i.scopes = newScopeAuthorizer(envMap(types.AuthScopeTokensEnvVar), os.Getenv(types.AuthJWTKeyEnvVar), i.adminToken)
+	if maxJobs := envInt(types.BulkMaxConcurrentJobsEnvVar, defaultBulkMaxConcurrentJobs); maxJobs > 0 {
+		i.bulkJobs = newBulkJobs(maxJobs, defaultBulkJobsRetained)
+	}
+	if raw := strings.TrimSpace(os.Getenv(types.VersionPatternEnvVar)); len(raw) > 0 {
+		re, err := parseVersionPattern(raw)
+		if err != nil {
+			klog.Errorf("%s, versions will not be validated", err.Error())
+		} else {
+			i.versionPattern, i.rawVersionPattern = re, raw
+		}
+	}
+	order, err := parseLatestOrder(os.Getenv(types.LatestVersionOrderEnvVar))
+	if err != nil {
+		klog.Errorf("%s, the latest version will not be resolved", err.Error())
+	}
+	i.latestOrder = order
+	hash, err := parseContentHash(os.Getenv(types.ContentHashAlgorithmEnvVar))
+	if err != nil {
+		klog.Errorf("%s, using %s", err.Error(), hash)
+	}
+	i.contentHash = hash
+	i.loadLimits = loadLimits{
+		maxKeyLength:    envInt(types.LoadMaxKeyLengthEnvVar, 0),
+		maxContentBytes: envInt(types.LoadMaxContentBytesEnvVar, 0),
+	}
+	if threshold := envInt(types.UpsertMaxHeapBytesEnvVar, 0); threshold > 0 {
+		i.memoryGuard = newMemoryGuard(uint64(threshold), envDuration(types.MemorySampleIntervalEnvVar, defaultMemorySampleInterval), runtimeHeapInUse)
+	}
+	if count := envInt(types.ShardCountEnvVar, 0); count > 1 {
+		shards, err := newShardRing(envInt(types.ShardIndexEnvVar, 0), count)
+		if err != nil {
+			klog.Fatalf("%s", err.Error())
+		}
+		i.shards = shards
+	}
+	certFile, keyFile := strings.TrimSpace(os.Getenv(types.TLSCertFileEnvVar)), strings.TrimSpace(os.Getenv(types.TLSKeyFileEnvVar))
+	switch {
+	case len(certFile) > 0 && len(keyFile) > 0:
+		certs, err := newCertReloader(certFile, keyFile)
+		if err != nil {
+			klog.Fatalf("%s", err.Error())
+		}
+		i.certs = certs
+	case len(certFile) > 0 || len(keyFile) > 0:
+		klog.Errorf("both %s and %s must be set to serve TLS, serving plain HTTP", types.TLSCertFileEnvVar, types.TLSKeyFileEnvVar)
+	}
+	if signingKey := os.Getenv(types.ModelCardSigningKeyEnvVar); len(signingKey) > 0 {
+		i.signer = newURLSigner(signingKey)
+	}
+	if annotations := envMap(types.IngestAnnotationsEnvVar); len(annotations) > 0 {
+		i.ingestTransformers = append(i.ingestTransformers, &annotationTransformer{annotations: annotations})
+	}
+	if annotations := downwardAPIAnnotations(envMap(types.DownwardAPIAnnotationsEnvVar)); len(annotations) > 0 {
+		i.serveTransformers = append(i.serveTransformers, &annotationTransformer{annotations: annotations})
+	}
+	defaults := &specDefaultsTransformer{
+		owner:  strings.TrimSpace(os.Getenv(types.ServeDefaultOwnerEnvVar)),
+		system: strings.TrimSpace(os.Getenv(types.ServeDefaultSystemEnvVar)),
+	}
+	if len(defaults.owner) > 0 || len(defaults.system) > 0 {
+		i.serveTransformers = append(i.serveTransformers, defaults)
+	}
+	if paths := envList(types.ServeRedactJSONPathsEnvVar, nil); len(paths) > 0 {
+		i.redactJSONPaths = paths
+		i.serveTransformers = append(i.serveTransformers,
+			newJSONRedactionTransformer(paths, envString(types.ServeRedactPlaceholderEnvVar, defaultRedactionPlaceholder)))
+	}
+	if paths := envList(types.ServeStripPathsEnvVar, nil); len(paths) > 0 {
+		i.stripPaths = paths
+		i.serveTransformers = append(i.serveTransformers, newStripTransformer(paths))
+	}
+	if base := os.Getenv(types.ServeReferenceBaseURLEnvVar); len(base) > 0 {
+		refs, err := newReferenceTransformer(base)
+		if err != nil {
+			klog.Errorf("%s, relative references are served as stored", err.Error())
+		} else {
+			i.referenceBaseURL = refs.base.String()
+			i.serveTransformers = append(i.serveTransformers, refs)
+		}
+	}
+	if i.tracing {
+		tp, err := newTracerProvider(context.Background())
+		if err != nil {
+			klog.Errorf("%s, spans will not be exported", err.Error())
+		}
+		i.tracerProvider = tp
+		r.Use(tracing())
+	}
+	i.sourceBudgets = newSourceBudgets(envMap(types.SourceConcurrencyBudgetsEnvVar))
+	if len(i.sourceBudgets) > 0 {
+		r.Use(i.sourceBudgets.limit(i.requestSource))
+	}
+	sources := envList(types.DiscoverySourcesEnvVar, []string{types.KServeNormalizer, types.KubeflowNormalizer})
+	if i.sourceMetrics {
+		labels := newSourceLabels(sources)
+		r.Use(sourceMetrics(i.requestSource, labels))
+		prometheus.MustRegister(&catalogSourceCollector{server: i, labels: labels})
+	}
+	if err := configureClientIP(r, envString(types.TrustedPlatformEnvVar, defaultTrustedPlatform), envList(types.TrustedProxiesEnvVar, defaultTrustedProxies),
+		envBool(types.ClientIPStrictEnvVar, false)); err != nil {
+		klog.Fatalf("%s", err.Error())
+	}
+
+	r.Use(scopeAuthorization(i.scopes))
+
+	klog.Infof("NewImportLocationServer content len %d", len(i.content))
+	i.registerRoutes(r, sources)
 	return i
 }
 
-// Middleware adding request ID to gin context.
-// Note that this is a simple unique ID that can be used for debugging purposes.
-// In the future, this might be replaced with OpenTelemetry IDs/tooling.
-func addRequestId() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Set("requestId", uuid.New().String())
-		c.Next()
+// registerRoutes registers the location service's handlers, along with the middleware particular to each route, on
+// the router, with discovery endpoints for each of sources
+func (i *ImportLocationServer) registerRoutes(r *gin.Engine, sources []string) {
+	loadGate := i.requireInitialLoad()
+	writeTimeout := responseWriteTimeout(i.responseWriteTimeout)
+	maintenance := i.rejectDuringMaintenance()
+	problems := problemDetails()
+	r.GET(util.ListURI, loadGate, writeTimeout, i.handleCatalogDiscoveryGet)
+	for _, source := range sources {
+		r.GET("/"+source+util.ListURI, loadGate, writeTimeout, i.handleSourceDiscoveryGet(source))
 	}
+	r.POST(util.UpsertURI, problems, maintenance, i.handleCatalogUpsertPost)
+	r.POST(util.UpsertModelCardURI, problems, maintenance, i.handleModelCardUpsertPost)
+	r.POST(util.UpsertBulkURI, problems, maintenance, i.handleBulkUpsertPost)
+	r.GET(util.JobURI, i.handleJobGet)
+	r.DELETE(util.RemoveURI, problems, maintenance, i.handleCatalogDelete)
+	r.GET("/:model/:version/:format", loadGate, writeTimeout, i.handleCatalogLookupGet)
+	r.GET(util.BundleURI, loadGate, writeTimeout, i.handleBundleGet)
+	r.GET(util.RelationsURI, loadGate, i.handleRelationsGet)
+	r.GET(util.ModelCardURI, modelCardAccess(i.signer, i.adminToken), loadGate, writeTimeout, i.handleModelCardGet)
+	r.POST(util.ModelCardSignURI, adminAuth(i.adminToken), i.handleModelCardSignPost)
+	r.GET(util.ModelCardMetaURI, loadGate, i.handleModelCardMetaGet)
+	r.GET(util.ModelCardTOCURI, modelCardAccess(i.signer, i.adminToken), loadGate, i.handleModelCardTOCGet)
+	r.GET(util.ModelCardAssetURI, modelCardAccess(i.signer, i.adminToken), loadGate, writeTimeout, i.handleModelCardAssetGet)
+	r.GET(util.ModelCardsURI, loadGate, i.handleModelCardsGet)
+	r.POST(util.ModelCardsRefreshURI, adminAuth(i.adminToken), i.handleModelCardsRefreshPost)
+	r.POST(util.ModelCardsReparseURI, adminAuth(i.adminToken), i.handleModelCardsReparsePost)
+	r.GET(util.MetricsURI, gin.WrapH(metricsHandler(i.tracing)))
+	r.GET(util.MetricsInFlightURI, handleInFlightGet)
+	r.GET(util.QuarantineURI, i.handleQuarantineGet)
+	r.GET(util.ManifestURI, loadGate, writeTimeout, i.handleManifestGet)
+	r.GET(util.FormatsURI, i.handleFormatsGet)
+	r.GET(util.ContractURI, i.handleContractGet)
+	r.GET(util.ModelsURI, loadGate, writeTimeout, i.handleModelsGet)
+	r.GET(util.DriftURI, adminAuth(i.adminToken), loadGate, i.handleDriftGet)
+	r.GET(util.CompressionStatsURI, adminAuth(i.adminToken), loadGate, i.handleCompressionStatsGet)
+	r.GET(util.ReconcileStatsURI, i.handleReconcileStatsGet)
+	r.GET(util.EventsURI, i.handleEventsGet)
+	r.GET(util.ChangesURI, i.handleChangesGet)
+	r.GET(util.HealthzURI, i.handleHealthzGet)
+	r.GET(util.ReadyzURI, i.handleReadyzGet)
+	r.GET(util.ConfigURI, optionalAdminAuth(i.adminToken), i.handleConfigGet)
+	r.POST(util.ConfigReindexURI, adminAuth(i.adminToken), i.handleReindexPost)
+	r.POST(util.MaintenanceURI, adminAuth(i.adminToken), i.handleMaintenancePost)
+	r.NoRoute(loadGate, writeTimeout, i.handleTemplatedLookupGet)
 }
 
+// errStorageListFailed is returned, wrapped, by loads and reloads from storage that could not list its keys, which
+// leave the content as it was
+var errStorageListFailed = errors.New("listing the keys in storage failed")
+
+// loadFromStorage caches the content of every key in storage, fetching keys in parallel as the fetch semaphore
+// allows; keys in quarantine are skipped until their backoff elapses.  It returns false if the keys could not be
+// listed, along with errStorageListFailed, or any of them could not be fetched.  Content already cached is kept
+// either way, so a failed initial load serves an empty catalog until a load or reload succeeds.
 func (i *ImportLocationServer) loadFromStorage() (bool, error) {
+	keys, ok := i.listStorageKeys()
+	if !ok {
+		return false, errStorageListFailed
+	}
+	loaded := i.fetchKeys(keys, func(uri string, sb *types.StorageBody) {
+		i.cacheFetched(uri, i.newFetchedLocation(sb))
+	}, nil)
+	return loaded, nil
+}
+
+// listStorageKeys returns the keys in storage, logging and returning false if they could not be listed
+func (i *ImportLocationServer) listStorageKeys() ([]string, bool) {
 	rc, msg, err, keys := i.storage.ListModelsKeys()
 	if err != nil {
 		klog.Errorf("%s: %s", err.Error(), msg)
-		return false, nil
+		return nil, false
 	}
 	if rc != http.StatusOK {
 		klog.Errorf("bad response code from storage list models %d, %s", rc, msg)
-		return false, nil
+		return nil, false
 	}
+	return i.shards.ownedKeys(keys), true
+}
 
+// fetchKeys fetches the storage keys in parallel, as the fetch semaphore allows, calling store with the URI and
+// storage body of each key storage has content for, and, if set, unfetched with the candidate URIs of each key
+// skipped by the quarantine or whose fetch failed; it returns false if any fetch failed
+func (i *ImportLocationServer) fetchKeys(keys []string, store func(uri string, sb *types.StorageBody), unfetched func(uris []string)) bool {
+	wg := sync.WaitGroup{}
+	failed := atomic.Bool{}
 	for _, key := range keys {
-		segs := strings.Split(key, "_")
-		if len(segs) < 2 {
-			klog.Errorf("bad format for key from ListModelsKeys when splitting with '_': %s", key)
+		if err := i.loadLimits.checkKey(key); err != nil {
+			klog.Error(err.Error())
 			continue
 		}
-		il := &ImportLocation{}
-		rc, msg, err, il.content = i.storage.FetchModel(key)
-		if err != nil {
-			klog.Errorf("%s: %s", err.Error(), msg)
-			return false, nil
+		source, segs, ok := i.splitStorageKey(key)
+		if !ok {
+			continue
 		}
-		if rc != http.StatusOK {
-			klog.Errorf("bad response code from storage fetch model %s is %d, %s", key, rc, msg)
-			return false, nil
+		if i.quarantine.skip(key) {
+			if unfetched != nil {
+				unfetched(i.candidateURIs(segs[0], segs[1]))
+			}
+			continue
 		}
-		_, uri := util.BuildImportKeyAndURI(segs[0], segs[1], i.format)
-		i.lock.Lock()
-		defer i.lock.Unlock()
-		i.content[uri] = il
-		i.router.GET(uri, il.handleCatalogInfoGet)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sb, err := i.fetchStorageBody(key)
+			if err != nil {
+				klog.Error(err.Error())
+				i.quarantine.failure(key)
+				failed.Store(true)
+				if unfetched != nil {
+					unfetched(i.candidateURIs(segs[0], segs[1]))
+				}
+				return
+			}
+			i.quarantine.success(key)
+			if err = i.loadLimits.checkBody(key, sb); err != nil {
+				klog.Error(err.Error())
+				return
+			}
+			// the storage service returns an empty body for keys it does not have
+			if len(sb.Body) > 0 {
+				if len(sb.ReconcilerType) == 0 {
+					sb.ReconcilerType = source
+				}
+				_, uri := i.buildKeyAndURI(segs[0], segs[1], i.formatFor(sb.Body))
+				store(uri, sb)
+				if i.sweeper != nil {
+					i.lock.Lock()
+					i.sweeper.seen(uri, key)
+					i.lock.Unlock()
+				}
+			}
+		}()
 	}
-
-	return true, nil
+	wg.Wait()
+	return !failed.Load()
 }
 
-func (i *ImportLocationServer) Run(stopCh <-chan struct{}) {
-	ch := make(chan int)
+// Run serves until stopCh is closed, returning early with the error when serving fails fatally, as when the port is
+// in use, or keeps failing past the serve retry limit
+func (i *ImportLocationServer) Run(stopCh <-chan struct{}) error {
 	go func() {
+		// in read-through mode there is no content to load or reconcile
+		if i.readThrough != nil {
+			i.initialLoadDone.Store(true)
+			return
+		}
+		i.initialLoad(stopCh, initialLoadRetryInterval)
+		if i.notifier != nil {
+			if i.watchChanges(stopCh) {
+				return
+			}
+			klog.Info("the storage change feed ended, falling back to reconciling on the reconcile interval")
+		}
+		if i.reconcileInterval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(i.reconcileInterval)
+		defer ticker.Stop()
 		for {
 			select {
-			case <-ch:
+			case <-stopCh:
 				return
-			default:
-				err := i.router.Run(fmt.Sprintf(":%s", i.port))
+			case <-ticker.C:
+				loaded, err := i.reloadFromStorage()
 				if err != nil {
-					klog.Errorf("ERROR: gin-gonic run error %s", err.Error())
+					klog.Errorf("reconcile with storage failed: %s", err.Error())
+					continue
 				}
+				klog.V(4).Infof("reconcile with storage complete: %v", loaded)
 			}
 		}
 	}()
-	<-stopCh
+	if i.certs != nil {
+		i.certs.watchSIGHUP(stopCh)
+	}
+	go i.memoryGuard.run(stopCh)
+	go i.sweepStaleLocations(stopCh)
+	ch := make(chan struct{})
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serveWithRetry(ch, i.listenAndServe, i.serveRetryBackoff, i.serveRetryLimit)
+	}()
+	var err error
+	select {
+	case <-stopCh:
+	case err = <-serveErr:
+		klog.Errorf("%s", err.Error())
+	}
 	close(ch)
+	i.writeBehind.close()
+	i.deadLetters.close()
+	shutdownTracing(i.tracerProvider)
+	return err
+}
+
+func (i *ImportLocationServer) handleCatalogLookupGet(c *gin.Context) {
+	model, sc := i.lookupURI(c)
+	if sc != http.StatusOK {
+		c.Status(sc)
+		return
+	}
+	format := i.format
+	if i.formatAutoDetect {
+		format = formatForFileName(model.Format, i.format)
+	}
+	if len(i.latestOrder) > 0 && model.Version == latestVersion {
+		if !i.resolveLatest(c, &model, format) {
+			return
+		}
+	}
+	key, uriString := i.buildKeyAndURI(model.Model, model.Version, format)
+	if err := i.checkURILength(key, uriString); err != nil {
+		c.Status(http.StatusRequestURITooLong)
+		c.Error(err)
+		return
+	}
+	if err := i.shards.checkShard(key); err != nil {
+		c.Status(http.StatusMisdirectedRequest)
+		c.Error(err)
+		return
+	}
+	var il *ImportLocation
+	ok := false
+	var err error
+	if i.readThrough != nil {
+		il, ok, err = i.fetchThrough(model.Model, model.Version, key, uriString)
+		if err != nil {
+			klog.Error(err.Error())
+			c.Status(http.StatusServiceUnavailable)
+			c.Error(err)
+			return
+		}
+	} else {
+		if !i.lockForRead(c) {
+			return
+		}
+		il, ok = i.content[uriString]
+		i.lock.RUnlock()
+		if !ok && i.fetchOnMiss {
+			il, ok, err = i.fetchMissing(model.Model, model.Version, key, uriString)
+			if err != nil && i.staleIfError {
+				c.Status(http.StatusServiceUnavailable)
+				c.Error(fmt.Errorf("storage is unavailable and there is no cached copy of %s: %s", uriString, err.Error()))
+				return
+			}
+		}
+	}
+	if !ok {
+		i.lookupNotFound(c, model.Model, model.Version)
+		return
+	}
+	stale := false
+	il, stale, err = i.revalidate(key, uriString, il)
+	if err != nil {
+		klog.Error(err.Error())
+		c.Status(http.StatusServiceUnavailable)
+		c.Error(err)
+		return
+	}
+	if stale {
+		c.Header(staleHeader, "true")
+	}
+	// held locations are replaced rather than changed, so il is served as looked up once the lock is released
+	if !i.lockForRead(c) {
+		return
+	}
+	klog.Infof("returning content: uriString %s with data of len %d", uriString, len(il.content))
+	variant, contentType := i.negotiateVariant(c, format)
+	served, servedVariant := i.servedContent(uriString, il, variant)
+	i.lock.RUnlock()
+	if servedVariant != variant {
+		contentType = i.contentTypeFor(format)
+	}
+	served, err = i.negotiateSchema(c, il, served)
+	if err != nil {
+		c.Status(http.StatusNotAcceptable)
+		c.Error(err)
+		return
+	}
+	if i.serveValidation && served != nil {
+		if err := wellFormed(served); err != nil {
+			err = fmt.Errorf("content for key %s at %s is malformed: %s", key, uriString, err.Error())
+			klog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			c.Error(err)
+			return
+		}
+	}
+	if i.serveAnnotations && served != nil {
+		served = annotateServed(served, i.instanceID, time.Now())
+	}
+	if pretty, _ := strconv.ParseBool(c.Query(util.PrettyQueryParam)); pretty {
+		served = prettyJSON(served)
+	}
+	if served != nil {
+		servedContentSize.WithLabelValues(string(format)).Observe(float64(len(served)))
+	}
+	il.handleCatalogInfoGet(c, served, contentType)
+}
+
+// fetchedLocation is a location fetched on a miss, along with the URI it was cached at
+type fetchedLocation struct {
+	uri string
+	il  *ImportLocation
+}
+
+// fetchMissing populates content with the location for a key not yet cached, if storage has it, returning it if
+// it is cached at the looked up URI
+func (i *ImportLocationServer) fetchMissing(seg1, seg2, key, uri string) (*ImportLocation, bool, error) {
+	v, err, _ := i.fetches.Do(key, func() (interface{}, error) {
+		sb, err := i.fetchStorageBody(key)
+		// the storage service returns an empty body for keys it does not have
+		if err != nil || len(sb.Body) == 0 {
+			return nil, err
+		}
+		_, fetchedURI := i.buildKeyAndURI(seg1, seg2, i.formatFor(sb.Body))
+		return &fetchedLocation{uri: fetchedURI, il: i.cacheFetched(fetchedURI, i.newFetchedLocation(sb))}, nil
+	})
+	if err != nil {
+		klog.Errorf("fetch on miss for key %s failed: %s", key, err.Error())
+		return nil, false, err
+	}
+	if v == nil || v.(*fetchedLocation).uri != uri {
+		return nil, false, nil
+	}
+	return v.(*fetchedLocation).il, true, nil
+}
+
+// newFetchedLocation creates the location for what storage has for a key
+func (i *ImportLocationServer) newFetchedLocation(sb *types.StorageBody) *ImportLocation {
+	il := &ImportLocation{content: i.cipher.seal(sb.Body), source: sb.ReconcilerType, modelCardKey: sb.ModelCardKey,
+		schemaVersion: detectSchemaVersion(sb.Body)}
+	if i.entityUniqueness {
+		il.entityRefs, _ = entityRefs(sb.Body)
+	}
+	return il
+}
+
+// cacheFetched caches a location fetched from storage for a URI, unless an upsert or delete that raced with the
+// fetch already set the URI, in which case that location takes precedence and is returned
+func (i *ImportLocationServer) cacheFetched(uri string, fetched *ImportLocation) *ImportLocation {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	il, ok := i.content[uri]
+	if ok {
+		return il
+	}
+	if i.entityUniqueness {
+		i.indexEntityRefs(uri, nil, fetched)
+	}
+	i.content[uri] = fetched
+	i.indexVersion(uri, fetched)
+	i.touch(uri)
+	i.markValidated(uri)
+	klog.Infof("cached URI %s with data of len %d fetched from storage", uri, len(fetched.content))
+	return fetched
+}
+
+// replaceFetched replaces the location at uri with one fetched from storage, unless its content is unchanged,
+// returning the location now at uri; callers must hold the server lock
+func (i *ImportLocationServer) replaceFetched(uri string, sb *types.StorageBody) *ImportLocation {
+	i.markValidated(uri)
+	current, ok := i.content[uri]
+	if ok && bytes.Equal(i.plaintext(current.content), sb.Body) {
+		return current
+	}
+	fetched := i.newFetchedLocation(sb)
+	if ok {
+		fetched.modelCardKey = current.modelCardKey
+	}
+	if i.entityUniqueness {
+		i.indexEntityRefs(uri, current, fetched)
+	}
+	i.content[uri] = fetched
+	i.indexVersion(uri, fetched)
+	i.reloading.apply(uri, fetched)
+	i.touch(uri)
+	i.events.publish(LocationEvent{Type: eventUpsert, URI: uri})
+	klog.Infof("replaced URI %s with data of len %d fetched from storage", uri, len(fetched.content))
+	return fetched
+}
+
+// fetchStorageBody fetches everything storage has for a key; all fetches from storage, whether loading, reconciling,
+// or on demand, share the fetch semaphore so their combined load on storage is bounded
+func (i *ImportLocationServer) fetchStorageBody(key string) (*types.StorageBody, error) {
+	if i.fetchSem != nil {
+		err := i.fetchSem.Acquire(context.Background(), 1)
+		if err != nil {
+			return nil, err
+		}
+		defer i.fetchSem.Release(1)
+	}
+	storageFetchesInFlight.Inc()
+	defer storageFetchesInFlight.Dec()
+	rc, msg, err, buf := i.storage.FetchModel(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", err.Error(), msg)
+	}
+	if rc != http.StatusOK {
+		return nil, fmt.Errorf("bad response code from storage fetch model %s is %d, %s", key, rc, msg)
+	}
+	sb := &types.StorageBody{}
+	err = json.Unmarshal(buf, sb)
+	if err != nil {
+		return nil, err
+	}
+	return sb, nil
 }
 
 type ImportLocation struct {
-	content []byte
+	content    []byte
+	entityRefs []string
+	// served caches content as rewritten by any serve transformers and converted to negotiated variants; guarded by
+	// the server's cacheLock
+	served servedCache
+	// modelCardKey is the key of the model card upserted with the location
+	modelCardKey string
+	// source is the normalizer type, i.e. kserve or kubeflow, that provided the location, if known
+	source string
+	// schemaVersion is the apiVersion of the entities of the content, if they share one
+	schemaVersion string
+	// fallbackCard caches the model card synthesized from the content, when no card is posted for it; guarded by the
+	// server's cacheLock
+	fallbackCard fallbackModelCard
 }
 
-func (i *ImportLocation) handleCatalogInfoGet(c *gin.Context) {
+// withModelCardKey returns a copy of the location with the model card key set; held locations are replaced rather than
+// changed, so that readers holding one observe it as it was when they looked it up
+func (i *ImportLocation) withModelCardKey(modelCardKey string) *ImportLocation {
+	updated := *i
+	updated.modelCardKey = modelCardKey
+	return &updated
+}
+
+// handleCatalogInfoGet serves the location's content, as decrypted and transformed for serving
+func (i *ImportLocation) handleCatalogInfoGet(c *gin.Context, served []byte, contentType string) {
 	if i.content == nil {
 		c.Status(http.StatusNotFound)
 		return
 	}
-	c.Data(http.StatusOK, "Content-Type: application/json", i.content)
+	c.Data(http.StatusOK, contentType, served)
 }
 
 type DicoveryResponse struct {
@@ -160,19 +946,61 @@ type DicoveryResponse struct {
 }
 
 func (i *ImportLocationServer) handleCatalogDiscoveryGet(c *gin.Context) {
+	i.discover(c, "")
+}
+
+// handleSourceDiscoveryGet returns the handler for the discovery endpoint of a single source
+func (i *ImportLocationServer) handleSourceDiscoveryGet(source string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		i.discover(c, source)
+	}
+}
+
+// discover responds with the URIs of the locations provided by the source, or of every location when source is empty
+func (i *ImportLocationServer) discover(c *gin.Context, source string) {
+	if i.readThrough != nil {
+		i.discoverThrough(c, source)
+		return
+	}
+	if !i.lockForRead(c) {
+		return
+	}
+	w, err := parseWindow(c)
+	if err != nil {
+		i.lock.RUnlock()
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	if detailed, _ := strconv.ParseBool(c.Query(util.DetailedQueryParam)); detailed {
+		i.discoverDetailed(c, source, w)
+		return
+	}
 	d := &DicoveryResponse{}
-	i.lock.Lock()
-	defer i.lock.Unlock()
 	for uri, il := range i.content {
 		//TODO normalizer id should be part of the model lookup URI a la "kubeflow/mnist/v1" or "kserve/mnist/v1"
 
 		// since we cannot delete handlers from gin, when we delete a location, rather than removing from the map,
 		// we set the contents field to nil, so we check for that before deciding to in include the URI
-		if il.content != nil {
+		if il.content != nil && (len(source) == 0 || il.source == source) && i.includes(w, uri) {
 			d.Uris = append(d.Uris, uri)
 		}
 	}
-	content, err := json.Marshal(d)
+	// the URIs are shaped and written once the lock is released, so a slow client does not hold up upserts
+	i.lock.RUnlock()
+	if stream, _ := strconv.ParseBool(c.Query(util.StreamQueryParam)); stream {
+		i.streamDiscovery(c, d.Uris)
+		return
+	}
+	content, err := i.shapeDiscovery(c, d.Uris)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	if content == nil {
+		content, err = json.Marshal(d)
+	}
 	if err != nil {
 		c.Status(http.StatusInternalServerError)
 		c.Error(err)
@@ -187,13 +1015,39 @@ type ModelURI struct {
 	Format  string `uri:"format" binding:"required"`
 }
 
+// lookupURI binds the model, version and format of a lookup from its route or, with a URI template, from its path
+func (i *ImportLocationServer) lookupURI(c *gin.Context) (ModelURI, int) {
+	var model ModelURI
+	t := i.uriTemplate.Load()
+	if t == nil || c.Request.URL == nil || len(c.Request.URL.Path) == 0 {
+		if err := c.ShouldBindUri(&model); err != nil {
+			return model, http.StatusBadRequest
+		}
+		return model, http.StatusOK
+	}
+	var ok bool
+	model.Model, model.Version, model.Format, ok = t.parse(c.Request.URL.Path)
+	if !ok {
+		return model, http.StatusNotFound
+	}
+	return model, http.StatusOK
+}
+
 func (u *ImportLocationServer) handleCatalogUpsertPost(c *gin.Context) {
-	key := c.Query("key")
+	// checked before the body is read, including by the dead letter capture, so a flood of large upserts is turned
+	// away cheaply
+	if u.memoryGuard.rejectUnderPressure(c) {
+		return
+	}
+	defer u.deadLetters.captureRejection(c)()
+	// keys pushed back by the storage service carry any namespace prefix
+	key := u.namespace.strip(c.Query("key"))
 	if len(key) == 0 {
 		c.Status(http.StatusBadRequest)
 		c.Error(fmt.Errorf("need a 'key' parameter"))
 		return
 	}
+	u.warnLegacyKey(c, key)
 	var postBody rest.PostBody
 	err := c.BindJSON(&postBody)
 	if err != nil {
@@ -203,41 +1057,159 @@ func (u *ImportLocationServer) handleCatalogUpsertPost(c *gin.Context) {
 		c.Error(err)
 		return
 	}
+	override, _ := strconv.ParseBool(c.Query(util.OverrideQueryParam))
+	sc, err := u.upsertKey(key, c.Query(util.TypeQueryParam), override, postBody)
+	c.Status(sc)
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// upsertKey upserts the location of a key provided by the source, returning the status code of the upsert
+func (u *ImportLocationServer) upsertKey(key, source string, override bool, postBody rest.PostBody) (int, error) {
 	segs := strings.Split(key, "_")
 	if len(segs) < 2 {
-		c.Status(http.StatusBadRequest)
-		c.Error(fmt.Errorf("bad key format: %s", key))
-		return
+		return http.StatusBadRequest, fmt.Errorf("bad key format: %s", key)
+	}
+	// normalized before anything is derived from the content, and so that storage is written the normalized content
+	if u.normalizeWhitespace {
+		postBody.Body = normalizeWhitespace(postBody.Body)
+	}
+	if err := u.checkVersion(key, segs[1]); err != nil {
+		klog.Error(err.Error())
+		return http.StatusBadRequest, err
+	}
+	if err := u.shards.checkShard(key); err != nil {
+		return http.StatusMisdirectedRequest, err
+	}
+	if err := u.checkKeyIdentity(key, segs[0], segs[1], postBody.Body); err != nil {
+		klog.Error(err.Error())
+		return http.StatusBadRequest, err
+	}
+	if status, err := u.checkAttachments(postBody); err != nil {
+		klog.Error(err.Error())
+		return status, err
+	}
+	var err error
+	cardContent, holdCard := postBody.ModelCard, true
+	if len(postBody.ModelCardKey) > 0 {
+		cardContent, holdCard, err = u.checkEmptyModelCard(postBody.ModelCardKey, postBody.ModelCard)
+		if err != nil {
+			klog.Error(err.Error())
+			return http.StatusBadRequest, err
+		}
+	}
+	if u.readThrough != nil {
+		return u.upsertThrough(key, postBody, cardContent, holdCard, override)
+	}
+	il := &ImportLocation{source: source}
+	if holdCard {
+		il.modelCardKey = postBody.ModelCardKey
+	}
+	il.content, err = u.ingestTransformers.Transform(key, postBody.Body)
+	if err != nil {
+		klog.Error(err.Error())
+		return http.StatusBadRequest, err
+	}
+	il.schemaVersion = detectSchemaVersion(il.content)
+	if u.entityUniqueness || u.ownership != nil {
+		il.entityRefs, err = entityRefs(il.content)
+		if err != nil {
+			klog.Infof("unable to parse entities for key %s so skipping the uniqueness and ownership checks: %s", key, err.Error())
+		}
+	}
+	if !u.lockForMutation() {
+		return http.StatusServiceUnavailable, fmt.Errorf("a reload from storage is in progress, retry the upsert of %s", key)
 	}
-	//TODO normalizer id should be part of the model lookup URI
-	_, uriString := util.BuildImportKeyAndURI(segs[0], segs[1], u.format)
-	il := &ImportLocation{}
-	il.content = postBody.Body
-	u.lock.Lock()
 	defer u.lock.Unlock()
+	// the URI is built under the lock so that a reindex cannot switch the URI template in between
+	//TODO normalizer id should be part of the model lookup URI
+	format := u.formatFor(il.content)
+	_, uriString := u.buildKeyAndURI(segs[0], segs[1], format)
+	if err = u.checkURILength(key, uriString); err != nil {
+		klog.Error(err.Error())
+		return http.StatusBadRequest, err
+	}
+	if err = u.checkModelCardKey(postBody.ModelCardKey, key, override); err != nil {
+		klog.Error(err.Error())
+		return http.StatusConflict, err
+	}
+	if !override {
+		if err = u.ownership.check(il.entityRefs, il.source); err != nil {
+			klog.Error(err.Error())
+			return http.StatusConflict, err
+		}
+	}
+	if u.entityUniqueness {
+		err = u.checkEntityRefs(uriString, il.entityRefs)
+		if err != nil {
+			klog.Error(err.Error())
+			return http.StatusConflict, err
+		}
+		u.indexEntityRefs(uriString, u.content[uriString], il)
+	}
+	// an override transfers the ownership of the entities to the upserting source
+	u.ownership.claim(il.entityRefs, il.source, override)
+	old := u.content[uriString]
+	// only changed content is written, so the storage service pushing the upsert back to us does not write it again
+	changed := old == nil || !bytes.Equal(u.plaintext(old.content), il.content)
+	upsertedContentSize.WithLabelValues(string(format)).Observe(float64(len(il.content)))
+	il.content = u.cipher.seal(il.content)
 	u.content[uriString] = il
+	u.indexVersion(uriString, il)
+	u.markValidated(uriString)
+	u.sweeper.refresh(uriString, key)
+	u.reloading.apply(uriString, il)
+	if changed {
+		u.touch(uriString)
+		u.events.publish(LocationEvent{Type: eventUpsert, URI: uriString})
+		// storage is written the card as held, i.e. with any placeholder
+		if holdCard {
+			postBody.ModelCard = cardContent
+		}
+		u.writeBehind.enqueue(key, il.source, postBody)
+	}
+	// with auto-detection, the format of a model version can change, in which case its location at the URI of its
+	// previous format is removed
+	for _, uri := range u.candidateURIs(segs[0], segs[1]) {
+		if _, ok := u.content[uri]; ok && uri != uriString {
+			u.removeLocation(uri)
+		}
+	}
+	if holdCard {
+		u.holdModelCard(key, postBody, cardContent, override)
+	}
+	klog.Infof("Upserting URI %s with data of len %d with modelcard key %s and modelcard len %d", uriString, len(il.content), postBody.ModelCardKey, len(postBody.ModelCard))
+	return http.StatusCreated, nil
+}
+
+// holdModelCard holds, or updates the metadata of, the model card of an upsert of key; callers must hold the lock
+func (u *ImportLocationServer) holdModelCard(key string, postBody rest.PostBody, cardContent string, override bool) {
 	mcm, ok := u.modelcards[postBody.ModelCardKey]
 	if !ok {
-		mcm = modelCardMetadata{
-			content:                  postBody.ModelCard,
-			lastUpdateTimeSinceEpoch: postBody.LastUpdateTimeSinceEpoch,
-			needToUpdate:             true,
-			updateCount:              0,
-		}
+		mcm = newModelCardMetadata(postBody.ModelCardKey, cardContent, postBody.LastUpdateTimeSinceEpoch)
+		mcm.storageKey = key
 	} else {
-		if mcm.lastUpdateTimeSinceEpoch != postBody.LastUpdateTimeSinceEpoch {
-			mcm.lastUpdateTimeSinceEpoch = postBody.LastUpdateTimeSinceEpoch
-			mcm.needToUpdate = true
-			mcm.updateCount = 0
+		if override {
+			mcm.storageKey = key
 		}
+		// the storage service pushes upserts back, so a card is often upserted unchanged
+		if mcm.reconcile(postBody.ModelCardKey, cardContent, postBody.LastUpdateTimeSinceEpoch) {
+			mcm.cachedAt = time.Now()
+		}
+	}
+	if len(postBody.Attachments) > 0 {
+		mcm.attachments = newAttachments(postBody.Attachments)
 	}
 	u.modelcards[postBody.ModelCardKey] = mcm
-	klog.Infof("Upserting URI %s with data of len %d with modelcard key %s and modelcard len %d", uriString, len(postBody.Body), postBody.ModelCardKey, len(postBody.ModelCard))
-	c.Status(http.StatusCreated)
+	if u.modelCardMaxResident > 0 {
+		u.useModelCard(postBody.ModelCardKey)
+		u.evictModelCards(postBody.ModelCardKey)
+	}
 }
 
 func (u *ImportLocationServer) handleCatalogDelete(c *gin.Context) {
-	key := c.Query("key")
+	key := u.namespace.strip(c.Query("key"))
 	if len(key) == 0 {
 		c.Status(http.StatusBadRequest)
 		c.Error(fmt.Errorf("need a 'key' parameter"))
@@ -249,39 +1221,148 @@ func (u *ImportLocationServer) handleCatalogDelete(c *gin.Context) {
 		c.Error(fmt.Errorf("bad key format: %s", key))
 		return
 	}
-	//TODO normalizer id should be part of the model lookup URI
-	_, uri := util.BuildImportKeyAndURI(segs[0], segs[1], u.format)
-	klog.Infof("Removing URI %s", uri)
+	u.warnLegacyKey(c, key)
+	if err := u.shards.checkShard(key); err != nil {
+		c.Status(http.StatusMisdirectedRequest)
+		c.Error(err)
+		return
+	}
+	// concurrent removals of the same key share one removal, so storage is deleted from once and a duplicate cannot
+	// act on state a strict rollback is restoring
+	v, err, shared := u.deletes.Do(key, func() (interface{}, error) {
+		return u.removeKey(key, segs)
+	})
+	// a sweep of the key this removal joined may have found it refreshed and kept it, in which case it is removed anew
+	for errors.Is(err, errSweepSkipped) {
+		v, err, shared = u.deletes.Do(key, func() (interface{}, error) {
+			return u.removeKey(key, segs)
+		})
+	}
+	if shared {
+		klog.V(4).Infof("removal of %s shared with a concurrent removal of it", key)
+	}
+	c.Status(v.(int))
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// removeKey removes the locations of a key, and deletes it from storage as configured, returning the status code
+// of the removal
+func (u *ImportLocationServer) removeKey(key string, segs []string) (int, error) {
+	return u.removeKeyUnless(key, segs, nil)
+}
+
+// removeKeyUnless removes the locations of a key as removeKey does, unless keep, called with the server lock held
+// and the candidate URIs of the key, returns true, in which case nothing is removed and errSweepSkipped is returned
+func (u *ImportLocationServer) removeKeyUnless(key string, segs []string, keep func(uris []string) bool) (int, error) {
+	u.readThrough.evict(key)
 	// you don't unbind URIs, so we remove its content regardless of removing it from the map so that
 	// when backstage calls, we can return it a not found if the content is now nil
+	if !u.lockForMutation() {
+		return http.StatusServiceUnavailable, fmt.Errorf("a reload from storage is in progress, retry the removal of %s", key)
+	}
+	//TODO normalizer id should be part of the model lookup URI
+	uris := u.candidateURIs(segs[0], segs[1])
+	if keep != nil && keep(uris) {
+		u.lock.Unlock()
+		return http.StatusOK, errSweepSkipped
+	}
+	klog.Infof("Removing URIs %v", uris)
+	removed := u.removeLocations(uris)
+	for _, il := range removed {
+		if len(il.modelCardKey) > 0 {
+			modelCardsServed.DeleteLabelValues(il.modelCardKey)
+		}
+	}
+	// a buffered upsert of the key would otherwise recreate it in storage
+	pending, wasPending := u.writeBehind.cancel(key)
+	u.lock.Unlock()
+
+	// storage is deleted from without the lock, as retries can take a while
+	err := u.deleteFromStorage(key)
+	if err == nil {
+		return http.StatusOK, nil
+	}
+	klog.Error(err.Error())
+	if u.storageDeleteMode != storageDeleteStrict {
+		return http.StatusOK, nil
+	}
 	u.lock.Lock()
-	defer u.lock.Unlock()
+	u.restoreLocations(removed)
+	u.lock.Unlock()
+	if wasPending {
+		u.writeBehind.enqueue(key, pending.source, pending.body)
+	}
+	return http.StatusInternalServerError, err
+}
+
+// removeLocation clears the content of the location at uri; callers must hold the server lock
+func (u *ImportLocationServer) removeLocation(uri string) {
 	il, ok := u.content[uri]
 	if ok {
-		il.content = nil
+		if il.content != nil {
+			u.events.publish(LocationEvent{Type: eventRemove, URI: uri})
+		}
+		u.unindexEntityRefs(uri, il)
+		// the removed location replaces the one held, which readers may still be serving
+		removed := *il
+		removed.content = nil
+		removed.served = servedCache{}
+		removed.entityRefs = nil
+		u.content[uri] = &removed
+		u.indexVersion(uri, &removed)
+		u.touch(uri)
+		u.sweeper.forget(uri)
 	}
-	c.Status(http.StatusOK)
+	// a removal during a reload also marks the location removed in the map being rebuilt, so the reload
+	// cannot restore it
+	u.reloading.apply(uri, &ImportLocation{})
 }
 
 func (i *ImportLocationServer) handleModelCardGet(c *gin.Context) {
-	i.lock.Lock()
-	defer i.lock.Unlock()
 	key := c.Query(util.KeyQueryParam)
+	i.refreshModelCard(key)
+	if !i.restoreModelCard(key) {
+		i.lock.RLock()
+		_, ok := i.modelcards[key]
+		i.lock.RUnlock()
+		if ok {
+			c.Status(http.StatusServiceUnavailable)
+			c.Error(fmt.Errorf("the evicted model card %s could not be refetched from storage", key))
+			return
+		}
+	}
+	if !i.lockForRead(c) {
+		return
+	}
 	content, ok := i.modelcards[key]
 	if !ok {
+		card, synthesized := i.fallbackModelCard(key)
+		i.lock.RUnlock()
+		if synthesized {
+			klog.Infof("return model card synthesized from the content of %s", key)
+			c.Data(http.StatusOK, "Content-Type: text/markdown", card)
+			return
+		}
 		klog.Infof("no model card found for %s", key)
 		c.Status(http.StatusNotFound)
 		return
 	}
-	if !content.needToUpdate && content.updateCount > 10{
-	   klog.Infof("no update required for model card %s", key)
+	serve := content.serving.serve()
+	if serve {
+		// only fetches answered with the content are counted, not those answered as not modified
+		modelCardsServed.WithLabelValues(key).Inc()
+		if i.modelCardMaxResident > 0 {
+			i.useModelCard(key)
+		}
+	}
+	i.lock.RUnlock()
+	if !serve {
+		klog.Infof("no update required for model card %s", key)
 		c.Status(http.StatusNotModified)
 		return
 	}
 	klog.Infof("return model card content for %s", key)
-	content.needToUpdate = false
-    content.updateCount++
-	i.modelcards[key] = content
 	c.Data(http.StatusOK, "Content-Type: text/markdown", []byte(content.content))
-
 }
@@ -2,16 +2,24 @@ package server
 
 import (
      "bytes"
+     "errors"
+     "fmt"
      "io"
      "net/http"
      "net/url"
+     "sort"
      "strings"
+     "sync"
      "testing"
+     "time"
 
      "github.com/gin-gonic/gin"
+     dto "github.com/prometheus/client_model/go"
      "github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
      "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
      testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+     stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+     "golang.org/x/sync/semaphore"
      "k8s.io/apimachinery/pkg/util/json"
 )
 
@@ -90,14 +98,14 @@ func TestHandleCatalogDiscoveryGetModel(t *testing.T) {
 			expectedSC: http.StatusNotFound,
 			param:      "foo",
 			content: map[string]modelCardMetadata{
-				"bar": {content: "bar", needToUpdate: true},
+				"bar": {content: "bar", serving: &modelCardServing{needToUpdate: true}},
 			},
 		},
 		{
 			name:       "valid key",
 			expectedSC: http.StatusOK,
 			content: map[string]modelCardMetadata{
-				"foo": {content: "bar", needToUpdate: true},
+				"foo": {content: "bar", serving: &modelCardServing{needToUpdate: true}},
 			},
 			param:        "foo",
 			expectedBody: `bar`,
@@ -269,3 +277,256 @@ func TestHandleCatalogDelete(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleCatalogLookupGetFetchOnMiss(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		fetchOnMiss     bool
+		storageContent  map[string][]byte
+		failKey         bool
+		expectedSC      int
+		expectedBody    string
+		expectedCached  bool
+		expectedFetches int
+	}{
+		{
+			name:           "miss without fetch on miss",
+			storageContent: map[string][]byte{"mnist_v1": []byte("stored")},
+			expectedSC:     http.StatusNotFound,
+		},
+		{
+			name:            "miss satisfied by storage",
+			fetchOnMiss:     true,
+			storageContent:  map[string][]byte{"mnist_v1": []byte("stored")},
+			expectedSC:      http.StatusOK,
+			expectedBody:    "stored",
+			expectedCached:  true,
+			expectedFetches: 1,
+		},
+		{
+			name:            "miss not in storage",
+			fetchOnMiss:     true,
+			storageContent:  map[string][]byte{"mnist_v2": []byte("stored")},
+			expectedSC:      http.StatusNotFound,
+			expectedFetches: 1,
+		},
+		{
+			name:            "miss with storage error",
+			fetchOnMiss:     true,
+			storageContent:  map[string][]byte{"mnist_v1": []byte("stored")},
+			failKey:         true,
+			expectedSC:      http.StatusNotFound,
+			expectedFetches: 1,
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(tc.storageContent)
+		st.FailKey("mnist_v1", tc.failKey)
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, storage: st, fetchOnMiss: tc.fetchOnMiss}
+
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: "catalog-info.yaml"}}
+
+		ils.handleCatalogLookupGet(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedBody, testWriter.ResponseWriter.Body.String())
+		_, cached := ils.content["/mnist/v1/catalog-info.yaml"]
+		common.AssertEqual(t, tc.expectedCached, cached)
+		common.AssertEqual(t, tc.expectedFetches, st.FetchCount("mnist_v1"))
+	}
+}
+
+func TestHandleCatalogLookupGetFetchOnMissSingleFlight(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("stored")})
+	st.FetchDelay = 100 * time.Millisecond
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, storage: st, fetchOnMiss: true}
+
+	wg := sync.WaitGroup{}
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testWriter := testgin.NewTestResponseWriter()
+			ctx, _ := gin.CreateTestContext(testWriter)
+			ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: "catalog-info.yaml"}}
+			ils.handleCatalogLookupGet(ctx)
+			common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		}()
+	}
+	wg.Wait()
+
+	common.AssertEqual(t, 1, st.FetchCount("mnist_v1"))
+}
+
+func TestLoadFromStorage(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		storageContent  map[string][]byte
+		failKey         string
+		listErr         error
+		expectedLoaded  bool
+		expectedContent map[string]string
+	}{
+		{
+			name:           "empty storage",
+			expectedLoaded: true,
+		},
+		{
+			name: "all keys load",
+			storageContent: map[string][]byte{
+				"mnist_v1": []byte("v1"),
+				"mnist_v2": []byte("v2"),
+				"mnist":    []byte("bad key"),
+			},
+			expectedLoaded: true,
+			expectedContent: map[string]string{
+				"/mnist/v1/catalog-info.yaml": "v1",
+				"/mnist/v2/catalog-info.yaml": "v2",
+			},
+		},
+		{
+			name: "fetch failure",
+			storageContent: map[string][]byte{
+				"mnist_v1": []byte("v1"),
+				"mnist_v2": []byte("v2"),
+			},
+			failKey:        "mnist_v2",
+			expectedLoaded: false,
+			expectedContent: map[string]string{
+				"/mnist/v1/catalog-info.yaml": "v1",
+			},
+		},
+		{
+			name:           "list failure",
+			storageContent: map[string][]byte{"mnist_v1": []byte("v1")},
+			listErr:        fmt.Errorf("list failure"),
+			expectedLoaded: false,
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(tc.storageContent)
+		st.ListErr = tc.listErr
+		st.FailKey(tc.failKey, true)
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, storage: st}
+
+		loaded, err := ils.loadFromStorage()
+
+		if tc.listErr != nil {
+			// the initial load fails leaving the catalog empty
+			common.AssertEqual(t, true, errors.Is(err, errStorageListFailed))
+		} else {
+			common.AssertError(t, err)
+		}
+		common.AssertEqual(t, tc.expectedLoaded, loaded)
+		common.AssertEqual(t, len(tc.expectedContent), len(ils.content))
+		for uri, content := range tc.expectedContent {
+			il, ok := ils.content[uri]
+			common.AssertEqual(t, true, ok)
+			if ok {
+				common.AssertEqual(t, content, string(il.content))
+			}
+		}
+	}
+}
+
+func TestStorageFetchConcurrency(t *testing.T) {
+	maxFetches := 3
+	storageContent := map[string][]byte{}
+	for n := 0; n < 20; n++ {
+		storageContent[fmt.Sprintf("mnist_v%d", n)] = []byte("stored")
+	}
+	for n := 0; n < 20; n++ {
+		storageContent[fmt.Sprintf("granite_v%d", n)] = []byte("stored")
+	}
+	st := stubstorage.NewStubStorageClient(storageContent)
+	st.FetchDelay = 10 * time.Millisecond
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		modelcards:  map[string]modelCardMetadata{},
+		storage:     st,
+		fetchOnMiss: true,
+		fetchSem:    semaphore.NewWeighted(int64(maxFetches)),
+	}
+
+	// mix a full load with on demand fetches from lookups that miss the cache
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		loaded, _ := ils.loadFromStorage()
+		common.AssertEqual(t, true, loaded)
+	}()
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testWriter := testgin.NewTestResponseWriter()
+			ctx, _ := gin.CreateTestContext(testWriter)
+			ctx.Params = gin.Params{{Key: "model", Value: "granite"}, {Key: "version", Value: fmt.Sprintf("v%d", n)}, {Key: "format", Value: "catalog-info.yaml"}}
+			ils.handleCatalogLookupGet(ctx)
+			common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		}()
+	}
+	wg.Wait()
+
+	common.AssertEqual(t, true, st.MaxConcurrentFetches() <= maxFetches)
+	common.AssertEqual(t, true, st.MaxConcurrentFetches() > 0)
+	common.AssertEqual(t, 40, len(ils.content))
+	m := &dto.Metric{}
+	common.AssertError(t, storageFetchesInFlight.Write(m))
+	common.AssertEqual(t, float64(0), m.GetGauge().GetValue())
+}
+
+func TestHandleSourceDiscoveryGet(t *testing.T) {
+	st := stubstorage.NewStubStorageClient(map[string][]byte{
+		"granite_v1": []byte(graniteEntity),
+		"bert_v1":    []byte("bert"),
+	})
+	st.SetReconcilerType("granite_v1", "kubeflow")
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, storage: st}
+	loaded, err := ils.loadFromStorage()
+	common.AssertError(t, err)
+	common.AssertEqual(t, true, loaded)
+
+	for _, upsert := range []struct {
+		query string
+		body  string
+	}{
+		{query: "key=mnist_v1&type=kserve", body: mnistEntity},
+		{query: "key=mnist_v2&type=kubeflow", body: mnistUpdatedEntity},
+		{query: "key=mnist_v3&type=kserve"},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		data, err := json.Marshal(rest.PostBody{Body: []byte(upsert.body)})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: upsert.query}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+	}
+	// removed locations are not discovered by their source either
+	ils.content["/mnist/v3/catalog-info.yaml"].content = nil
+
+	for _, tc := range []struct {
+		source       string
+		expectedURIs []string
+	}{
+		{source: "kserve", expectedURIs: []string{"/mnist/v1/catalog-info.yaml"}},
+		{source: "kubeflow", expectedURIs: []string{"/granite/v1/catalog-info.yaml", "/mnist/v2/catalog-info.yaml"}},
+		{source: "", expectedURIs: []string{"/bert/v1/catalog-info.yaml", "/granite/v1/catalog-info.yaml", "/mnist/v1/catalog-info.yaml", "/mnist/v2/catalog-info.yaml"}},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		if len(tc.source) > 0 {
+			ils.handleSourceDiscoveryGet(tc.source)(ctx)
+		} else {
+			ils.handleCatalogDiscoveryGet(ctx)
+		}
+
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		d := DicoveryResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &d))
+		sort.Strings(d.Uris)
+		common.AssertEqual(t, tc.expectedURIs, d.Uris)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// shardVirtualNodes is the number of points each shard has on the ring, which evens out the share of keys each owns
+const shardVirtualNodes = 128
+
+// shardRing partitions keys between replicas by consistent hashing, so each replica loads and serves a disjoint
+// subset of the catalog: each shard has shardVirtualNodes points on a ring of 64 bit FNV-1a hashes, and a key is
+// owned by the shard of the first point at or after the key's hash.  A nil ring owns every key.
+type shardRing struct {
+	points []uint64
+	shards []int
+	// index is the shard of this replica, of count shards
+	index int
+	count int
+}
+
+// newShardRing creates the ring for shard index of count shards
+func newShardRing(index, count int) (*shardRing, error) {
+	if count < 1 || index < 0 || index >= count {
+		return nil, fmt.Errorf("shard index %d is not in the range of %d shards", index, count)
+	}
+	type point struct {
+		hash  uint64
+		shard int
+	}
+	points := make([]point, 0, count*shardVirtualNodes)
+	for shard := range count {
+		for vnode := range shardVirtualNodes {
+			points = append(points, point{hash: hashKey(strconv.Itoa(shard) + "#" + strconv.Itoa(vnode)), shard: shard})
+		}
+	}
+	sort.Slice(points, func(a, b int) bool {
+		return points[a].hash < points[b].hash
+	})
+	r := &shardRing{points: make([]uint64, len(points)), shards: make([]int, len(points)), index: index, count: count}
+	for idx, p := range points {
+		r.points[idx], r.shards[idx] = p.hash, p.shard
+	}
+	return r, nil
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// shardOf returns the shard owning key
+func (r *shardRing) shardOf(key string) int {
+	h := hashKey(key)
+	idx := sort.Search(len(r.points), func(n int) bool {
+		return r.points[n] >= h
+	})
+	if idx == len(r.points) {
+		idx = 0
+	}
+	return r.shards[idx]
+}
+
+// owns returns whether this replica's shard owns key
+func (r *shardRing) owns(key string) bool {
+	return r == nil || r.shardOf(key) == r.index
+}
+
+// ownedKeys filters keys down to those this replica's shard owns
+func (r *shardRing) ownedKeys(keys []string) []string {
+	if r == nil {
+		return keys
+	}
+	owned := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if r.owns(key) {
+			owned = append(owned, key)
+		}
+	}
+	return owned
+}
+
+// checkShard returns an error when key is not owned by this replica's shard
+func (r *shardRing) checkShard(key string) error {
+	if r.owns(key) {
+		return nil
+	}
+	return fmt.Errorf("key %s is owned by shard %d, not by this replica's shard %d of %d", key, r.shardOf(key), r.index, r.count)
+}
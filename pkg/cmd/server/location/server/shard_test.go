@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestShardRingOwnership(t *testing.T) {
+	const count = 4
+	rings := make([]*shardRing, count)
+	for index := range count {
+		r, err := newShardRing(index, count)
+		common.AssertError(t, err)
+		rings[index] = r
+	}
+	owned := make([]int, count)
+	for n := range 1000 {
+		key := fmt.Sprintf("model%d_v%d", n, n%3)
+		owners := 0
+		for index, r := range rings {
+			if r.owns(key) {
+				owners++
+				owned[index]++
+				common.AssertEqual(t, index, r.shardOf(key))
+			}
+		}
+		// a key is owned by exactly one shard
+		common.AssertEqual(t, 1, owners)
+	}
+	for _, n := range owned {
+		common.AssertEqual(t, true, n > 100)
+	}
+
+	// without sharding every key is owned
+	var none *shardRing
+	common.AssertEqual(t, true, none.owns("mnist_v1"))
+
+	for _, bad := range [][2]int{{-1, 2}, {2, 2}, {0, 0}} {
+		_, err := newShardRing(bad[0], bad[1])
+		common.AssertEqual(t, true, err != nil)
+	}
+}
+
+func TestShardedDiscoveryDisjoint(t *testing.T) {
+	const count = 3
+	storageContent := map[string][]byte{}
+	for n := range 30 {
+		storageContent[fmt.Sprintf("model%d_v1", n)] = []byte(fmt.Sprintf("model%d", n))
+	}
+	seen := map[string]int{}
+	for index := range count {
+		r, err := newShardRing(index, count)
+		common.AssertError(t, err)
+		ils := &ImportLocationServer{
+			content:    map[string]*ImportLocation{},
+			modelcards: map[string]modelCardMetadata{},
+			storage:    stubstorage.NewStubStorageClient(storageContent),
+			shards:     r,
+		}
+		loaded, err := ils.loadFromStorage()
+		common.AssertError(t, err)
+		common.AssertEqual(t, true, loaded)
+
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ils.handleCatalogDiscoveryGet(ctx)
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		resp := DicoveryResponse{}
+		common.AssertError(t, json.Unmarshal(testWriter.ResponseWriter.Body.Bytes(), &resp))
+		for _, uri := range resp.Uris {
+			seen[uri]++
+		}
+	}
+	// the shards together discover the whole catalog, each URI from a single shard
+	common.AssertEqual(t, len(storageContent), len(seen))
+	for uri, n := range seen {
+		if n != 1 {
+			t.Errorf("uri %s discovered by %d shards", uri, n)
+		}
+	}
+}
+
+func TestShardedRequestsMisdirected(t *testing.T) {
+	r, err := newShardRing(0, 2)
+	common.AssertError(t, err)
+	owned, unowned := "", ""
+	for n := 0; len(owned) == 0 || len(unowned) == 0; n++ {
+		key := fmt.Sprintf("model%d_v1", n)
+		if r.owns(key) {
+			owned = key
+		} else {
+			unowned = key
+		}
+	}
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, shards: r}
+	for _, tc := range []struct {
+		key        string
+		expectedSC int
+	}{
+		{key: owned, expectedSC: http.StatusCreated},
+		{key: unowned, expectedSC: http.StatusMisdirectedRequest},
+	} {
+		data, err := json.Marshal(rest.PostBody{Body: []byte("content")})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + tc.key}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+	}
+	common.AssertEqual(t, 1, len(ils.content))
+
+	testWriter := testgin.NewTestResponseWriter()
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Params = gin.Params{{Key: "model", Value: unowned[:len(unowned)-3]}, {Key: "version", Value: "v1"}, {Key: "format", Value: catalogInfoFileName}}
+	ils.handleCatalogLookupGet(ctx)
+	common.AssertEqual(t, http.StatusMisdirectedRequest, ctx.Writer.Status())
+}
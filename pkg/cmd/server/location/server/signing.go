@@ -0,0 +1,142 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+const (
+	defaultSignedURLTTL = 5 * time.Minute
+	maxSignedURLTTL     = 24 * time.Hour
+)
+
+// urlSigner mints and verifies short-lived model card URLs carrying an HMAC over the card key and expiry, so a
+// card can be shared without sharing credentials
+type urlSigner struct {
+	key []byte
+	now func() time.Time
+}
+
+func newURLSigner(key string) *urlSigner {
+	return &urlSigner{key: []byte(key), now: time.Now}
+}
+
+func (s *urlSigner) signature(cardKey string, exp int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(cardKey + "\n" + strconv.FormatInt(exp, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// sign returns the signed model card URI for cardKey, expiring after ttl
+func (s *urlSigner) sign(cardKey string, ttl time.Duration) (string, time.Time) {
+	expires := s.now().Add(ttl).Truncate(time.Second)
+	q := url.Values{}
+	q.Set(util.KeyQueryParam, cardKey)
+	q.Set(util.ExpiresQueryParam, strconv.FormatInt(expires.Unix(), 10))
+	q.Set(util.SignatureQueryParam, s.signature(cardKey, expires.Unix()))
+	return util.ModelCardURI + "?" + q.Encode(), expires
+}
+
+func (s *urlSigner) verify(cardKey, exp, sig string) error {
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad %s query parameter: %s", util.ExpiresQueryParam, err.Error())
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.signature(cardKey, expires))) {
+		return fmt.Errorf("bad signature for model card %s", cardKey)
+	}
+	if !s.now().Before(time.Unix(expires, 0)) {
+		return fmt.Errorf("signed URL for model card %s expired at %s", cardKey, time.Unix(expires, 0).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// modelCardAccess lets model card requests through when they carry a valid signature for the requested card, or the
+// admin bearer token; without a signer, model cards are served to all
+func modelCardAccess(signer *urlSigner, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signer == nil {
+			c.Next()
+			return
+		}
+		if sig := c.Query(util.SignatureQueryParam); len(sig) > 0 {
+			// an attachment is served with the access of its card, whose key is then part of the path
+			key := c.Query(util.KeyQueryParam)
+			if len(key) == 0 {
+				key = c.Param("key")
+			}
+			if err := signer.verify(key, c.Query(util.ExpiresQueryParam), sig); err != nil {
+				c.AbortWithError(http.StatusForbidden, err)
+				return
+			}
+			c.Next()
+			return
+		}
+		if requireAdminScope(c) {
+			return
+		}
+		presented, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if len(token) == 0 || !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			c.AbortWithError(http.StatusUnauthorized, fmt.Errorf("a signed URL or a valid admin bearer token is required"))
+			return
+		}
+		c.Next()
+	}
+}
+
+type SignedURLResponse struct {
+	URL     string `json:"url"`
+	Expires string `json:"expires"`
+}
+
+// handleModelCardSignPost mints a signed URL for the model card with the key parameter, valid for the ttl parameter
+func (i *ImportLocationServer) handleModelCardSignPost(c *gin.Context) {
+	if i.signer == nil {
+		c.Status(http.StatusNotFound)
+		c.Error(fmt.Errorf("signed model card URLs are disabled as no signing key is set"))
+		return
+	}
+	key := c.Query(util.KeyQueryParam)
+	if len(key) == 0 {
+		c.Status(http.StatusBadRequest)
+		c.Error(fmt.Errorf("need a 'key' parameter"))
+		return
+	}
+	ttl := defaultSignedURLTTL
+	if raw := c.Query(util.TTLQueryParam); len(raw) > 0 {
+		var err error
+		ttl, err = time.ParseDuration(raw)
+		if err != nil || ttl <= 0 || ttl > maxSignedURLTTL {
+			c.Status(http.StatusBadRequest)
+			c.Error(fmt.Errorf("the %s query parameter must be a positive duration of at most %s", util.TTLQueryParam, maxSignedURLTTL.String()))
+			return
+		}
+	}
+	i.lock.RLock()
+	_, ok := i.modelcards[key]
+	i.lock.RUnlock()
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	uri, expires := i.signer.sign(key, ttl)
+	buf, err := json.Marshal(&SignedURLResponse{URL: uri, Expires: expires.UTC().Format(time.RFC3339)})
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", buf)
+}
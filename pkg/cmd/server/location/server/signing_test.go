@@ -0,0 +1,102 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestSignedModelCardURLs(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := newURLSigner("signing-secret")
+	signer.now = func() time.Time { return now }
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{
+			"mnist-card":   {content: "# mnist", serving: &modelCardServing{needToUpdate: true}},
+			"granite-card": {content: "# granite", serving: &modelCardServing{needToUpdate: true}},
+		},
+		adminToken: "admin-secret",
+		signer:     signer,
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.GET(util.ModelCardURI, modelCardAccess(ils.signer, ils.adminToken), ils.handleModelCardGet)
+	r.POST(util.ModelCardSignURI, adminAuth(ils.adminToken), ils.handleModelCardSignPost)
+
+	req := httptest.NewRequest(http.MethodPost, util.ModelCardSignURI+"?key=mnist-card&ttl=1m", nil)
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	signed := &SignedURLResponse{}
+	common.AssertError(t, json.Unmarshal(rec.Body.Bytes(), signed))
+	common.AssertEqual(t, "2023-11-14T22:14:20Z", signed.Expires)
+
+	for _, tc := range []struct {
+		name       string
+		uri        string
+		token      string
+		advance    time.Duration
+		expectedSC int
+		expected   string
+	}{
+		{
+			name:       "valid signed URL",
+			uri:        signed.URL,
+			expectedSC: http.StatusOK,
+			expected:   "# mnist",
+		},
+		{
+			name:       "signed URL for another card",
+			uri:        strings.Replace(signed.URL, "key=mnist-card", "key=granite-card", 1),
+			expectedSC: http.StatusForbidden,
+		},
+		{
+			name:       "tampered signature",
+			uri:        signed.URL[:len(signed.URL)-2] + "AA",
+			expectedSC: http.StatusForbidden,
+		},
+		{
+			name:       "tampered expiry",
+			uri:        strings.Replace(signed.URL, "exp=1700000060", "exp=1700003600", 1),
+			expectedSC: http.StatusForbidden,
+		},
+		{
+			name:       "expired signed URL",
+			uri:        signed.URL,
+			advance:    time.Minute,
+			expectedSC: http.StatusForbidden,
+		},
+		{
+			name:       "unsigned and unauthenticated",
+			uri:        util.ModelCardURI + "?key=granite-card",
+			expectedSC: http.StatusUnauthorized,
+		},
+		{
+			name:       "authenticated",
+			uri:        util.ModelCardURI + "?key=granite-card",
+			token:      "admin-secret",
+			expectedSC: http.StatusOK,
+			expected:   "# granite",
+		},
+	} {
+		signer.now = func() time.Time { return now.Add(tc.advance) }
+		req = httptest.NewRequest(http.MethodGet, tc.uri, nil)
+		if len(tc.token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		common.AssertEqual(t, tc.expectedSC, rec.Code)
+		if tc.expectedSC == http.StatusOK {
+			common.AssertEqual(t, tc.expected, rec.Body.String())
+		}
+	}
+}
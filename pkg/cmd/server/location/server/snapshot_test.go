@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// TestSnapshotReads upserts two generations of a model version in turn while discovery and lookup read it
+// continuously, asserting every read observes the content of one generation along with that generation's model card
+func TestSnapshotReads(t *testing.T) {
+	ils := &ImportLocationServer{
+		content:    map[string]*ImportLocation{},
+		modelcards: map[string]modelCardMetadata{},
+	}
+	r := newBareRouter()
+	ils.registerRoutes(r, nil)
+
+	generations := []rest.PostBody{
+		{Body: []byte(mnistEntity), ModelCardKey: "mnist-v1", ModelCard: "# mnist", LastUpdateTimeSinceEpoch: "1000"},
+		{Body: []byte(mnistUpdatedEntity), ModelCardKey: "mnist-v1", ModelCard: "# mnist updated", LastUpdateTimeSinceEpoch: "2000"},
+	}
+	upsert := func(pb rest.PostBody) int {
+		data, err := json.Marshal(&pb)
+		common.AssertError(t, err)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, util.UpsertURI+"?key=mnist_v1", bytes.NewReader(data)))
+		return rec.Code
+	}
+	common.AssertEqual(t, http.StatusCreated, upsert(generations[0]))
+	consistent := map[string]string{}
+	for _, g := range generations {
+		consistent[hashSHA256.sum(g.Body)] = g.LastUpdateTimeSinceEpoch
+	}
+
+	done := make(chan struct{})
+	reads := atomic.Int64{}
+	wg := sync.WaitGroup{}
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, util.ListURI+"?detailed=true&fields=uri,checksum,modelCardKey,lastUpdateTimeSinceEpoch", nil))
+				d := &DetailedDiscoveryResponse{}
+				if err := json.Unmarshal(rec.Body.Bytes(), d); err != nil || len(d.Locations) != 1 {
+					t.Errorf("expected one location to be discovered but got %s", rec.Body.String())
+					return
+				}
+				l := d.Locations[0]
+				if ts, ok := consistent[l.Checksum]; !ok || ts != l.LastUpdateTimeSinceEpoch || l.ModelCardKey != "mnist-v1" {
+					t.Errorf("discovered %s with checksum %s and model card %s updated at %s, which no upsert posted together",
+						l.URI, l.Checksum, l.ModelCardKey, l.LastUpdateTimeSinceEpoch)
+					return
+				}
+
+				rec = httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mnist/v1/catalog-info.yaml", nil))
+				if _, ok := consistent[hashSHA256.sum(rec.Body.Bytes())]; rec.Code != http.StatusOK || !ok {
+					t.Errorf("looked up %d with content no upsert posted: %s", rec.Code, rec.Body.String())
+					return
+				}
+				reads.Add(1)
+			}
+		}()
+	}
+
+	// upserts continue until the readers have read enough times to have raced with them
+	for n := 0; n < 200 || (reads.Load() < 100 && !t.Failed()); n++ {
+		if sc := upsert(generations[(n+1)%len(generations)]); sc != http.StatusCreated && sc != http.StatusOK {
+			t.Errorf("expected the upsert to succeed but got %d", sc)
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// staleHeader marks responses serving cached content that could not be revalidated with storage
+const staleHeader = "X-Catalog-Stale"
+
+// markValidated records the content at uri is current with storage as of now; callers must hold the server lock
+func (i *ImportLocationServer) markValidated(uri string) {
+	if i.locationMaxAge <= 0 {
+		return
+	}
+	if i.validated == nil {
+		i.validated = map[string]time.Time{}
+	}
+	i.validated[uri] = time.Now()
+}
+
+// revalidate refetches the location at uri from storage when it was last validated longer than the location max
+// age ago, replacing it if storage has different content.  When the refetch fails, the cached location is returned
+// as stale with stale-if-error, and an error otherwise.
+func (i *ImportLocationServer) revalidate(key, uri string, il *ImportLocation) (*ImportLocation, bool, error) {
+	// content read through from storage is fresh already
+	if i.locationMaxAge <= 0 || il.content == nil || i.readThrough != nil {
+		return il, false, nil
+	}
+	i.lock.RLock()
+	validatedAt := i.validated[uri]
+	i.lock.RUnlock()
+	if time.Since(validatedAt) <= i.locationMaxAge {
+		return il, false, nil
+	}
+
+	v, err, _ := i.fetches.Do("revalidate:"+key, func() (interface{}, error) {
+		return i.fetchStorageBody(key)
+	})
+	if err != nil {
+		if !i.staleIfError {
+			return nil, false, fmt.Errorf("revalidating %s with storage failed: %s", uri, err.Error())
+		}
+		klog.Warningf("serving stale content for %s as revalidating it with storage failed: %s", uri, err.Error())
+		return il, true, nil
+	}
+	sb := v.(*types.StorageBody)
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	current, ok := i.content[uri]
+	if !ok || current.content == nil {
+		return il, false, nil
+	}
+	// storage not having the key is left to reconciling, which removes the location
+	if len(sb.Body) == 0 {
+		i.markValidated(uri)
+		return current, false, nil
+	}
+	return i.replaceFetched(uri, sb), false, nil
+}
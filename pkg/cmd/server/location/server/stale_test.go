@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+)
+
+func TestHandleCatalogLookupGetStale(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		cached          bool
+		validatedAgo    time.Duration
+		failKey         bool
+		staleIfError    bool
+		expectedSC      int
+		expectedBody    string
+		expectedStale   string
+		expectedFetches int
+	}{
+		{
+			name:         "fresh cached copy served without revalidating",
+			cached:       true,
+			validatedAgo: time.Second,
+			failKey:      true,
+			expectedSC:   http.StatusOK,
+			expectedBody: "cached",
+		},
+		{
+			name:            "expired cached copy revalidated with storage",
+			cached:          true,
+			validatedAgo:    time.Hour,
+			expectedSC:      http.StatusOK,
+			expectedBody:    "stored",
+			expectedFetches: 1,
+		},
+		{
+			name:            "stale cached copy served on storage error",
+			cached:          true,
+			validatedAgo:    time.Hour,
+			failKey:         true,
+			staleIfError:    true,
+			expectedSC:      http.StatusOK,
+			expectedBody:    "cached",
+			expectedStale:   "true",
+			expectedFetches: 1,
+		},
+		{
+			name:            "storage error without stale if error",
+			cached:          true,
+			validatedAgo:    time.Hour,
+			failKey:         true,
+			expectedSC:      http.StatusServiceUnavailable,
+			expectedFetches: 1,
+		},
+		{
+			name:            "no cached copy with storage error",
+			failKey:         true,
+			staleIfError:    true,
+			expectedSC:      http.StatusServiceUnavailable,
+			expectedFetches: 1,
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte("stored")})
+		st.FailKey("mnist_v1", tc.failKey)
+		ils := &ImportLocationServer{
+			content:        map[string]*ImportLocation{},
+			modelcards:     map[string]modelCardMetadata{},
+			validated:      map[string]time.Time{},
+			storage:        st,
+			fetchOnMiss:    true,
+			locationMaxAge: time.Minute,
+			staleIfError:   tc.staleIfError,
+		}
+		if tc.cached {
+			ils.content["/mnist/v1/catalog-info.yaml"] = &ImportLocation{content: []byte("cached")}
+			ils.validated["/mnist/v1/catalog-info.yaml"] = time.Now().Add(-tc.validatedAgo)
+		}
+
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: "catalog-info.yaml"}}
+
+		ils.handleCatalogLookupGet(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedBody, testWriter.ResponseWriter.Body.String())
+		common.AssertEqual(t, tc.expectedStale, testWriter.Header().Get(staleHeader))
+		common.AssertEqual(t, tc.expectedFetches, st.FetchCount("mnist_v1"))
+	}
+}
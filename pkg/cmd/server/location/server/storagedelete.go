@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// storageDeleteMode determines whether removals are also deleted from storage, and what happens when that fails
+type storageDeleteMode string
+
+const (
+	// storageDeleteOff leaves storage alone, as it is reconciled from the normalizers' current key sets
+	storageDeleteOff storageDeleteMode = "off"
+	// storageDeleteBestEffort logs storage deletes that fail after retrying and keeps the removal
+	storageDeleteBestEffort storageDeleteMode = "best-effort"
+	// storageDeleteStrict rolls the removal back and fails it when the storage delete fails after retrying
+	storageDeleteStrict storageDeleteMode = "strict"
+
+	defaultStorageDeleteRetries = 3
+	defaultStorageDeleteBackoff = 200 * time.Millisecond
+)
+
+// storageDeleter deletes keys from storage, as the bridge storage REST client does
+type storageDeleter interface {
+	DeleteModel(importKey string) (int, string, error)
+}
+
+func parseStorageDeleteMode(str string) storageDeleteMode {
+	switch s := storageDeleteMode(strings.ToLower(strings.TrimSpace(str))); s {
+	case storageDeleteOff, storageDeleteBestEffort, storageDeleteStrict:
+		return s
+	case "":
+	default:
+		klog.Errorf("invalid storage delete mode %s, using %s", str, storageDeleteOff)
+	}
+	return storageDeleteOff
+}
+
+// deleteFromStorage deletes a key from storage, retrying failures with a doubling backoff; nothing is deleted
+// unless a storage delete mode is set
+func (i *ImportLocationServer) deleteFromStorage(key string) error {
+	if i.deleter == nil || i.storageDeleteMode == "" || i.storageDeleteMode == storageDeleteOff {
+		return nil
+	}
+	backoff := i.storageDeleteBackoff
+	var err error
+	for attempt := 0; attempt <= i.storageDeleteRetries; attempt++ {
+		if attempt > 0 {
+			klog.Infof("retrying storage delete of %s in %s: %s", key, backoff.String(), err.Error())
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		rc, msg, derr := i.deleter.DeleteModel(key)
+		if derr == nil && rc != http.StatusOK {
+			derr = fmt.Errorf("bad response code %d: %s", rc, msg)
+		}
+		if derr == nil {
+			return nil
+		}
+		err = derr
+	}
+	return fmt.Errorf("storage delete of %s failed after %d attempts: %s", key, i.storageDeleteRetries+1, err.Error())
+}
+
+// removeLocations removes the locations at uris, returning copies of those removed so that they can be restored;
+// callers must hold the server lock
+func (i *ImportLocationServer) removeLocations(uris []string) map[string]ImportLocation {
+	removed := map[string]ImportLocation{}
+	for _, uri := range uris {
+		if il, ok := i.content[uri]; ok && il.content != nil {
+			removed[uri] = *il
+		}
+		i.removeLocation(uri)
+	}
+	return removed
+}
+
+// restoreLocations rolls back the removal of locations, unless they were upserted again since, publishing their
+// return as upserts; callers must hold the server lock
+func (i *ImportLocationServer) restoreLocations(removed map[string]ImportLocation) {
+	for uri, prev := range removed {
+		il, ok := i.content[uri]
+		if !ok || il.content != nil {
+			continue
+		}
+		il = &prev
+		i.content[uri] = il
+		i.indexVersion(uri, il)
+		if i.entityUniqueness {
+			i.indexEntityRefs(uri, nil, il)
+		}
+		i.reloading.apply(uri, il)
+		// subscribers saw the removal, so they are told of the location coming back
+		i.touch(uri)
+		i.events.publish(LocationEvent{Type: eventUpsert, URI: uri})
+	}
+}
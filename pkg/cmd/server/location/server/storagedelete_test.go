@@ -0,0 +1,176 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+)
+
+func TestHandleCatalogDeleteFromStorage(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		mode            storageDeleteMode
+		failDeletes     int
+		expectedSC      int
+		expectedDeletes int
+		expectRemoved   bool
+		expectedEvents  []string
+	}{
+		{
+			name:           "storage left alone by default",
+			expectedSC:     http.StatusOK,
+			expectRemoved:  true,
+			expectedEvents: []string{eventRemove},
+		},
+		{
+			name:            "strict delete succeeds",
+			mode:            storageDeleteStrict,
+			expectedSC:      http.StatusOK,
+			expectedDeletes: 1,
+			expectRemoved:   true,
+			expectedEvents:  []string{eventRemove},
+		},
+		{
+			name:            "strict delete succeeds on retry",
+			mode:            storageDeleteStrict,
+			failDeletes:     2,
+			expectedSC:      http.StatusOK,
+			expectedDeletes: 3,
+			expectRemoved:   true,
+			expectedEvents:  []string{eventRemove},
+		},
+		{
+			name:            "strict delete fails and rolls back",
+			mode:            storageDeleteStrict,
+			failDeletes:     10,
+			expectedSC:      http.StatusInternalServerError,
+			expectedDeletes: 3,
+			expectedEvents:  []string{eventRemove, eventUpsert},
+		},
+		{
+			name:            "best effort delete fails and proceeds",
+			mode:            storageDeleteBestEffort,
+			failDeletes:     10,
+			expectedSC:      http.StatusOK,
+			expectedDeletes: 3,
+			expectRemoved:   true,
+			expectedEvents:  []string{eventRemove},
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte(mnistEntity)})
+		st.FailDeletes(tc.failDeletes)
+		ils := &ImportLocationServer{
+			content: map[string]*ImportLocation{
+				"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity), entityRefs: []string{"component:default/mnist"}},
+			},
+			modelcards:           map[string]modelCardMetadata{},
+			entityRefs:           map[string]string{"component:default/mnist": "/mnist/v1/catalog-info.yaml"},
+			entityUniqueness:     true,
+			deleter:              st,
+			storageDeleteMode:    tc.mode,
+			storageDeleteRetries: 2,
+			events:               newEventBroker(0),
+		}
+		events, _ := ils.events.subscribe()
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}}
+
+		ils.handleCatalogDelete(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		common.AssertEqual(t, tc.expectedDeletes, st.DeleteCount("mnist_v1"))
+		published := []string{}
+		for len(events) > 0 {
+			e := <-events
+			common.AssertEqual(t, "/mnist/v1/catalog-info.yaml", e.URI)
+			published = append(published, e.Type)
+		}
+		common.AssertEqual(t, tc.expectedEvents, published)
+		il := ils.content["/mnist/v1/catalog-info.yaml"]
+		if tc.expectRemoved {
+			common.AssertEqual(t, true, il.content == nil)
+			common.AssertEqual(t, map[string]string{}, ils.entityRefs)
+			continue
+		}
+		common.AssertEqual(t, mnistEntity, string(il.content))
+		_, touched := ils.updated["/mnist/v1/catalog-info.yaml"]
+		common.AssertEqual(t, true, touched)
+		common.AssertEqual(t, map[string]string{"component:default/mnist": "/mnist/v1/catalog-info.yaml"}, ils.entityRefs)
+		common.AssertEqual(t, 1, len(ctx.Errors))
+	}
+}
+
+// blockingDeleter counts deletes, holding the first until released
+type blockingDeleter struct {
+	deletes atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (d *blockingDeleter) DeleteModel(importKey string) (int, string, error) {
+	if d.deletes.Add(1) == 1 {
+		close(d.started)
+		<-d.release
+	}
+	return http.StatusOK, "", nil
+}
+
+func TestHandleCatalogDeleteConcurrentDuplicates(t *testing.T) {
+	deleter := &blockingDeleter{started: make(chan struct{}), release: make(chan struct{})}
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity)},
+		},
+		modelcards:        map[string]modelCardMetadata{},
+		deleter:           deleter,
+		storageDeleteMode: storageDeleteStrict,
+		events:            newEventBroker(0),
+	}
+	events, _ := ils.events.subscribe()
+	remove := func() *gin.Context {
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}}
+		ils.handleCatalogDelete(ctx)
+		return ctx
+	}
+
+	const duplicates = 5
+	contexts := make(chan *gin.Context, duplicates)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		contexts <- remove()
+	}()
+	// the duplicates arrive while the first removal is deleting from storage
+	<-deleter.started
+	for range duplicates - 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			contexts <- remove()
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(deleter.release)
+	wg.Wait()
+	close(contexts)
+
+	for ctx := range contexts {
+		common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+		common.AssertEqual(t, 0, len(ctx.Errors))
+	}
+	common.AssertEqual(t, int32(1), deleter.deletes.Load())
+	common.AssertEqual(t, true, ils.content["/mnist/v1/catalog-info.yaml"].content == nil)
+	common.AssertEqual(t, 1, len(events))
+	common.AssertEqual(t, eventRemove, (<-events).Type)
+}
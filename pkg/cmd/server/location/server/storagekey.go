@@ -0,0 +1,36 @@
+package server
+
+import (
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// storageKeyPrefixSeparator separates the source prefix of a storage key from the model and version, as in
+// kserve/mnist_v1
+const storageKeyPrefixSeparator = "/"
+
+// splitStorageKey splits a key listed, or notified as changed, by storage into the source named by its prefix, if
+// any, and the segments of the model and version.  With source prefixes configured, a key prefixed with one of them
+// is split after it and attributed to the prefix's source, while a key with an unrecognized prefix is skipped;
+// without them, keys are split as is.  It returns false, logging why, for keys that cannot be split.
+func (i *ImportLocationServer) splitStorageKey(key string) (string, []string, bool) {
+	source := ""
+	rest := key
+	if len(i.storageKeyPrefixes) > 0 {
+		if prefix, unprefixed, ok := strings.Cut(key, storageKeyPrefixSeparator); ok {
+			source, ok = i.storageKeyPrefixes[prefix]
+			if !ok {
+				klog.Errorf("skipping key %s from storage as its source prefix %s is not configured", key, prefix)
+				return "", nil, false
+			}
+			rest = unprefixed
+		}
+	}
+	segs := strings.Split(rest, "_")
+	if len(segs) < 2 {
+		klog.Errorf("bad format for key from storage when splitting with '_': %s", key)
+		return "", nil, false
+	}
+	return source, segs, true
+}
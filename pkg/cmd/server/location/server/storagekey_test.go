@@ -0,0 +1,75 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+)
+
+func TestLoadFromStorageSourcePrefixes(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		prefixes        map[string]string
+		expectedSources map[string]string
+	}{
+		{
+			name:     "configured prefixes",
+			prefixes: map[string]string{"kserve": types.KServeNormalizer, "kf": types.KubeflowNormalizer},
+			expectedSources: map[string]string{
+				"/mnist/v1/catalog-info.yaml":   types.KServeNormalizer,
+				"/fraud/v1/catalog-info.yaml":   types.KubeflowNormalizer,
+				"/fraud/v2/catalog-info.yaml":   types.KubeflowNormalizer,
+				"/granite/v1/catalog-info.yaml": "",
+			},
+		},
+		{
+			// prefixes are not separated, so the prefixed keys load at URIs including them
+			name: "no prefixes",
+			expectedSources: map[string]string{
+				"/kserve/mnist/v1/catalog-info.yaml": "",
+				"/kf/fraud/v1/catalog-info.yaml":     "",
+				"/kf/fraud/v2/catalog-info.yaml":     "",
+				"/other/iris/v1/catalog-info.yaml":   "",
+				"/granite/v1/catalog-info.yaml":      "",
+			},
+		},
+	} {
+		st := stubstorage.NewStubStorageClient(map[string][]byte{
+			"kserve/mnist_v1": []byte("mnist"),
+			"kf/fraud_v1":     []byte("fraud v1"),
+			"kf/fraud_v2":     []byte("fraud v2"),
+			"other/iris_v1":   []byte("iris"),
+			"granite_v1":      []byte("granite"),
+		})
+		ils := &ImportLocationServer{
+			content:            map[string]*ImportLocation{},
+			modelcards:         map[string]modelCardMetadata{},
+			storage:            st,
+			storageKeyPrefixes: tc.prefixes,
+		}
+
+		loaded, err := ils.loadFromStorage()
+		common.AssertError(t, err)
+		common.AssertEqual(t, true, loaded)
+		sources := map[string]string{}
+		for uri, il := range ils.content {
+			sources[uri] = il.source
+		}
+		common.AssertEqual(t, tc.expectedSources, sources)
+	}
+
+	// the source stored with the content takes precedence over that of the prefix
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"kf/fraud_v1": []byte("fraud")})
+	st.SetReconcilerType("kf/fraud_v1", types.KServeNormalizer)
+	ils := &ImportLocationServer{
+		content:            map[string]*ImportLocation{},
+		modelcards:         map[string]modelCardMetadata{},
+		storage:            st,
+		storageKeyPrefixes: map[string]string{"kf": types.KubeflowNormalizer},
+	}
+	_, err := ils.loadFromStorage()
+	common.AssertError(t, err)
+	common.AssertEqual(t, types.KServeNormalizer, ils.content["/fraud/v1/catalog-info.yaml"].source)
+}
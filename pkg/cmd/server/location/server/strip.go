@@ -0,0 +1,63 @@
+package server
+
+// stripTransformer removes, as content is served, the fields at the configured paths of the Backstage entities in
+// catalog-info, such as annotations or deprecated spec fields Backstage warns about or rejects on import; content
+// without Backstage entities is left untouched.  Paths are written as for jsonRedactionTransformer, relative to each
+// entity, such as metadata.annotations.example\.com/internal.
+type stripTransformer struct {
+	paths [][]string
+}
+
+func newStripTransformer(paths []string) *stripTransformer {
+	s := &stripTransformer{}
+	for _, p := range paths {
+		if fields := splitRedactionPath(p); len(fields) > 0 {
+			s.paths = append(s.paths, fields)
+		}
+	}
+	return s
+}
+
+func (s *stripTransformer) Transform(uri string, content []byte) ([]byte, error) {
+	if len(s.paths) == 0 {
+		return content, nil
+	}
+	entities, err := parseEntities(content)
+	if err != nil {
+		// not content we can strip
+		return content, nil
+	}
+	changed := false
+	for _, entity := range entities {
+		for _, fields := range s.paths {
+			changed = strip(entity, fields) || changed
+		}
+	}
+	if !changed {
+		return content, nil
+	}
+	return marshalEntities(entities, isJSON(content))
+}
+
+// strip deletes the field at fields within v, returning whether anything was deleted
+func strip(v interface{}, fields []string) bool {
+	switch node := v.(type) {
+	case []interface{}:
+		changed := false
+		for _, elem := range node {
+			changed = strip(elem, fields) || changed
+		}
+		return changed
+	case map[string]interface{}:
+		child, ok := node[fields[0]]
+		if !ok {
+			return false
+		}
+		if len(fields) == 1 {
+			delete(node, fields[0])
+			return true
+		}
+		return strip(child, fields[1:])
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestStripTransformer(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		paths    []string
+		content  string
+		expected string
+	}{
+		{
+			name:     "json entity fields stripped, others preserved",
+			paths:    []string{"spec.deprecatedField", "metadata.annotations.example\\.com/internal"},
+			content:  `{"kind":"Component","metadata":{"annotations":{"example.com/internal":"x","example.com/team":"ai"},"name":"mnist"},"spec":{"deprecatedField":"old","owner":"ai-team"}}`,
+			expected: `{"kind":"Component","metadata":{"annotations":{"example.com/team":"ai"},"name":"mnist"},"spec":{"owner":"ai-team"}}`,
+		},
+		{
+			name:  "each yaml entity stripped",
+			paths: []string{"spec.owner", "spec.links.url"},
+			content: `kind: Component
+metadata:
+  name: mnist
+spec:
+  owner: ai-team
+  type: model-server
+---
+kind: Resource
+metadata:
+  name: mnist-v1
+spec:
+  links:
+  - title: api
+    url: http://internal/a
+  - title: docs
+`,
+			expected: `kind: Component
+metadata:
+  name: mnist
+spec:
+  type: model-server
+---
+kind: Resource
+metadata:
+  name: mnist-v1
+spec:
+  links:
+  - title: api
+  - title: docs
+`,
+		},
+		{
+			name:     "no configured field present",
+			paths:    []string{"spec.lifecycle"},
+			content:  mnistEntity,
+			expected: mnistEntity,
+		},
+		{
+			name:     "content without entities",
+			paths:    []string{"modelName"},
+			content:  `[{"modelName":"iris"}]`,
+			expected: `[{"modelName":"iris"}]`,
+		},
+	} {
+		out, err := newStripTransformer(tc.paths).Transform("/mnist/v1/catalog-info.yaml", []byte(tc.content))
+		common.AssertError(t, err)
+		common.AssertEqual(t, tc.expected, string(out))
+	}
+}
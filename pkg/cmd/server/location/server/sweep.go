@@ -0,0 +1,146 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// minSweepInterval bounds how often the sweeper runs for short TTLs
+const minSweepInterval = time.Second
+
+// refreshedKey is when the location of a key was last upserted, or first loaded from storage
+type refreshedKey struct {
+	key string
+	at  time.Time
+}
+
+// locationSweeper removes the locations that have not been refreshed within the TTL, for deployments whose
+// normalizers continuously re-post, where a location no longer posted is orphaned.  Its refresh times are guarded
+// by the server lock.
+type locationSweeper struct {
+	ttl       time.Duration
+	now       func() time.Time
+	refreshed map[string]refreshedKey
+}
+
+func newLocationSweeper(ttl time.Duration) *locationSweeper {
+	return &locationSweeper{ttl: ttl, now: time.Now, refreshed: map[string]refreshedKey{}}
+}
+
+// refresh records the upsert of the location at uri for key; a nil sweeper records nothing, and callers must hold
+// the server lock
+func (s *locationSweeper) refresh(uri, key string) {
+	if s == nil {
+		return
+	}
+	s.refreshed[uri] = refreshedKey{key: key, at: s.now()}
+}
+
+// seen records the location at uri loaded from storage for key, unless it is already recorded, so that reloading it
+// does not count as a refresh; callers must hold the server lock
+func (s *locationSweeper) seen(uri, key string) {
+	if s == nil {
+		return
+	}
+	if _, ok := s.refreshed[uri]; !ok {
+		s.refreshed[uri] = refreshedKey{key: key, at: s.now()}
+	}
+}
+
+// forget drops the record of the location at uri once it is removed; callers must hold the server lock
+func (s *locationSweeper) forget(uri string) {
+	if s == nil {
+		return
+	}
+	delete(s.refreshed, uri)
+}
+
+// interval is how often to sweep, often enough that a location is removed soon after its TTL passes
+func (s *locationSweeper) interval() time.Duration {
+	return max(s.ttl/4, minSweepInterval)
+}
+
+// errSweepSkipped is returned by the removal of a stale key whose location was refreshed since it was found stale
+var errSweepSkipped = errors.New("the location was refreshed since it was found stale")
+
+// sweepStale removes the keys of the locations not refreshed within the TTL, as a removal of the key would, returning
+// the number of keys removed
+func (i *ImportLocationServer) sweepStale() int {
+	stale, cutoff := i.staleKeys()
+	swept := 0
+	for key := range stale {
+		if i.sweepKey(key, cutoff) {
+			swept++
+		}
+	}
+	return swept
+}
+
+// staleKeys returns the keys of the locations with content not refreshed since the cutoff, the TTL before now
+func (i *ImportLocationServer) staleKeys() (map[string]bool, time.Time) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	cutoff := i.sweeper.now().Add(-i.sweeper.ttl)
+	stale := map[string]bool{}
+	for uri, r := range i.sweeper.refreshed {
+		if il, ok := i.content[uri]; ok && il.content != nil && r.at.Before(cutoff) {
+			stale[r.key] = true
+		}
+	}
+	return stale, cutoff
+}
+
+// sweepKey removes a key found stale, unless one of its locations was refreshed since the cutoff, as by an upsert
+// racing with the sweep, or none is left to remove; the check is made under the lock the removal holds.  It returns
+// whether the key was removed.
+func (i *ImportLocationServer) sweepKey(key string, cutoff time.Time) bool {
+	_, segs, ok := i.splitStorageKey(key)
+	if !ok {
+		return false
+	}
+	v, err, _ := i.deletes.Do(key, func() (interface{}, error) {
+		return i.removeKeyUnless(key, segs, func(uris []string) bool {
+			stale := false
+			for _, uri := range uris {
+				r, ok := i.sweeper.refreshed[uri]
+				if !ok {
+					continue
+				}
+				if !r.at.Before(cutoff) {
+					return true
+				}
+				stale = stale || (i.content[uri] != nil && i.content[uri].content != nil)
+			}
+			return !stale
+		})
+	})
+	switch {
+	case errors.Is(err, errSweepSkipped):
+		klog.Infof("kept key %s, whose location was refreshed since it was found stale", key)
+		return false
+	case err != nil:
+		klog.Errorf("sweeping the stale key %s failed with status %d: %s", key, v.(int), err.Error())
+		return false
+	}
+	klog.Infof("swept key %s, whose location was not refreshed within %s", key, i.sweeper.ttl.String())
+	return true
+}
+
+// sweepStaleLocations sweeps stale locations until stopCh is closed; without a sweeper it returns at once
+func (i *ImportLocationServer) sweepStaleLocations(stopCh <-chan struct{}) {
+	if i.sweeper == nil {
+		return
+	}
+	ticker := time.NewTicker(i.sweeper.interval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			i.sweepStale()
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+)
+
+func TestSweepStale(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sweeper := newLocationSweeper(time.Hour)
+	sweeper.now = func() time.Time { return now }
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"granite_v1": []byte(graniteEntity)})
+	ils := &ImportLocationServer{
+		content:           map[string]*ImportLocation{},
+		modelcards:        map[string]modelCardMetadata{},
+		storage:           st,
+		deleter:           st,
+		storageDeleteMode: storageDeleteBestEffort,
+		events:            newEventBroker(0),
+		sweeper:           sweeper,
+	}
+	events, _ := ils.events.subscribe()
+	upsert := func(key, body string) {
+		sc, err := ils.upsertKey(key, "", false, rest.PostBody{Body: []byte(body)})
+		common.AssertError(t, err)
+		common.AssertEqual(t, http.StatusCreated, sc)
+	}
+
+	// granite is loaded from storage and never upserted
+	_, err := ils.loadFromStorage()
+	common.AssertError(t, err)
+	upsert("mnist_v1", mnistEntity)
+	upsert("fraud_v1", relatedEntity)
+	now = now.Add(40 * time.Minute)
+	// re-posting unchanged content refreshes the location
+	upsert("mnist_v1", mnistEntity)
+	for len(events) > 0 {
+		<-events
+	}
+
+	// nothing is stale within the TTL
+	now = now.Add(10 * time.Minute)
+	common.AssertEqual(t, 0, ils.sweepStale())
+
+	now = now.Add(20 * time.Minute)
+	common.AssertEqual(t, 2, ils.sweepStale())
+	common.AssertEqual(t, mnistEntity, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+	common.AssertEqual(t, true, ils.content["/fraud/v1/catalog-info.yaml"].content == nil)
+	common.AssertEqual(t, true, ils.content["/granite/v1/catalog-info.yaml"].content == nil)
+	common.AssertEqual(t, 1, st.DeleteCount("fraud_v1"))
+	common.AssertEqual(t, 1, st.DeleteCount("granite_v1"))
+	removed := map[string]string{}
+	for len(events) > 0 {
+		e := <-events
+		removed[e.URI] = e.Type
+	}
+	common.AssertEqual(t, map[string]string{"/fraud/v1/catalog-info.yaml": eventRemove, "/granite/v1/catalog-info.yaml": eventRemove}, removed)
+
+	// swept locations are not swept again, and the refreshed one is once it goes stale in turn
+	common.AssertEqual(t, 0, ils.sweepStale())
+	now = now.Add(time.Hour)
+	common.AssertEqual(t, 1, ils.sweepStale())
+	common.AssertEqual(t, true, ils.content["/mnist/v1/catalog-info.yaml"].content == nil)
+}
+
+// TestSweepStaleRefreshedSinceFound upserts a stale key between it being found stale and swept, asserting the sweep
+// keeps it rather than removing it from memory and storage
+func TestSweepStaleRefreshedSinceFound(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sweeper := newLocationSweeper(time.Hour)
+	sweeper.now = func() time.Time { return now }
+	st := stubstorage.NewStubStorageClient(map[string][]byte{})
+	ils := &ImportLocationServer{
+		content:           map[string]*ImportLocation{},
+		modelcards:        map[string]modelCardMetadata{},
+		storage:           st,
+		deleter:           st,
+		storageDeleteMode: storageDeleteBestEffort,
+		sweeper:           sweeper,
+	}
+	upsert := func(key, body string) {
+		_, err := ils.upsertKey(key, "", false, rest.PostBody{Body: []byte(body)})
+		common.AssertError(t, err)
+	}
+	upsert("mnist_v1", mnistEntity)
+	upsert("fraud_v1", relatedEntity)
+	now = now.Add(2 * time.Hour)
+
+	stale, cutoff := ils.staleKeys()
+	common.AssertEqual(t, map[string]bool{"mnist_v1": true, "fraud_v1": true}, stale)
+	// mnist is re-posted after the sweep found it stale
+	upsert("mnist_v1", mnistUpdatedEntity)
+
+	common.AssertEqual(t, false, ils.sweepKey("mnist_v1", cutoff))
+	common.AssertEqual(t, mnistUpdatedEntity, string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+	common.AssertEqual(t, 0, st.DeleteCount("mnist_v1"))
+	common.AssertEqual(t, true, ils.sweepKey("fraud_v1", cutoff))
+	common.AssertEqual(t, true, ils.content["/fraud/v1/catalog-info.yaml"].content == nil)
+	common.AssertEqual(t, 1, st.DeleteCount("fraud_v1"))
+	// a key already removed is not removed again
+	common.AssertEqual(t, false, ils.sweepKey("fraud_v1", cutoff))
+	common.AssertEqual(t, 1, st.DeleteCount("fraud_v1"))
+}
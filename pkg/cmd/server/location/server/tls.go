@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"k8s.io/klog/v2"
+)
+
+// certReloader holds the TLS certificate the location service serves with, reloading it from certFile and keyFile
+// on SIGHUP so certificates can be rotated without downtime; connections already established keep the certificate
+// they were handshaken with, and new ones get the reloaded one
+type certReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+}
+
+// newCertReloader creates the reloader, failing if the certificate cannot be initially loaded
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the certificate from disk, keeping the current one if it cannot be loaded
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate %s and key %s failed: %s", r.certFile, r.keyFile, err.Error())
+	}
+	r.cert.Store(&cert)
+	return nil
+}
+
+// getCertificate is the tls.Config callback serving the current certificate to each new connection
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// tlsConfig is the server TLS configuration serving the current certificate
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{GetCertificate: r.getCertificate, MinVersion: tls.VersionTLS12}
+}
+
+// reloadOn reloads the certificate on each signal received until stopCh is closed
+func (r *certReloader) reloadOn(signals <-chan os.Signal, stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-signals:
+			if err := r.reload(); err != nil {
+				klog.Errorf("%s, continuing to serve the current certificate", err.Error())
+				continue
+			}
+			klog.Infof("reloaded TLS certificate %s", r.certFile)
+		}
+	}
+}
+
+// watchSIGHUP reloads the certificate whenever the process receives a SIGHUP, until stopCh is closed
+func (r *certReloader) watchSIGHUP(stopCh <-chan struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signals)
+		r.reloadOn(signals, stopCh)
+	}()
+}
+
+// listenAndServe serves the router on the configured port, over TLS with the reloaded certificate when one is
+// configured
+func (i *ImportLocationServer) listenAndServe() error {
+	if i.certs == nil {
+		return i.router.Run(fmt.Sprintf(":%s", i.port))
+	}
+	srv := &http.Server{Addr: fmt.Sprintf(":%s", i.port), Handler: i.router, TLSConfig: i.certs.tlsConfig()}
+	return srv.ListenAndServeTLS("", "")
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+// writeTestCert writes a self-signed certificate with the given serial number, and its key, to certFile and keyFile
+func writeTestCert(t *testing.T, certFile, keyFile string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	common.AssertError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "location"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	common.AssertError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	common.AssertError(t, err)
+	common.AssertError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	common.AssertError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
+
+// servedSerial returns the serial number of the certificate a new connection to addr is served
+func servedSerial(t *testing.T, addr string) int64 {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	common.AssertError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestCertReloaderSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeTestCert(t, certFile, keyFile, 1)
+
+	r, err := newCertReloader(certFile, keyFile)
+	common.AssertError(t, err)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", r.tlsConfig())
+	common.AssertError(t, err)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	signals := make(chan os.Signal)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go r.reloadOn(signals, stopCh)
+
+	addr := l.Addr().String()
+	common.AssertEqual(t, int64(1), servedSerial(t, addr))
+	established, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	common.AssertError(t, err)
+	defer established.Close()
+	common.AssertError(t, established.Handshake())
+
+	// new connections use the swapped in certificate once reloaded, while established ones are kept
+	writeTestCert(t, certFile, keyFile, 2)
+	common.AssertEqual(t, int64(1), servedSerial(t, addr))
+	signals <- syscall.SIGHUP
+	// a second send on the unbuffered channel returns once the first reload has completed
+	signals <- syscall.SIGHUP
+	common.AssertEqual(t, int64(2), servedSerial(t, addr))
+	common.AssertEqual(t, int64(1), established.ConnectionState().PeerCertificates[0].SerialNumber.Int64())
+	_, err = established.Write([]byte("GET / HTTP/1.1\r\nHost: location\r\n\r\n"))
+	common.AssertError(t, err)
+
+	// a failed reload keeps serving the current certificate
+	common.AssertError(t, os.WriteFile(keyFile, []byte("not a key"), 0600))
+	signals <- syscall.SIGHUP
+	signals <- syscall.SIGHUP
+	common.AssertEqual(t, int64(2), servedSerial(t, addr))
+}
+
+func TestNewCertReloaderMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	_, err := newCertReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+	common.AssertEqual(t, true, err != nil)
+}
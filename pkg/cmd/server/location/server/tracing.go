@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+const tracerName = "github.com/redhat-ai-dev/model-catalog-bridge/pkg/cmd/server/location/server"
+
+// newTracerProvider creates a tracer provider batching spans to an OTLP/HTTP exporter, which is configured by the
+// standard OTEL_EXPORTER_OTLP_* env vars, and registers it globally for the tracing middleware; the service name and
+// resource attributes are read from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES
+func newTracerProvider(ctx context.Context) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(tp)
+	return tp, nil
+}
+
+// shutdownTracing flushes the spans not yet exported on shutdown; without a tracer provider there are none
+func shutdownTracing(tp *sdktrace.TracerProvider) {
+	if tp == nil {
+		return
+	}
+	if err := tp.Shutdown(context.Background()); err != nil {
+		klog.Errorf("flushing spans on shutdown failed: %s", err.Error())
+	}
+}
+
+// tracing starts a span for each request, continuing any trace propagated by the W3C traceparent header, with the
+// globally registered tracer provider; the span context is carried in the request context, so the request metrics
+// can attach its trace ID as an exemplar
+func tracing() gin.HandlerFunc {
+	propagator := propagation.TraceContext{}
+	tracer := otel.Tracer(tracerName)
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath()
+		if len(route) == 0 {
+			route = "unmatched"
+		}
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// traceExemplar returns the trace ID of the span of the request as exemplar labels, or nil when the request is
+// not traced
+func traceExemplar(c *gin.Context) prometheus.Labels {
+	sc := trace.SpanContextFromContext(c.Request.Context())
+	if !sc.HasTraceID() {
+		return nil
+	}
+	return prometheus.Labels{"trace_id": sc.TraceID().String()}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	"go.opentelemetry.io/otel"
+)
+
+func TestTracingExportsSpans(t *testing.T) {
+	exported := atomic.Int32{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exported.Add(1)
+		}
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL)
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	tp, err := newTracerProvider(context.Background())
+	common.AssertError(t, err)
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.Use(tracing())
+	r.GET("/:model/:version/:format", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mnist/v1/catalog-info.yaml", nil))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+
+	// shutting down flushes the batched span to the collector
+	shutdownTracing(tp)
+	common.AssertEqual(t, int32(1), exported.Load())
+}
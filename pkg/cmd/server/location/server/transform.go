@@ -0,0 +1,169 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// IngestTransformer rewrites catalog-info content as it is upserted, before it is stored, so the stored content,
+// and anything derived from it, reflects the transformation; this differs from serve time rewriting of content,
+// which leaves what is stored intact
+type IngestTransformer interface {
+	Transform(key string, content []byte) ([]byte, error)
+}
+
+// IngestTransformerFunc allows a plain function to be used as an IngestTransformer
+type IngestTransformerFunc func(key string, content []byte) ([]byte, error)
+
+func (f IngestTransformerFunc) Transform(key string, content []byte) ([]byte, error) {
+	return f(key, content)
+}
+
+// ingestTransformerChain applies its transformers in order, feeding the output of one into the next; an empty
+// chain is the identity transformation
+type ingestTransformerChain []IngestTransformer
+
+func (ch ingestTransformerChain) Transform(key string, content []byte) ([]byte, error) {
+	var err error
+	for idx, t := range ch {
+		content, err = t.Transform(key, content)
+		if err != nil {
+			return nil, fmt.Errorf("ingest transformer %d failed for key %s: %s", idx, key, err.Error())
+		}
+	}
+	return content, nil
+}
+
+// AddIngestTransformer appends a transformer to the chain applied to upserted content; it should be called before Run
+func (i *ImportLocationServer) AddIngestTransformer(t IngestTransformer) {
+	i.ingestTransformers = append(i.ingestTransformers, t)
+}
+
+// annotationTransformer adds annotations to every Backstage entity in the content which does not already
+// define them; content without Backstage entities is left untouched
+type annotationTransformer struct {
+	annotations map[string]string
+}
+
+func (a *annotationTransformer) Transform(key string, content []byte) ([]byte, error) {
+	entities, err := parseEntities(content)
+	if err != nil {
+		// not content we can annotate
+		return content, nil
+	}
+	changed := false
+	for _, entity := range entities {
+		if _, ok := entityRef(entity); !ok {
+			continue
+		}
+		annotations := entityAnnotations(entity)
+		for k, v := range a.annotations {
+			if _, ok := annotations[k]; !ok {
+				annotations[k] = v
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return content, nil
+	}
+	return marshalEntities(entities, isJSON(content))
+}
+
+// specDefaultsTransformer sets the owner and system of Backstage entities that lack them, as Backstage rejects
+// entities without an owner; only the kinds whose spec has the field are given it, and present values are kept
+type specDefaultsTransformer struct {
+	owner  string
+	system string
+}
+
+var (
+	ownedKinds  = map[string]bool{"component": true, "api": true, "resource": true, "system": true, "domain": true}
+	systemKinds = map[string]bool{"component": true, "api": true, "resource": true}
+)
+
+func (d *specDefaultsTransformer) Transform(key string, content []byte) ([]byte, error) {
+	entities, err := parseEntities(content)
+	if err != nil {
+		// not content we can set defaults for
+		return content, nil
+	}
+	changed := false
+	for _, entity := range entities {
+		if _, ok := entityRef(entity); !ok {
+			continue
+		}
+		kind, _ := entity["kind"].(string)
+		kind = strings.ToLower(kind)
+		spec, ok := entity["spec"].(map[string]interface{})
+		if !ok {
+			spec = map[string]interface{}{}
+		}
+		for field, def := range map[string]string{"owner": d.owner, "system": d.system} {
+			if len(def) == 0 || (field == "owner" && !ownedKinds[kind]) || (field == "system" && !systemKinds[kind]) {
+				continue
+			}
+			if v, ok := spec[field].(string); ok && len(v) > 0 {
+				continue
+			}
+			spec[field] = def
+			entity["spec"] = spec
+			changed = true
+		}
+	}
+	if !changed {
+		return content, nil
+	}
+	return marshalEntities(entities, isJSON(content))
+}
+
+// ServeTransformer rewrites catalog-info content as it is served, leaving the stored content intact; its output
+// is cached with the location until the location's content changes, so it should depend only on the content
+type ServeTransformer interface {
+	Transform(uri string, content []byte) ([]byte, error)
+}
+
+// ServeTransformerFunc allows a plain function to be used as a ServeTransformer
+type ServeTransformerFunc func(uri string, content []byte) ([]byte, error)
+
+func (f ServeTransformerFunc) Transform(uri string, content []byte) ([]byte, error) {
+	return f(uri, content)
+}
+
+// serveTransformerChain applies its transformers in order, feeding the output of one into the next; an empty
+// chain is the identity transformation
+type serveTransformerChain []ServeTransformer
+
+func (ch serveTransformerChain) Transform(uri string, content []byte) ([]byte, error) {
+	var err error
+	for idx, t := range ch {
+		content, err = t.Transform(uri, content)
+		if err != nil {
+			return nil, fmt.Errorf("serve transformer %d failed for uri %s: %s", idx, uri, err.Error())
+		}
+	}
+	return content, nil
+}
+
+// AddServeTransformer appends a transformer to the chain applied to served content; it should be called before Run
+func (i *ImportLocationServer) AddServeTransformer(t ServeTransformer) {
+	i.serveTransformers = append(i.serveTransformers, t)
+}
+
+// downwardAPIAnnotations resolves the annotation to env var mapping, where the env vars are typically populated from
+// the Kubernetes downward API (i.e. the pod's namespace), into the annotation values; unset env vars are skipped
+func downwardAPIAnnotations(mapping map[string]string) map[string]string {
+	annotations := map[string]string{}
+	for annotation, envVar := range mapping {
+		v := strings.TrimSpace(os.Getenv(envVar))
+		if len(v) == 0 {
+			klog.Infof("env var %s for annotation %s is not set so the annotation will not be served", envVar, annotation)
+			continue
+		}
+		annotations[annotation] = v
+	}
+	return annotations
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestIngestTransformers(t *testing.T) {
+	ownerAnnotation := &annotationTransformer{annotations: map[string]string{"backstage.io/owner": "ai-team"}}
+	for _, tc := range []struct {
+		name            string
+		transformers    ingestTransformerChain
+		body            string
+		expectedSC      int
+		expectedContent string
+	}{
+		{
+			name:            "identity by default",
+			body:            mnistUpdatedEntity,
+			expectedSC:      http.StatusCreated,
+			expectedContent: mnistUpdatedEntity,
+		},
+		{
+			name:         "owner annotation injected into yaml",
+			transformers: ingestTransformerChain{ownerAnnotation},
+			body:         "kind: Component\nmetadata:\n  name: mnist\n",
+			expectedSC:   http.StatusCreated,
+			expectedContent: `kind: Component
+metadata:
+  annotations:
+    backstage.io/owner: ai-team
+  name: mnist
+`,
+		},
+		{
+			name:            "owner annotation injected into json",
+			transformers:    ingestTransformerChain{ownerAnnotation},
+			body:            `{"kind":"Component","metadata":{"name":"mnist"}}`,
+			expectedSC:      http.StatusCreated,
+			expectedContent: `{"kind":"Component","metadata":{"annotations":{"backstage.io/owner":"ai-team"},"name":"mnist"}}`,
+		},
+		{
+			name:            "owner annotation already present",
+			transformers:    ingestTransformerChain{ownerAnnotation},
+			body:            "kind: Component\nmetadata:\n  annotations:\n    backstage.io/owner: other-team\n  name: mnist\n",
+			expectedSC:      http.StatusCreated,
+			expectedContent: "kind: Component\nmetadata:\n  annotations:\n    backstage.io/owner: other-team\n  name: mnist\n",
+		},
+		{
+			name:            "non entity content untouched",
+			transformers:    ingestTransformerChain{ownerAnnotation},
+			body:            `{"models":[{"name":"mnist"}]}`,
+			expectedSC:      http.StatusCreated,
+			expectedContent: `{"models":[{"name":"mnist"}]}`,
+		},
+		{
+			name: "chained",
+			transformers: ingestTransformerChain{
+				ownerAnnotation,
+				IngestTransformerFunc(func(key string, content []byte) ([]byte, error) {
+					return append(content, []byte("# "+key+"\n")...), nil
+				}),
+			},
+			body:            "kind: Component\nmetadata:\n  name: mnist\n",
+			expectedSC:      http.StatusCreated,
+			expectedContent: "kind: Component\nmetadata:\n  annotations:\n    backstage.io/owner: ai-team\n  name: mnist\n# mnist_v1\n",
+		},
+		{
+			name: "failing transformer",
+			transformers: ingestTransformerChain{
+				IngestTransformerFunc(func(key string, content []byte) ([]byte, error) {
+					return nil, fmt.Errorf("bad content")
+				}),
+			},
+			body:       mnistEntity,
+			expectedSC: http.StatusBadRequest,
+		},
+	} {
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}}
+		for _, tr := range tc.transformers {
+			ils.AddIngestTransformer(tr)
+		}
+		testWriter := testgin.NewTestResponseWriter()
+		data, err := json.Marshal(rest.PostBody{Body: []byte(tc.body)})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=mnist_v1"}, Body: io.NopCloser(bytes.NewReader(data))}
+
+		ils.handleCatalogUpsertPost(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		il, ok := ils.content["/mnist/v1/catalog-info.yaml"]
+		common.AssertEqual(t, len(tc.expectedContent) > 0, ok)
+		if ok {
+			common.AssertEqual(t, tc.expectedContent, string(il.content))
+		}
+	}
+}
+
+func TestServeTransformers(t *testing.T) {
+	t.Setenv("TEST_POD_NAMESPACE", "ai-ns")
+	annotations := downwardAPIAnnotations(map[string]string{
+		"backstage.io/kubernetes-namespace": "TEST_POD_NAMESPACE",
+		"example.com/cluster":               "TEST_UNSET_CLUSTER_NAME",
+	})
+	common.AssertEqual(t, map[string]string{"backstage.io/kubernetes-namespace": "ai-ns"}, annotations)
+
+	for _, tc := range []struct {
+		name            string
+		transformers    bool
+		expectedContent string
+		expectedCalls   int
+	}{
+		{
+			name:            "disabled",
+			expectedContent: "kind: Component\nmetadata:\n  name: mnist\n",
+		},
+		{
+			name:            "annotations injected and cached",
+			transformers:    true,
+			expectedContent: "kind: Component\nmetadata:\n  annotations:\n    backstage.io/kubernetes-namespace: ai-ns\n  name: mnist\n",
+			expectedCalls:   1,
+		},
+	} {
+		calls := 0
+		ils := &ImportLocationServer{
+			content: map[string]*ImportLocation{
+				"/mnist/v1/catalog-info.yaml": {content: []byte("kind: Component\nmetadata:\n  name: mnist\n")},
+			},
+		}
+		if tc.transformers {
+			ils.AddServeTransformer(ServeTransformerFunc(func(uri string, content []byte) ([]byte, error) {
+				calls++
+				return content, nil
+			}))
+			ils.AddServeTransformer(&annotationTransformer{annotations: annotations})
+		}
+		for range 2 {
+			testWriter := testgin.NewTestResponseWriter()
+			ctx, _ := gin.CreateTestContext(testWriter)
+			ctx.Request = &http.Request{URL: &url.URL{}}
+			ctx.Params = gin.Params{{Key: "model", Value: "mnist"}, {Key: "version", Value: "v1"}, {Key: "format", Value: "catalog-info.yaml"}}
+
+			ils.handleCatalogLookupGet(ctx)
+
+			common.AssertEqual(t, http.StatusOK, ctx.Writer.Status())
+			common.AssertEqual(t, tc.expectedContent, testWriter.ResponseWriter.Body.String())
+		}
+		common.AssertEqual(t, tc.expectedCalls, calls)
+		// the stored content is left untouched
+		common.AssertEqual(t, "kind: Component\nmetadata:\n  name: mnist\n", string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+	}
+}
+
+func TestSpecDefaultsTransformer(t *testing.T) {
+	defaults := &specDefaultsTransformer{owner: "ai-team", system: "ai-platform"}
+	for _, tc := range []struct {
+		name            string
+		content         string
+		expectedContent string
+	}{
+		{
+			name:            "owner and system injected when absent",
+			content:         "kind: Component\nmetadata:\n  name: mnist\n",
+			expectedContent: "kind: Component\nmetadata:\n  name: mnist\nspec:\n  owner: ai-team\n  system: ai-platform\n",
+		},
+		{
+			name:            "only the absent field injected",
+			content:         "kind: Component\nmetadata:\n  name: mnist\nspec:\n  owner: rhdh-rhoai-bridge\n",
+			expectedContent: "kind: Component\nmetadata:\n  name: mnist\nspec:\n  owner: rhdh-rhoai-bridge\n  system: ai-platform\n",
+		},
+		{
+			name:            "present values untouched",
+			content:         "kind: Component\nmetadata:\n  name: mnist\nspec:\n  owner: rhdh-rhoai-bridge\n  system: mnist\n",
+			expectedContent: "kind: Component\nmetadata:\n  name: mnist\nspec:\n  owner: rhdh-rhoai-bridge\n  system: mnist\n",
+		},
+		{
+			name:            "kinds without a system only get an owner",
+			content:         `{"kind":"System","metadata":{"name":"mnist"}}`,
+			expectedContent: `{"kind":"System","metadata":{"name":"mnist"},"spec":{"owner":"ai-team"}}`,
+		},
+		{
+			name:            "non entity content untouched",
+			content:         `{"models":[{"name":"mnist"}]}`,
+			expectedContent: `{"models":[{"name":"mnist"}]}`,
+		},
+	} {
+		content, err := defaults.Transform("/mnist/v1/catalog-info.yaml", []byte(tc.content))
+		common.AssertError(t, err)
+		common.AssertEqual(t, tc.expectedContent, string(content))
+	}
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+)
+
+const (
+	modelPlaceholder   = "{model}"
+	versionPlaceholder = "{version}"
+	filePlaceholder    = "{file}"
+
+	// defaultURITemplate is the template URIs are built with when none is configured
+	defaultURITemplate = "/" + modelPlaceholder + "/" + versionPlaceholder + "/" + filePlaceholder
+)
+
+// uriTemplate builds the URI of a location from its model, version and file name, i.e.
+// /models/{model}/versions/{version}/{file}; each placeholder must be a whole path segment, so that the model,
+// version and file name of a URI, and so its key, can be recovered from the URI
+type uriTemplate struct {
+	raw  string
+	segs []string
+}
+
+func parseURITemplate(raw string) (*uriTemplate, error) {
+	if !strings.HasPrefix(raw, "/") {
+		return nil, fmt.Errorf("URI template %s must start with '/'", raw)
+	}
+	t := &uriTemplate{raw: raw, segs: strings.Split(strings.TrimPrefix(raw, "/"), "/")}
+	found := map[string]int{}
+	for _, seg := range t.segs {
+		switch {
+		case len(seg) == 0:
+			return nil, fmt.Errorf("URI template %s has an empty path segment", raw)
+		case seg == modelPlaceholder || seg == versionPlaceholder || seg == filePlaceholder:
+			found[seg]++
+		case strings.ContainsAny(seg, "{}:*"):
+			return nil, fmt.Errorf("URI template %s segment %s must be a literal or one of %s, %s and %s", raw, seg, modelPlaceholder, versionPlaceholder, filePlaceholder)
+		}
+	}
+	for _, p := range []string{modelPlaceholder, versionPlaceholder, filePlaceholder} {
+		if found[p] != 1 {
+			return nil, fmt.Errorf("URI template %s must contain %s exactly once", raw, p)
+		}
+	}
+	return t, nil
+}
+
+func (t *uriTemplate) String() string {
+	return t.raw
+}
+
+func (t *uriTemplate) build(model, version, file string) string {
+	r := strings.NewReplacer(modelPlaceholder, model, versionPlaceholder, version, filePlaceholder, file)
+	return r.Replace(t.raw)
+}
+
+// parse recovers the model, version and file name from a URI built by the template
+func (t *uriTemplate) parse(uri string) (string, string, string, bool) {
+	segs := strings.Split(strings.TrimPrefix(uri, "/"), "/")
+	if !strings.HasPrefix(uri, "/") || len(segs) != len(t.segs) {
+		return "", "", "", false
+	}
+	var model, version, file string
+	for idx, seg := range t.segs {
+		switch seg {
+		case modelPlaceholder:
+			model = segs[idx]
+		case versionPlaceholder:
+			version = segs[idx]
+		case filePlaceholder:
+			file = segs[idx]
+		default:
+			if segs[idx] != seg {
+				return "", "", "", false
+			}
+		}
+	}
+	return model, version, file, len(model) > 0 && len(version) > 0 && len(file) > 0
+}
+
+// checkURILength returns an error when the URI built for key is longer than the maximum URI length, if set
+func (i *ImportLocationServer) checkURILength(key, uri string) error {
+	if i.maxURILength <= 0 || len(uri) <= i.maxURILength {
+		return nil
+	}
+	return fmt.Errorf("the URI %s for key %s is %d characters long, more than the maximum of %d", uri, key, len(uri), i.maxURILength)
+}
+
+// buildKeyAndURI is util.BuildImportKeyAndURI under the configured URI template, if any
+func (i *ImportLocationServer) buildKeyAndURI(seg1, seg2 string, format types.NormalizerFormat) (string, string) {
+	key, uri := util.BuildImportKeyAndURI(seg1, seg2, format)
+	t := i.uriTemplate.Load()
+	if t == nil {
+		return key, uri
+	}
+	return key, t.build(strings.ReplaceAll(seg1, " ", ""), strings.ReplaceAll(seg2, " ", ""), path.Base(uri))
+}
+
+// handleTemplatedLookupGet serves the lookups of URIs whose template does not match the /:model/:version/:format
+// route; any other unmatched route is not found
+func (i *ImportLocationServer) handleTemplatedLookupGet(c *gin.Context) {
+	t := i.uriTemplate.Load()
+	if t == nil || c.Request.Method != http.MethodGet {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if _, _, _, ok := t.parse(c.Request.URL.Path); !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	i.handleCatalogLookupGet(c)
+}
+
+type ReindexRequest struct {
+	Template string `json:"template"`
+}
+
+type ReindexResponse struct {
+	Template string `json:"template"`
+	Uris     int    `json:"uris"`
+}
+
+// handleReindexPost switches to a new URI template, re-deriving the URI of every location from the model and
+// version recovered from its current URI, and swapping the re-keyed content in along with the template
+func (i *ImportLocationServer) handleReindexPost(c *gin.Context) {
+	req := ReindexRequest{}
+	err := c.BindJSON(&req)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+	next, err := parseURITemplate(req.Template)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		c.Error(err)
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.reloading != nil {
+		c.Status(http.StatusConflict)
+		c.Error(fmt.Errorf("a reload from storage is in progress, retry the reindex"))
+		return
+	}
+	current := i.uriTemplate.Load()
+	if current == nil {
+		current, _ = parseURITemplate(defaultURITemplate)
+	}
+	content := make(map[string]*ImportLocation, len(i.content))
+	updated := map[string]time.Time{}
+	validated := map[string]time.Time{}
+	for uri, il := range i.content {
+		model, version, file, ok := current.parse(uri)
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			c.Error(fmt.Errorf("URI %s does not match the URI template %s", uri, current.String()))
+			return
+		}
+		reindexed := next.build(model, version, file)
+		content[reindexed] = il
+		if t, ok := i.updated[uri]; ok {
+			updated[reindexed] = t
+		}
+		if t, ok := i.validated[uri]; ok {
+			validated[reindexed] = t
+		}
+	}
+	i.content = content
+	i.updated = updated
+	i.validated = validated
+	if i.entityUniqueness {
+		i.entityRefs = map[string]string{}
+		for uri, il := range i.content {
+			i.indexEntityRefs(uri, nil, il)
+		}
+	}
+	i.uriTemplate.Store(next)
+	i.reindexVersions()
+	klog.Infof("reindexed %d URIs from template %s to %s", len(content), current.String(), next.String())
+
+	buf, err := json.Marshal(&ReindexResponse{Template: next.String(), Uris: len(content)})
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "Content-Type: application/json", buf)
+}
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/util"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	stubstorage "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/storage"
+)
+
+func TestParseURITemplate(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		raw         string
+		expectedErr string
+		model       string
+		version     string
+		expectedURI string
+	}{
+		{
+			name:        "default",
+			raw:         defaultURITemplate,
+			model:       "mnist",
+			version:     "v1",
+			expectedURI: "/mnist/v1/catalog-info.yaml",
+		},
+		{
+			name:        "literal segments",
+			raw:         "/models/{model}/versions/{version}/{file}",
+			model:       "mnist",
+			version:     "v1",
+			expectedURI: "/models/mnist/versions/v1/catalog-info.yaml",
+		},
+		{
+			name:        "reordered",
+			raw:         "/{version}/{model}/{file}",
+			model:       "mnist",
+			version:     "v1",
+			expectedURI: "/v1/mnist/catalog-info.yaml",
+		},
+		{
+			name:        "relative",
+			raw:         "{model}/{version}/{file}",
+			expectedErr: "must start with '/'",
+		},
+		{
+			name:        "missing placeholder",
+			raw:         "/{model}/{file}",
+			expectedErr: "must contain {version} exactly once",
+		},
+		{
+			name:        "repeated placeholder",
+			raw:         "/{model}/{model}/{version}/{file}",
+			expectedErr: "must contain {model} exactly once",
+		},
+		{
+			name:        "placeholder within a segment",
+			raw:         "/model-{model}/{version}/{file}",
+			expectedErr: "must be a literal or one of",
+		},
+		{
+			name:        "unknown placeholder",
+			raw:         "/{namespace}/{model}/{version}/{file}",
+			expectedErr: "must be a literal or one of",
+		},
+		{
+			name:        "empty segment",
+			raw:         "/{model}//{version}/{file}",
+			expectedErr: "has an empty path segment",
+		},
+	} {
+		tmpl, err := parseURITemplate(tc.raw)
+		if len(tc.expectedErr) > 0 {
+			common.AssertEqual(t, true, err != nil && strings.Contains(err.Error(), tc.expectedErr))
+			continue
+		}
+		common.AssertError(t, err)
+		uri := tmpl.build(tc.model, tc.version, "catalog-info.yaml")
+		common.AssertEqual(t, tc.expectedURI, uri)
+		model, version, file, ok := tmpl.parse(uri)
+		common.AssertEqual(t, true, ok)
+		common.AssertEqual(t, []string{tc.model, tc.version, "catalog-info.yaml"}, []string{model, version, file})
+	}
+}
+
+func TestHandleReindexPost(t *testing.T) {
+	ils := &ImportLocationServer{
+		content: map[string]*ImportLocation{
+			"/mnist/v1/catalog-info.yaml":   {content: []byte(mnistEntity)},
+			"/granite/v2/catalog-info.yaml": {content: []byte(graniteEntity)},
+		},
+		modelcards: map[string]modelCardMetadata{},
+		format:     types.CatalogInfoYamlFormat,
+		adminToken: "secret",
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.GET("/:model/:version/:format", ils.handleCatalogLookupGet)
+	r.POST(util.ConfigReindexURI, adminAuth(ils.adminToken), ils.handleReindexPost)
+	r.NoRoute(ils.handleTemplatedLookupGet)
+
+	for _, tc := range []struct {
+		name         string
+		token        string
+		body         string
+		expectedSC   int
+		expectedURIs []string
+		servedURI    string
+		notFoundURI  string
+	}{
+		{
+			name:         "no token",
+			body:         `{"template":"/models/{model}/versions/{version}/{file}"}`,
+			expectedSC:   http.StatusUnauthorized,
+			expectedURIs: []string{"/granite/v2/catalog-info.yaml", "/mnist/v1/catalog-info.yaml"},
+			servedURI:    "/mnist/v1/catalog-info.yaml",
+		},
+		{
+			name:         "wrong token",
+			token:        "guess",
+			body:         `{"template":"/models/{model}/versions/{version}/{file}"}`,
+			expectedSC:   http.StatusUnauthorized,
+			expectedURIs: []string{"/granite/v2/catalog-info.yaml", "/mnist/v1/catalog-info.yaml"},
+			servedURI:    "/mnist/v1/catalog-info.yaml",
+		},
+		{
+			name:         "invalid template leaves content alone",
+			token:        "secret",
+			body:         `{"template":"/models/{model}/{file}"}`,
+			expectedSC:   http.StatusBadRequest,
+			expectedURIs: []string{"/granite/v2/catalog-info.yaml", "/mnist/v1/catalog-info.yaml"},
+			servedURI:    "/mnist/v1/catalog-info.yaml",
+		},
+		{
+			name:         "switch to a longer template",
+			token:        "secret",
+			body:         `{"template":"/models/{model}/versions/{version}/{file}"}`,
+			expectedSC:   http.StatusOK,
+			expectedURIs: []string{"/models/granite/versions/v2/catalog-info.yaml", "/models/mnist/versions/v1/catalog-info.yaml"},
+			servedURI:    "/models/mnist/versions/v1/catalog-info.yaml",
+			notFoundURI:  "/mnist/v1/catalog-info.yaml",
+		},
+		{
+			name:         "switch back to three segments",
+			token:        "secret",
+			body:         `{"template":"/{version}/{model}/{file}"}`,
+			expectedSC:   http.StatusOK,
+			expectedURIs: []string{"/v1/mnist/catalog-info.yaml", "/v2/granite/catalog-info.yaml"},
+			servedURI:    "/v1/mnist/catalog-info.yaml",
+			notFoundURI:  "/models/mnist/versions/v1/catalog-info.yaml",
+		},
+	} {
+		req := httptest.NewRequest(http.MethodPost, util.ConfigReindexURI, bytes.NewBufferString(tc.body))
+		if len(tc.token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		common.AssertEqual(t, tc.expectedSC, rec.Code)
+
+		uris := []string{}
+		for uri := range ils.content {
+			uris = append(uris, uri)
+		}
+		common.AssertEqual(t, len(tc.expectedURIs), len(uris))
+		for _, uri := range tc.expectedURIs {
+			_, ok := ils.content[uri]
+			common.AssertEqual(t, true, ok)
+		}
+
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.servedURI, nil))
+		common.AssertEqual(t, http.StatusOK, rec.Code)
+		common.AssertEqual(t, mnistEntity, rec.Body.String())
+		if len(tc.notFoundURI) > 0 {
+			rec = httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.notFoundURI, nil))
+			common.AssertEqual(t, http.StatusNotFound, rec.Code)
+		}
+	}
+}
+
+func TestAdminAuthDisabled(t *testing.T) {
+	ils := &ImportLocationServer{content: map[string]*ImportLocation{}}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.POST(util.ConfigReindexURI, adminAuth(""), ils.handleReindexPost)
+	req := httptest.NewRequest(http.MethodPost, util.ConfigReindexURI, bytes.NewBufferString(`{"template":"/{model}/{version}/{file}"}`))
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	common.AssertEqual(t, http.StatusForbidden, rec.Code)
+}
+
+func TestMaxURILength(t *testing.T) {
+	// /mnist/v10/catalog-info.yaml is 28 characters long
+	for _, tc := range []struct {
+		name             string
+		key              string
+		expectedUpsertSC int
+		expectedLookupSC int
+	}{
+		{
+			name:             "just under the limit",
+			key:              "mnist_v1",
+			expectedUpsertSC: http.StatusCreated,
+			expectedLookupSC: http.StatusOK,
+		},
+		{
+			name:             "at the limit",
+			key:              "mnist_v10",
+			expectedUpsertSC: http.StatusCreated,
+			expectedLookupSC: http.StatusOK,
+		},
+		{
+			name:             "just over the limit",
+			key:              "mnist_v100",
+			expectedUpsertSC: http.StatusBadRequest,
+			expectedLookupSC: http.StatusRequestURITooLong,
+		},
+	} {
+		ils := &ImportLocationServer{content: map[string]*ImportLocation{}, modelcards: map[string]modelCardMetadata{}, maxURILength: 28}
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		r.POST(util.UpsertURI, ils.handleCatalogUpsertPost)
+		r.GET("/:model/:version/:format", ils.handleCatalogLookupGet)
+
+		req := httptest.NewRequest(http.MethodPost, util.UpsertURI+"?key="+tc.key, bytes.NewBufferString(`{"body":"Y3JlYXRl"}`))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		common.AssertEqual(t, tc.expectedUpsertSC, rec.Code)
+
+		model, version, _ := strings.Cut(tc.key, "_")
+		req = httptest.NewRequest(http.MethodGet, "/"+model+"/"+version+"/catalog-info.yaml", nil)
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if tc.expectedLookupSC == http.StatusOK {
+			common.AssertEqual(t, http.StatusOK, rec.Code)
+			common.AssertEqual(t, "create", rec.Body.String())
+			continue
+		}
+		common.AssertEqual(t, tc.expectedLookupSC, rec.Code)
+		common.AssertEqual(t, 0, len(ils.content))
+	}
+}
+
+func TestHandleReindexPostKeepsValidation(t *testing.T) {
+	validatedAt := time.Now()
+	st := stubstorage.NewStubStorageClient(map[string][]byte{"mnist_v1": []byte(mnistEntity)})
+	ils := &ImportLocationServer{
+		content:        map[string]*ImportLocation{"/mnist/v1/catalog-info.yaml": {content: []byte(mnistEntity)}},
+		modelcards:     map[string]modelCardMetadata{},
+		storage:        st,
+		format:         types.CatalogInfoYamlFormat,
+		locationMaxAge: time.Hour,
+		validated:      map[string]time.Time{"/mnist/v1/catalog-info.yaml": validatedAt},
+	}
+	r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+	r.POST(util.ConfigReindexURI, ils.handleReindexPost)
+	r.NoRoute(ils.handleTemplatedLookupGet)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, util.ConfigReindexURI, bytes.NewBufferString(`{"template":"/models/{model}/versions/{version}/{file}"}`)))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, map[string]time.Time{"/models/mnist/versions/v1/catalog-info.yaml": validatedAt}, ils.validated)
+
+	// the reindexed location is still fresh, so it is served without revalidating it with storage
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/models/mnist/versions/v1/catalog-info.yaml", nil))
+	common.AssertEqual(t, http.StatusOK, rec.Code)
+	common.AssertEqual(t, 0, st.FetchCount("mnist_v1"))
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// parseVersionPattern compiles the pattern the version segment of upserted keys must match in full
+func parseVersionPattern(raw string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + raw + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid version pattern %s: %s", raw, err.Error())
+	}
+	return re, nil
+}
+
+// checkVersion returns an error when a version pattern is set and the version of key does not match it
+func (i *ImportLocationServer) checkVersion(key, version string) error {
+	if i.versionPattern == nil || i.versionPattern.MatchString(version) {
+		return nil
+	}
+	return fmt.Errorf("the version %s of key %s does not match the version pattern %s", version, key, i.rawVersionPattern)
+}
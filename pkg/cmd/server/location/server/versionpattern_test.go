@@ -0,0 +1,53 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestUpsertVersionPattern(t *testing.T) {
+	semver, err := parseVersionPattern(`v?\d+\.\d+\.\d+`)
+	common.AssertError(t, err)
+	_, err = parseVersionPattern(`v(\d+`)
+	common.AssertEqual(t, true, err != nil)
+
+	for _, tc := range []struct {
+		name       string
+		pattern    bool
+		key        string
+		expectedSC int
+	}{
+		{name: "no validation by default", key: "mnist_latest", expectedSC: http.StatusCreated},
+		{name: "semver", pattern: true, key: "mnist_1.2.3", expectedSC: http.StatusCreated},
+		{name: "prefixed semver", pattern: true, key: "mnist_v1.2.3", expectedSC: http.StatusCreated},
+		{name: "tag", pattern: true, key: "mnist_latest", expectedSC: http.StatusBadRequest},
+		{name: "partial version", pattern: true, key: "mnist_1.0", expectedSC: http.StatusBadRequest},
+		{name: "match must be in full", pattern: true, key: "mnist_1.2.3-rc", expectedSC: http.StatusBadRequest},
+	} {
+		ils := &ImportLocationServer{
+			content:    map[string]*ImportLocation{},
+			modelcards: map[string]modelCardMetadata{},
+		}
+		if tc.pattern {
+			ils.versionPattern = semver
+		}
+		data, err := json.Marshal(rest.PostBody{Body: []byte("kind: Component\n")})
+		common.AssertError(t, err)
+		ctx, _ := gin.CreateTestContext(testgin.NewTestResponseWriter())
+		ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + tc.key}, Body: io.NopCloser(bytes.NewReader(data))}
+		ils.handleCatalogUpsertPost(ctx)
+		if ctx.Writer.Status() != tc.expectedSC {
+			t.Errorf("%s: expected status %d but got %d", tc.name, tc.expectedSC, ctx.Writer.Status())
+		}
+		common.AssertEqual(t, tc.expectedSC == http.StatusCreated, len(ils.content) == 1)
+	}
+}
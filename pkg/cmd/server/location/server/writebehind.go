@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"k8s.io/klog/v2"
+)
+
+// storageWriter persists upserts to storage, as the bridge storage REST client does
+type storageWriter interface {
+	UpsertModel(importKey, normalizerType, lastUpdateTimeSinceEpoch, modelCardKey string, modelCard *string, buf []byte) (int, string, *rest.PostBody, error)
+}
+
+type pendingWrite struct {
+	source string
+	body   rest.PostBody
+}
+
+// writeBehind buffers upserts bound for storage, flushing them together once the window since the first buffered
+// upsert elapses; repeated upserts of a key within a window are coalesced into a write of the latest.  Content is
+// served from memory regardless, so reads never wait on the buffer.
+type writeBehind struct {
+	lock    sync.Mutex
+	window  time.Duration
+	writer  storageWriter
+	pending map[string]pendingWrite
+	timer   *time.Timer
+	closed  bool
+	// flushing serializes flushes, so close waits on a flush the timer already started
+	flushing sync.Mutex
+}
+
+func newWriteBehind(writer storageWriter, window time.Duration) *writeBehind {
+	return &writeBehind{writer: writer, window: window, pending: map[string]pendingWrite{}}
+}
+
+// enqueue buffers the upsert of a key; a nil write behind buffer discards it
+func (w *writeBehind) enqueue(key, source string, body rest.PostBody) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed {
+		klog.Errorf("storage write of %s after shutdown is dropped", key)
+		return
+	}
+	w.pending[key] = pendingWrite{source: source, body: body}
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.window, w.flush)
+	}
+}
+
+// cancel drops the buffered upsert of a key, returning it if there was one; a nil write behind buffer has none
+func (w *writeBehind) cancel(key string) (pendingWrite, bool) {
+	if w == nil {
+		return pendingWrite{}, false
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	p, ok := w.pending[key]
+	delete(w.pending, key)
+	return p, ok
+}
+
+// flush writes the buffered upserts to storage; failed writes are logged, as storage is reconciled from the
+// normalizers regardless
+func (w *writeBehind) flush() {
+	w.flushing.Lock()
+	defer w.flushing.Unlock()
+	w.lock.Lock()
+	pending := w.pending
+	w.pending = map[string]pendingWrite{}
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.lock.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	klog.Infof("flushing %d buffered upserts to storage", len(pending))
+	for key, p := range pending {
+		var modelCard *string
+		if len(p.body.ModelCardKey) > 0 {
+			modelCard = &p.body.ModelCard
+		}
+		rc, msg, _, err := w.writer.UpsertModel(key, p.source, p.body.LastUpdateTimeSinceEpoch, p.body.ModelCardKey, modelCard, p.body.Body)
+		if err == nil && rc != http.StatusOK && rc != http.StatusCreated {
+			err = fmt.Errorf("bad response code %d: %s", rc, msg)
+		}
+		if err != nil {
+			klog.Errorf("buffered upsert of %s to storage failed: %s", key, err.Error())
+		}
+	}
+}
+
+// close flushes any buffered upserts, so they are not lost on shutdown, and drops any upserts after; it returns once
+// every buffered upsert, including those of a flush already in progress, has been written
+func (w *writeBehind) close() {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.lock.Unlock()
+	w.flush()
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+	testgin "github.com/redhat-ai-dev/model-catalog-bridge/test/stub/gin-gonic"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+type recordingWriter struct {
+	lock   sync.Mutex
+	writes map[string]string
+	// cards, when set, records the model cards written
+	cards map[string]string
+	calls int
+}
+
+func (r *recordingWriter) UpsertModel(importKey, normalizerType, lastUpdateTimeSinceEpoch, modelCardKey string, modelCard *string, buf []byte) (int, string, *rest.PostBody, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.writes[importKey] = string(buf)
+	if r.cards != nil && modelCard != nil {
+		r.cards[modelCardKey] = *modelCard
+	}
+	r.calls++
+	return http.StatusCreated, "", nil, nil
+}
+
+func (r *recordingWriter) snapshot() (map[string]string, int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	writes := map[string]string{}
+	for k, v := range r.writes {
+		writes[k] = v
+	}
+	return writes, r.calls
+}
+
+func upsertForTest(t *testing.T, ils *ImportLocationServer, key, body string) {
+	testWriter := testgin.NewTestResponseWriter()
+	data, err := json.Marshal(rest.PostBody{Body: []byte(body)})
+	common.AssertError(t, err)
+	ctx, _ := gin.CreateTestContext(testWriter)
+	ctx.Request = &http.Request{URL: &url.URL{RawQuery: "key=" + key}, Body: io.NopCloser(bytes.NewReader(data))}
+	ils.handleCatalogUpsertPost(ctx)
+	common.AssertEqual(t, http.StatusCreated, ctx.Writer.Status())
+}
+
+func TestWriteBehindBatching(t *testing.T) {
+	w := &recordingWriter{writes: map[string]string{}}
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		modelcards:  map[string]modelCardMetadata{},
+		writeBehind: newWriteBehind(w, 200*time.Millisecond),
+	}
+
+	upsertForTest(t, ils, "mnist_v1", "create")
+	upsertForTest(t, ils, "mnist_v1", "update")
+	upsertForTest(t, ils, "mnist_v2", "create")
+	// unchanged content, like the storage service pushing back what was written, is not written again
+	upsertForTest(t, ils, "mnist_v2", "create")
+
+	// reads reflect the upserts before they are written
+	common.AssertEqual(t, "update", string(ils.content["/mnist/v1/catalog-info.yaml"].content))
+	_, calls := w.snapshot()
+	common.AssertEqual(t, 0, calls)
+
+	time.Sleep(500 * time.Millisecond)
+	writes, calls := w.snapshot()
+	common.AssertEqual(t, map[string]string{"mnist_v1": "update", "mnist_v2": "create"}, writes)
+	common.AssertEqual(t, 2, calls)
+
+	// the next upsert starts a new window
+	upsertForTest(t, ils, "mnist_v2", "update")
+	time.Sleep(500 * time.Millisecond)
+	writes, calls = w.snapshot()
+	common.AssertEqual(t, map[string]string{"mnist_v1": "update", "mnist_v2": "update"}, writes)
+	common.AssertEqual(t, 3, calls)
+}
+
+func TestWriteBehindShutdownFlush(t *testing.T) {
+	w := &recordingWriter{writes: map[string]string{}}
+	ils := &ImportLocationServer{
+		content:     map[string]*ImportLocation{},
+		modelcards:  map[string]modelCardMetadata{},
+		writeBehind: newWriteBehind(w, time.Hour),
+	}
+
+	upsertForTest(t, ils, "mnist_v1", "create")
+	ils.writeBehind.close()
+
+	writes, calls := w.snapshot()
+	common.AssertEqual(t, map[string]string{"mnist_v1": "create"}, writes)
+	common.AssertEqual(t, 1, calls)
+
+	// upserts after shutdown are still served but no longer written
+	upsertForTest(t, ils, "mnist_v2", "create")
+	common.AssertEqual(t, "create", string(ils.content["/mnist/v2/catalog-info.yaml"].content))
+	ils.writeBehind.flush()
+	_, calls = w.snapshot()
+	common.AssertEqual(t, 1, calls)
+}
+
+// blockingWriter records writes once released, signalling each write it has started
+type blockingWriter struct {
+	recordingWriter
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingWriter) UpsertModel(importKey, normalizerType, lastUpdateTimeSinceEpoch, modelCardKey string, modelCard *string, buf []byte) (int, string, *rest.PostBody, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return b.recordingWriter.UpsertModel(importKey, normalizerType, lastUpdateTimeSinceEpoch, modelCardKey, modelCard, buf)
+}
+
+func TestWriteBehindCloseWaitsForFlush(t *testing.T) {
+	w := &blockingWriter{recordingWriter: recordingWriter{writes: map[string]string{}}, started: make(chan struct{}), release: make(chan struct{})}
+	wb := newWriteBehind(w, time.Millisecond)
+	wb.enqueue("mnist_v1", "kserve", rest.PostBody{Body: []byte("create")})
+	// the timer's flush has taken the buffered upsert and is writing it
+	<-w.started
+
+	closed := make(chan struct{})
+	go func() {
+		wb.close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("close returned while a flush was still writing")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(w.release)
+	<-closed
+	writes, calls := w.snapshot()
+	common.AssertEqual(t, map[string]string{"mnist_v1": "create"}, writes)
+	common.AssertEqual(t, 1, calls)
+}
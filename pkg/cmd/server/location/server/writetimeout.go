@@ -0,0 +1,40 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+// responseWriteTimeout bounds how long the handler of a large response, such as a lookup, discovery or bundle, may
+// spend writing it, so the response to a client reading too slowly is aborted and its connection closed, freeing the
+// response, rather than held for as long as the client takes; zero leaves writes unbounded.  The server-sent events
+// stream is long-lived by design and is not bounded.
+func responseWriteTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		rc := http.NewResponseController(c.Writer)
+		if err := rc.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			// only the connections of a server support deadlines
+			c.Next()
+			return
+		}
+		c.Next()
+		// the deadline is on the connection, so is cleared for the next request kept alive on it
+		_ = rc.SetWriteDeadline(time.Time{})
+		for _, err := range c.Errors {
+			if errors.Is(err.Err, os.ErrDeadlineExceeded) {
+				slowClientAborts.Inc()
+				klog.Infof("aborted the response to %s for %s as the client read it too slowly: %s", c.ClientIP(), c.Request.URL.Path, err.Error())
+				break
+			}
+		}
+	}
+}
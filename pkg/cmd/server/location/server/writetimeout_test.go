@@ -0,0 +1,65 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redhat-ai-dev/model-catalog-bridge/test/stub/common"
+)
+
+func TestResponseWriteTimeout(t *testing.T) {
+	large := bytes.Repeat([]byte("kind: Component\n"), 4*1024*1024)
+	served := make(chan struct{}, 1)
+	newServer := func(timeout time.Duration) *httptest.Server {
+		r := newRouter(io.Discard, nil, defaultRequestIdHeader)
+		r.GET("/:model/:version/:format", responseWriteTimeout(timeout), func(c *gin.Context) {
+			c.Data(http.StatusOK, "text/yaml", large)
+			served <- struct{}{}
+		})
+		return httptest.NewServer(r)
+	}
+
+	// a client reading at speed is served the whole response
+	fast := newServer(10 * time.Second)
+	defer fast.Close()
+	before := counterValue(t, slowClientAborts)
+	resp, err := http.Get(fast.URL + "/mnist/v1/catalog-info.yaml")
+	common.AssertError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	common.AssertError(t, err)
+	<-served
+	common.AssertEqual(t, http.StatusOK, resp.StatusCode)
+	common.AssertEqual(t, len(large), len(body))
+	common.AssertEqual(t, before, counterValue(t, slowClientAborts))
+
+	// a client that stops reading has its response aborted at the timeout, rather than the handler holding it
+	slow := newServer(100 * time.Millisecond)
+	defer slow.Close()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(slow.URL, "http://"))
+	common.AssertError(t, err)
+	defer conn.Close()
+	_, err = fmt.Fprintf(conn, "GET /mnist/v1/catalog-info.yaml HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	common.AssertError(t, err)
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the response to the slow client was not aborted")
+	}
+	// the count is taken once the handler returns, just after it signals
+	deadline := time.Now().Add(5 * time.Second)
+	for counterValue(t, slowClientAborts) != before+1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the aborted response to be counted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -17,6 +17,7 @@ type BridgeStorageRESTClient struct {
 	CurrentKeySetURL string
 	ListURL          string
 	FetchURL         string
+	RemoveURL        string
 	Token            string
 }
 
@@ -27,6 +28,7 @@ func SetupBridgeStorageRESTClient(hostURL, token string) *BridgeStorageRESTClien
 		CurrentKeySetURL: hostURL + util.CurrentKeySetURI,
 		ListURL:          hostURL + util.ListURI,
 		FetchURL:         hostURL + util.FetchURI,
+		RemoveURL:        hostURL + util.RemoveURI,
 		Token:            token,
 	}
 	return b
@@ -97,3 +99,12 @@ func (b *BridgeStorageRESTClient) FetchModel(key string) (int, string, error, []
 
 	return storageResp.StatusCode(), msg, nil, storageResp.Body()
 }
+
+func (b *BridgeStorageRESTClient) DeleteModel(importKey string) (int, string, error) {
+	storageResp, err := b.RESTClient.R().SetAuthToken(b.Token).SetQueryParam(util.KeyQueryParam, importKey).SetHeader("Accept", "application/json").Delete(b.RemoveURL)
+	msg := fmt.Sprintf("%#v", storageResp)
+	if err != nil {
+		return http.StatusInternalServerError, msg, err
+	}
+	return storageResp.StatusCode(), msg, nil
+}
@@ -66,6 +66,7 @@ func NewStorageRESTServer(st types.BridgeStorage, port, bridgeURL, bridgeToken,
 	r.POST(util.CurrentKeySetURI, s.handleCatalogCurrentKeySetPost)
 	r.GET(util.ListURI, s.handleCatalogList)
 	r.GET(util.FetchURI, s.handleCatalogFetch)
+	r.DELETE(util.RemoveURI, s.handleCatalogDelete)
 	return s
 }
 
@@ -274,6 +275,8 @@ func (s *StorageRESTServer) handleCatalogUpsertPost(c *gin.Context) {
 	alreadyPushed := len(sb.LocationId) > 0
 	sb.Body = postBody.Body
 	sb.ReconcilerType = reconcilerType
+	sb.ModelCardKey = postBody.ModelCardKey
+	sb.ModelCard = postBody.ModelCard
 	err = s.st.Upsert(key, *sb)
 	if err != nil {
 		c.Status(http.StatusInternalServerError)
@@ -286,7 +289,7 @@ func (s *StorageRESTServer) handleCatalogUpsertPost(c *gin.Context) {
 	// push update to bridge locations REST endpoint
 	var rc int
 	var msg string
-	rc, msg, err = s.locations.UpsertModel(key, &postBody)
+	rc, msg, err = s.locations.UpsertModel(key, reconcilerType, &postBody)
 	if err != nil {
 		c.Status(http.StatusInternalServerError)
 		msg = fmt.Sprintf("error upserting to bridge uri %s POST body: msg %s error %s", uri, msg, err.Error())
@@ -420,6 +423,27 @@ func (s *StorageRESTServer) handleCatalogFetch(c *gin.Context) {
 	c.Data(http.StatusOK, "Content-Type: application/json", content)
 }
 
+// handleCatalogDelete removes the entry for a key from storage, as the location service does when a location is
+// removed from it; removing a key storage does not have succeeds, so the location service can retry removals
+func (s *StorageRESTServer) handleCatalogDelete(c *gin.Context) {
+	key := c.Query(util.KeyQueryParam)
+	if len(key) == 0 {
+		c.Status(http.StatusBadRequest)
+		c.Error(fmt.Errorf("need a 'key' parameter"))
+		return
+	}
+	err := s.st.Remove(key)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		msg := fmt.Sprintf("error removing from storage key %s: %s", key, err.Error())
+		klog.Error(msg)
+		c.Error(fmt.Errorf("%s", msg))
+		return
+	}
+	s.del(key)
+	c.Status(http.StatusOK)
+}
+
 func GetRESTConfig() (*k8srest.Config, error) {
 	restConfig, err := util.InClusterConfigHackForRHDHSidecars()
 	if restConfig == nil || err != nil {
@@ -312,3 +312,60 @@ func Test_handleCatalogUpsertPost_handleCatalogCurrentKeySetPost_ConfigMap(t *te
 	common.AssertEqual(t, true, ok)
 
 }
+
+func Test_handleCatalogDelete_ConfigMap(t *testing.T) {
+	cmCl := fake.NewClientset().CoreV1()
+	cm := &corev1.ConfigMap{}
+	cm.Name = util.StorageConfigMapName
+	cm.BinaryData = map[string][]byte{"mnist_v1": []byte("{}"), "mnist_v2": []byte("{}")}
+	_, err := cmCl.ConfigMaps(metav1.NamespaceDefault).Create(context.Background(), cm, metav1.CreateOptions{})
+	common.AssertError(t, err)
+	s := &StorageRESTServer{
+		st:              configmap.NewConfigMapBridgeStorageForTest(metav1.NamespaceDefault, cmCl),
+		mutex:           sync.Mutex{},
+		pushedLocations: map[string]*types.StorageBody{"mnist_v1": {}},
+	}
+
+	for _, tc := range []struct {
+		name         string
+		reqURL       url.URL
+		expectedSC   int
+		expectedKeys []string
+	}{
+		{
+			name:         "no key",
+			reqURL:       url.URL{},
+			expectedSC:   http.StatusBadRequest,
+			expectedKeys: []string{"mnist_v1", "mnist_v2"},
+		},
+		{
+			name:         "remove key",
+			reqURL:       url.URL{RawQuery: "key=mnist_v1"},
+			expectedSC:   http.StatusOK,
+			expectedKeys: []string{"mnist_v2"},
+		},
+		{
+			name:         "remove key already removed",
+			reqURL:       url.URL{RawQuery: "key=mnist_v1"},
+			expectedSC:   http.StatusOK,
+			expectedKeys: []string{"mnist_v2"},
+		},
+	} {
+		testWriter := testgin.NewTestResponseWriter()
+		ctx, _ := gin.CreateTestContext(testWriter)
+		ctx.Request = &http.Request{URL: &tc.reqURL}
+
+		s.handleCatalogDelete(ctx)
+
+		common.AssertEqual(t, tc.expectedSC, ctx.Writer.Status())
+		keys, err := s.st.List()
+		common.AssertError(t, err)
+		common.AssertEqual(t, len(tc.expectedKeys), len(keys))
+		for _, k := range tc.expectedKeys {
+			_, err = s.st.Fetch(k)
+			common.AssertError(t, err)
+		}
+		_, pushed := s.pushedLocations["mnist_v1"]
+		common.AssertEqual(t, tc.expectedSC != http.StatusOK, pushed)
+	}
+}
@@ -1,8 +1,17 @@
 package rest
 
 type PostBody struct {
-	Body                     []byte `json:"body"`
-	LastUpdateTimeSinceEpoch string `json:"lastUpdateTimeSinceEpoch"`
-	ModelCardKey             string `json:"modelCardKey"`
-	ModelCard                string `json:"modelCard"`
+	Body                     []byte       `json:"body"`
+	LastUpdateTimeSinceEpoch string       `json:"lastUpdateTimeSinceEpoch"`
+	ModelCardKey             string       `json:"modelCardKey"`
+	ModelCard                string       `json:"modelCard"`
+	Attachments              []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a named binary asset of a model card, such as an eval dataset, whose content is base64 encoded in
+// JSON; without a content type, one is inferred from the name's extension or the content
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	Content     []byte `json:"content"`
 }
@@ -0,0 +1,97 @@
+package types
+
+// These environment variables enable and tune the optional behaviors of the location service
+const (
+	EntityUniquenessEnvVar         = "ENTITY_UNIQUENESS_CHECK"
+	IngestAnnotationsEnvVar        = "INGEST_ANNOTATIONS"
+	FetchOnMissEnvVar              = "FETCH_ON_MISS"
+	DownwardAPIAnnotationsEnvVar   = "DOWNWARD_API_ANNOTATIONS"
+	StorageFetchConcurrencyEnvVar  = "STORAGE_FETCH_CONCURRENCY"
+	ReconcileIntervalEnvVar        = "RECONCILE_INTERVAL"
+	ReconcileStaleAfterEnvVar      = "RECONCILE_STALE_AFTER"
+	QuarantineThresholdEnvVar      = "QUARANTINE_FAILURE_THRESHOLD"
+	QuarantineBaseBackoffEnvVar    = "QUARANTINE_BASE_BACKOFF"
+	QuarantineMaxBackoffEnvVar     = "QUARANTINE_MAX_BACKOFF"
+	ReloadUpsertStrategyEnvVar     = "RELOAD_UPSERT_STRATEGY"
+	ReloadUpsertWaitEnvVar         = "RELOAD_UPSERT_WAIT"
+	RequestLogSkipPathsEnvVar      = "REQUEST_LOG_SKIP_PATHS"
+	FormatAutoDetectEnvVar         = "FORMAT_AUTO_DETECT"
+	ModelCardMaxAgeEnvVar          = "MODEL_CARD_MAX_AGE"
+	ModelCardRefetchIntervalEnvVar = "MODEL_CARD_REFETCH_INTERVAL"
+	ServeDefaultOwnerEnvVar        = "SERVE_DEFAULT_OWNER"
+	ServeDefaultSystemEnvVar       = "SERVE_DEFAULT_SYSTEM"
+	DiscoverySourcesEnvVar         = "DISCOVERY_SOURCES"
+	ServeValidationEnvVar          = "SERVE_VALIDATION"
+	StorageWriteBehindWindowEnvVar = "STORAGE_WRITE_BEHIND_WINDOW"
+	URITemplateEnvVar              = "URI_TEMPLATE"
+	AdminTokenEnvVar               = "ADMIN_TOKEN"
+	AuthScopeTokensEnvVar          = "AUTH_SCOPE_TOKENS"
+	AuthJWTKeyEnvVar               = "AUTH_JWT_KEY"
+	StorageDeleteModeEnvVar        = "STORAGE_DELETE_MODE"
+	StorageDeleteRetriesEnvVar     = "STORAGE_DELETE_RETRIES"
+	ModelCardKeyConflictsEnvVar    = "MODEL_CARD_KEY_CONFLICTS"
+	ModelCardMaxResidentEnvVar     = "MODEL_CARD_MAX_RESIDENT"
+	ModelCardSigningKeyEnvVar      = "MODEL_CARD_SIGNING_KEY"
+	ModelCardAttachmentMaxEnvVar   = "MODEL_CARD_ATTACHMENT_MAX_BYTES"
+	ModelCardFallbackEnvVar        = "MODEL_CARD_FALLBACK"
+	DiscoveryShapeEnvVar           = "DISCOVERY_SHAPE"
+	SSEHeartbeatIntervalEnvVar     = "SSE_HEARTBEAT_INTERVAL"
+	EventsMaxSubscribersEnvVar     = "EVENTS_MAX_SUBSCRIBERS"
+	LocationMaxAgeEnvVar           = "LOCATION_MAX_AGE"
+	StaleIfErrorEnvVar             = "STALE_IF_ERROR"
+	FormatContentTypesEnvVar       = "FORMAT_CONTENT_TYPES"
+	DeadLetterFileEnvVar           = "DEAD_LETTER_FILE"
+	DeadLetterMaxBytesEnvVar       = "DEAD_LETTER_MAX_BYTES"
+	MaxURILengthEnvVar             = "MAX_URI_LENGTH"
+	TracingEnabledEnvVar           = "TRACING_ENABLED"
+	NotReadyServes503EnvVar        = "NOT_READY_SERVES_503"
+	ContentEncryptionKeyEnvVar     = "CONTENT_ENCRYPTION_KEY"
+	RequestIdHeaderEnvVar          = "REQUEST_ID_HEADER"
+	BareRouterEnvVar               = "BARE_ROUTER"
+	TLSCertFileEnvVar              = "TLS_CERT_FILE"
+	TLSKeyFileEnvVar               = "TLS_KEY_FILE"
+	ContentNegotiationEnvVar       = "CONTENT_NEGOTIATION"
+	EmptyModelCardModeEnvVar       = "EMPTY_MODEL_CARD_MODE"
+	KeyIdentityCheckEnvVar         = "KEY_IDENTITY_CHECK"
+	LegacyKeyWarningEnvVar         = "LEGACY_KEY_WARNING"
+	NormalizeWhitespaceEnvVar      = "INGEST_NORMALIZE_WHITESPACE"
+	LookupNotFoundGuidanceEnvVar   = "LOOKUP_NOT_FOUND_GUIDANCE"
+	EntityOwnershipLockEnvVar      = "ENTITY_OWNERSHIP_LOCK"
+	EntityOwnershipFileEnvVar      = "ENTITY_OWNERSHIP_FILE"
+	ServeMetadataAnnotationsEnvVar = "SERVE_METADATA_ANNOTATIONS"
+	InstanceIdEnvVar               = "INSTANCE_ID"
+	StorageKeyPrefixesEnvVar       = "STORAGE_KEY_SOURCE_PREFIXES"
+	LocationRefreshTTLEnvVar       = "LOCATION_REFRESH_TTL"
+	ModelCardPlaceholderEnvVar     = "MODEL_CARD_PLACEHOLDER"
+	ShardIndexEnvVar               = "SHARD_INDEX"
+	ShardCountEnvVar               = "SHARD_COUNT"
+	UpsertMaxHeapBytesEnvVar       = "UPSERT_MAX_HEAP_BYTES"
+	MemorySampleIntervalEnvVar     = "MEMORY_SAMPLE_INTERVAL"
+	ReadThroughEnvVar              = "READ_THROUGH"
+	ReadThroughCacheTTLEnvVar      = "READ_THROUGH_CACHE_TTL"
+	ReadThroughCacheEntriesEnvVar  = "READ_THROUGH_CACHE_MAX_ENTRIES"
+	SourceConcurrencyBudgetsEnvVar = "SOURCE_CONCURRENCY_BUDGETS"
+	ServeRedactJSONPathsEnvVar     = "SERVE_REDACT_JSON_PATHS"
+	ServeRedactPlaceholderEnvVar   = "SERVE_REDACT_PLACEHOLDER"
+	ServeStripPathsEnvVar          = "SERVE_STRIP_PATHS"
+	ServeReferenceBaseURLEnvVar    = "SERVE_REFERENCE_BASE_URL"
+	ModelsIncludeEmptyEnvVar       = "MODELS_INCLUDE_EMPTY"
+	StorageKeyPrefixEnvVar         = "STORAGE_KEY_PREFIX"
+	VersionPatternEnvVar           = "VERSION_PATTERN"
+	LatestVersionOrderEnvVar       = "LATEST_VERSION_ORDER"
+	ContentHashAlgorithmEnvVar     = "CONTENT_HASH_ALGORITHM"
+	LoadMaxKeyLengthEnvVar         = "LOAD_MAX_KEY_LENGTH"
+	LoadMaxContentBytesEnvVar      = "LOAD_MAX_CONTENT_BYTES"
+	ChangeLogSizeEnvVar            = "CHANGE_LOG_SIZE"
+	SkipStartupLoadEnvVar          = "SKIP_STARTUP_LOAD"
+	TrustedProxiesEnvVar           = "TRUSTED_PROXIES"
+	TrustedPlatformEnvVar          = "TRUSTED_PLATFORM"
+	ClientIPStrictEnvVar           = "CLIENT_IP_CONFIG_STRICT"
+	BulkMaxConcurrentJobsEnvVar    = "BULK_MAX_CONCURRENT_JOBS"
+	SchemaNegotiationEnvVar        = "SCHEMA_NEGOTIATION"
+	SourceMetricsEnvVar            = "SOURCE_METRICS"
+	ReadLockTimeoutEnvVar          = "READ_LOCK_TIMEOUT"
+	ResponseWriteTimeoutEnvVar     = "RESPONSE_WRITE_TIMEOUT"
+	ServeRetryBackoffEnvVar        = "SERVE_RETRY_BACKOFF"
+	ServeRetryLimitEnvVar          = "SERVE_RETRY_LIMIT"
+)
@@ -19,6 +19,8 @@ type StorageBody struct {
 	LocationIDValid          bool   `json:"locationIDValid"`
 	ReconcilerType           string `json:"reconcilerType"`
 	LastUpdateTimeSinceEpoch string `json:"lastUpdateTimeSinceEpoch"`
+	ModelCardKey             string `json:"modelCardKey,omitempty"`
+	ModelCard                string `json:"modelCard,omitempty"`
 }
 
 type BridgeStorageType string
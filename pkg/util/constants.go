@@ -6,11 +6,57 @@ const (
 	StorageConfigMapName = "bac-import-model"
 	KeyQueryParam        = "key"
 	TypeQueryParam       = "type"
+	LimitQueryParam      = "limit"
+	CursorQueryParam     = "cursor"
+	PrefixQueryParam     = "prefix"
+	DetailedQueryParam   = "detailed"
+	FieldsQueryParam     = "fields"
+	OverrideQueryParam   = "override"
+	SinceQueryParam      = "since"
+	UntilQueryParam      = "until"
+	ExpiresQueryParam    = "exp"
+	SignatureQueryParam  = "sig"
+	TTLQueryParam        = "ttl"
+	ShapeQueryParam      = "shape"
+	PrettyQueryParam     = "pretty"
+	EmptyQueryParam      = "empty"
+	AllQueryParam        = "all"
+	AsyncQueryParam      = "async"
+	StreamQueryParam     = "stream"
 	UpsertURI            = "/upsert"
+	UpsertModelCardURI   = "/upsert/modelcard"
+	UpsertBulkURI        = "/upsert/bulk"
+	JobURI               = "/jobs/:id"
 	CurrentKeySetURI     = "/currentkeyset"
 	RemoveURI            = "/remove"
 	ListURI              = "/list"
 	FetchURI             = "/fetch"
 	ModelCardURI         = "/modelcard"
+	ModelCardMetaURI     = "/modelcard/meta"
+	ModelCardSignURI     = "/modelcard/sign"
+	ModelCardTOCURI      = "/modelcard/toc"
+	ModelCardAssetURI    = "/modelcard/:key/assets/:name"
+	ModelCardsURI        = "/modelcards"
+	ModelCardsRefreshURI = "/modelcards/refresh"
+	ModelCardsReparseURI = "/modelcards/reparse"
+	MetricsURI           = "/metrics"
+	MetricsInFlightURI   = "/metrics/inflight"
+	QuarantineURI        = "/quarantine"
+	ManifestURI          = "/manifest"
+	BundleURI            = "/:model/:version/bundle"
+	RelationsURI         = "/:model/:version/:format/relations"
+	EventsURI            = "/events"
+	ChangesURI           = "/changes"
+	HealthzURI           = "/healthz"
+	ReadyzURI            = "/readyz"
+	ConfigURI            = "/config"
+	ConfigReindexURI     = "/config/reindex"
+	MaintenanceURI       = "/maintenance"
+	FormatsURI           = "/formats"
+	ContractURI          = "/contract"
+	ModelsURI            = "/models"
+	DriftURI             = "/drift"
+	CompressionStatsURI  = "/stats/compression"
+	ReconcileStatsURI    = "/stats/reconcile"
 
 )
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/rest"
+	"github.com/redhat-ai-dev/model-catalog-bridge/pkg/types"
+)
+
+// StubStorageClient is an in memory stand in for the bridge storage REST client, for tests that need to control
+// what storage returns and observe how it was called
+type StubStorageClient struct {
+	lock     sync.Mutex
+	content  map[string][]byte
+	meta     map[string]types.StorageBody
+	failKeys map[string]bool
+	fetches  map[string]int
+	deletes  map[string]int
+
+	failDeletes int
+
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+
+	// FetchDelay slows down each fetch, allowing tests to drive concurrent fetches
+	FetchDelay time.Duration
+	// ListErr, when set, is returned by ListModelsKeys
+	ListErr error
+}
+
+func NewStubStorageClient(content map[string][]byte) *StubStorageClient {
+	s := &StubStorageClient{
+		content:  map[string][]byte{},
+		meta:     map[string]types.StorageBody{},
+		failKeys: map[string]bool{},
+		fetches:  map[string]int{},
+		deletes:  map[string]int{},
+	}
+	for k, v := range content {
+		s.content[k] = v
+	}
+	return s
+}
+
+// SetContent adds or, when body is nil, removes the content for a key
+func (s *StubStorageClient) SetContent(key string, body []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if body == nil {
+		delete(s.content, key)
+		return
+	}
+	s.content[key] = body
+}
+
+// SetModelCard sets the model card stored along with the content for a key
+func (s *StubStorageClient) SetModelCard(key, modelCardKey, modelCard, lastUpdateTimeSinceEpoch string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sb := s.meta[key]
+	sb.ModelCardKey, sb.ModelCard, sb.LastUpdateTimeSinceEpoch = modelCardKey, modelCard, lastUpdateTimeSinceEpoch
+	s.meta[key] = sb
+}
+
+// SetReconcilerType sets the type of the normalizer that stored the content for a key
+func (s *StubStorageClient) SetReconcilerType(key, reconcilerType string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sb := s.meta[key]
+	sb.ReconcilerType = reconcilerType
+	s.meta[key] = sb
+}
+
+// FailKey has fetches of key return an error until cleared
+func (s *StubStorageClient) FailKey(key string, fail bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failKeys[key] = fail
+}
+
+// FetchCount returns the number of fetches made for a key
+func (s *StubStorageClient) FetchCount(key string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.fetches[key]
+}
+
+func (s *StubStorageClient) ListModelsKeys() (int, string, error, []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.ListErr != nil {
+		return http.StatusInternalServerError, s.ListErr.Error(), s.ListErr, []string{}
+	}
+	keys := []string{}
+	for k := range s.content {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return http.StatusOK, "", nil, keys
+}
+
+// MaxConcurrentFetches returns the most fetches that were ever in progress at the same time
+func (s *StubStorageClient) MaxConcurrentFetches() int {
+	return int(s.maxInFlight.Load())
+}
+
+func (s *StubStorageClient) FetchModel(key string) (int, string, error, []byte) {
+	n := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		max := s.maxInFlight.Load()
+		if n <= max || s.maxInFlight.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	time.Sleep(s.FetchDelay)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.fetches[key]++
+	if s.failKeys[key] {
+		err := fmt.Errorf("stub failure fetching %s", key)
+		return http.StatusInternalServerError, err.Error(), err, []byte{}
+	}
+	// like the storage service, unknown keys get an empty storage body
+	sb := s.meta[key]
+	sb.Body = s.content[key]
+	buf, _ := json.Marshal(sb)
+	return http.StatusOK, "", nil, buf
+}
+
+// FailDeletes has the next n deletes return an error
+func (s *StubStorageClient) FailDeletes(n int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failDeletes = n
+}
+
+// DeleteCount returns the number of deletes attempted for a key
+func (s *StubStorageClient) DeleteCount(key string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.deletes[key]
+}
+
+func (s *StubStorageClient) DeleteModel(key string) (int, string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.deletes[key]++
+	if s.failDeletes > 0 {
+		s.failDeletes--
+		err := fmt.Errorf("stub failure deleting %s", key)
+		return http.StatusInternalServerError, err.Error(), err
+	}
+	// like the storage service, deleting a key it does not have succeeds
+	delete(s.content, key)
+	delete(s.meta, key)
+	return http.StatusOK, "", nil
+}
+
+// UpsertModel stores the content and model card for a key, as the storage service does
+func (s *StubStorageClient) UpsertModel(importKey, normalizerType, lastUpdateTimeSinceEpoch, modelCardKey string, modelCard *string, buf []byte) (int, string, *rest.PostBody, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sb := types.StorageBody{ReconcilerType: normalizerType, LastUpdateTimeSinceEpoch: lastUpdateTimeSinceEpoch, ModelCardKey: modelCardKey}
+	if modelCard != nil {
+		sb.ModelCard = *modelCard
+	}
+	s.meta[importKey] = sb
+	s.content[importKey] = buf
+	return http.StatusCreated, "", &rest.PostBody{Body: buf, ModelCardKey: modelCardKey, LastUpdateTimeSinceEpoch: lastUpdateTimeSinceEpoch}, nil
+}
@@ -24,6 +24,7 @@ func SetupBridgeStorageRESTClient(ts *httptest.Server) *storage.BridgeStorageRES
 	storageTC.ListURL = ts.URL + util.ListURI
 	storageTC.FetchURL = ts.URL + util.FetchURI
 	storageTC.CurrentKeySetURL = ts.URL + util.CurrentKeySetURI
+	storageTC.RemoveURL = ts.URL + util.RemoveURI
 	return storageTC
 }
 